@@ -2,9 +2,12 @@ package analysis
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"testing"
+	"time"
 )
 
 func TestGraph(t *testing.T) {
@@ -727,3 +730,763 @@ jest.setMock('./utils', {});
 		t.Errorf("Expected setmock.test.ts to have DepMocked for utils.ts")
 	}
 }
+
+// writeFiles creates files (relative path -> content) under dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestGraph_VitestMocks verifies that vi.mock and vi.doMock insulate a test
+// the same way jest.mock does.
+func TestGraph_VitestMocks(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"leaf.ts":        "export const value = 1;",
+		"middle.ts":      "import { value } from './leaf';",
+		"mock.test.ts":   "import { value } from './middle';\nvi.mock('./middle');",
+		"domock.test.ts": "vi.doMock('./middle', () => ({ value: 2 }));\nconst { value } = await import('./middle');",
+		"real.test.ts":   "import { value } from './middle';",
+	})
+
+	g := NewGraph()
+	if err := g.Build(tmpDir); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	affected := g.GetAffectedDependents(filepath.Join(tmpDir, "leaf.ts"))
+	if _, ok := affected[filepath.Join(tmpDir, "real.test.ts")]; !ok {
+		t.Errorf("Expected real.test.ts to be affected; got %v", affected)
+	}
+	for _, name := range []string{"mock.test.ts", "domock.test.ts"} {
+		if _, ok := affected[filepath.Join(tmpDir, name)]; ok {
+			t.Errorf("Expected %s to be insulated by its mock; got %v", name, affected)
+		}
+	}
+}
+
+// TestGraph_PartialMocksNotInsulated verifies that a mock whose factory loads
+// the real module through requireActual, importActual or importOriginal does
+// not cut the test off from that module's dependencies.
+func TestGraph_PartialMocksNotInsulated(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"leaf.ts":   "export const value = 1;",
+		"middle.ts": "import { value } from './leaf';\nexport const other = 2;",
+		"jest.test.ts": `import { value } from './middle';
+jest.mock('./middle', () => ({ ...jest.requireActual('./middle'), other: 3 }));`,
+		"vitest.test.ts": `import { value } from './middle';
+vi.mock('./middle', async () => ({ ...(await vi.importActual('./middle')), other: 3 }));`,
+		"original.test.ts": `import { value } from './middle';
+vi.mock('./middle', async (importOriginal) => ({ ...(await importOriginal()), other: 3 }));`,
+		"full.test.ts": `import { value } from './middle';
+vi.mock('./middle', () => ({ value: 0, other: 3 }));`,
+	})
+
+	g := NewGraph()
+	if err := g.Build(tmpDir); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	affected := g.GetAffectedDependents(filepath.Join(tmpDir, "leaf.ts"))
+	for _, name := range []string{"jest.test.ts", "vitest.test.ts", "original.test.ts"} {
+		if _, ok := affected[filepath.Join(tmpDir, name)]; !ok {
+			t.Errorf("Expected partially mocked %s to be affected; got %v", name, affected)
+		}
+	}
+	if _, ok := affected[filepath.Join(tmpDir, "full.test.ts")]; ok {
+		t.Errorf("Expected fully mocked full.test.ts to be insulated; got %v", affected)
+	}
+}
+
+// TestGraph_ManualMocks verifies that a __mocks__ file next to a mocked module,
+// or in the project root for a package, becomes a dependency of tests that
+// mock the module without a factory, including when it is created later.
+func TestGraph_ManualMocks(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"src/api.ts":           "export const get = () => 1;",
+		"src/__mocks__/api.ts": "export const get = () => 0;",
+		"src/db.ts":            "export const query = () => 1;",
+		"__mocks__/axios.ts":   "export default { get: () => 0 };",
+		"api.test.ts":          "import { get } from './src/api';\njest.mock('./src/api');",
+		"factory.test.ts":      "import { get } from './src/api';\njest.mock('./src/api', () => ({ get: () => 2 }));",
+		"axios.test.ts":        "import axios from 'axios';\nvi.mock('axios');",
+		"db.test.ts":           "import { query } from './src/db';\nvi.mock('./src/db');",
+	})
+
+	g := NewGraphWithRoot(tmpDir)
+	if err := g.Build(tmpDir); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	affected := g.GetAffectedDependents(filepath.Join(tmpDir, "src/__mocks__/api.ts"))
+	if _, ok := affected[filepath.Join(tmpDir, "api.test.ts")]; !ok {
+		t.Errorf("Expected api.test.ts to depend on its manual mock; got %v", affected)
+	}
+	if _, ok := affected[filepath.Join(tmpDir, "factory.test.ts")]; ok {
+		t.Errorf("Expected factory.test.ts, which mocks with a factory, not to use the manual mock; got %v", affected)
+	}
+
+	affected = g.GetAffectedDependents(filepath.Join(tmpDir, "__mocks__/axios.ts"))
+	if _, ok := affected[filepath.Join(tmpDir, "axios.test.ts")]; !ok {
+		t.Errorf("Expected axios.test.ts to depend on the root package mock; got %v", affected)
+	}
+
+	// A manual mock added after the build is linked when it is parsed.
+	dbMock := filepath.Join(tmpDir, "src/__mocks__/db.ts")
+	writeFiles(t, tmpDir, map[string]string{"src/__mocks__/db.ts": "export const query = () => 0;"})
+	g.Update(dbMock)
+	affected = g.GetAffectedDependents(dbMock)
+	if _, ok := affected[filepath.Join(tmpDir, "db.test.ts")]; !ok {
+		t.Errorf("Expected db.test.ts to depend on the new manual mock; got %v", affected)
+	}
+}
+
+// TestGraph_WorkspacePackages verifies that importing a workspace package by
+// name links to its source files, so changes in one package affect the tests
+// of packages that use it.
+func TestGraph_WorkspacePackages(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"packages/utils/package.json":     `{"name": "@acme/utils", "main": "./dist/index.js", "types": "./dist/index.d.ts"}`,
+		"packages/utils/src/index.ts":     "export * from './format';",
+		"packages/utils/src/format.ts":    "export const format = (s: string) => s;",
+		"packages/web/package.json":       `{"name": "@acme/web"}`,
+		"packages/web/src/app.ts":         "import { format } from '@acme/utils';\nexport const app = format('x');",
+		"packages/web/src/app.test.ts":    "import { app } from './app';",
+		"packages/web/src/lodash.test.ts": "import _ from 'lodash';",
+	})
+
+	g := NewGraphWithRoot(tmpDir)
+	g.SetPackages([]Package{
+		{Name: "@acme/utils", Root: filepath.Join(tmpDir, "packages/utils")},
+		{Name: "@acme/web", Root: filepath.Join(tmpDir, "packages/web")},
+	})
+	if err := g.Build(tmpDir); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	affected := g.GetAffectedDependents(filepath.Join(tmpDir, "packages/utils/src/format.ts"))
+	if _, ok := affected[filepath.Join(tmpDir, "packages/web/src/app.test.ts")]; !ok {
+		t.Errorf("Expected app.test.ts to be affected by a change in @acme/utils; got %v", affected)
+	}
+	if deps := g.Forward[filepath.Join(tmpDir, "packages/web/src/lodash.test.ts")]; len(deps) != 0 {
+		t.Errorf("Expected no dependencies for a node_modules import; got %v", deps)
+	}
+}
+
+func TestResolvePackage(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"exports/package.json": `{
+  "name": "@acme/exports",
+  "exports": {
+    ".": { "types": "./dist/index.d.ts", "import": "./dist/esm/index.js", "require": "./dist/cjs/index.js" },
+    "./format": "./src/format.js",
+    "./icons/*": { "default": "./src/icons/*.tsx" },
+    "./icons/internal/*": null,
+    "./package.json": "./package.json"
+  }
+}`,
+		"exports/src/index.ts":             "",
+		"exports/src/format.ts":            "",
+		"exports/src/icons/arrow.tsx":      "",
+		"exports/src/icons/internal/x.tsx": "",
+		"sugar/package.json":               `{"name": "sugar", "exports": {"import": "./lib/main.mjs", "default": "./lib/main.cjs"}}`,
+		"sugar/src/main.ts":                "",
+		"main/package.json":                `{"name": "main-only", "module": "./esm/entry.js", "main": "./cjs/entry.js"}`,
+		"main/esm/entry.js":                "",
+		"main/deep/file.ts":                "",
+		"bare/package.json":                `{"name": "bare"}`,
+		"bare/index.ts":                    "",
+		"ui/package.json":                  `{"name": "@acme/ui"}`,
+		"ui/index.ts":                      "",
+		"ui-kit/package.json":              `{"name": "@acme/ui-kit"}`,
+		"ui-kit/index.ts":                  "",
+	})
+
+	p := NewParser()
+	p.SetPackages([]Package{
+		{Name: "@acme/exports", Root: filepath.Join(tmpDir, "exports")},
+		{Name: "sugar", Root: filepath.Join(tmpDir, "sugar")},
+		{Name: "main-only", Root: filepath.Join(tmpDir, "main")},
+		{Name: "bare", Root: filepath.Join(tmpDir, "bare")},
+		{Name: "@acme/ui", Root: filepath.Join(tmpDir, "ui")},
+		{Name: "@acme/ui-kit", Root: filepath.Join(tmpDir, "ui-kit")},
+	})
+
+	tests := []struct {
+		imp  string
+		want string // Relative to tmpDir; empty when unresolved
+	}{
+		{"@acme/exports", "exports/src/index.ts"},
+		{"@acme/exports/format", "exports/src/format.ts"},
+		{"@acme/exports/icons/arrow", "exports/src/icons/arrow.tsx"},
+		{"@acme/exports/icons/internal/x", ""},
+		{"@acme/exports/src/format", ""},
+		{"sugar", "sugar/src/main.ts"},
+		{"sugar/other", ""},
+		{"main-only", "main/esm/entry.js"},
+		{"main-only/deep/file", "main/deep/file.ts"},
+		{"bare", "bare/index.ts"},
+		{"@acme/ui-kit", "ui-kit/index.ts"},
+		{"@acme/ui", "ui/index.ts"},
+		{"@acme/uikit", ""},
+		{"react", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.imp, func(t *testing.T) {
+			got, ok := p.resolvePackage(tt.imp)
+			want := ""
+			if tt.want != "" {
+				want = filepath.Join(tmpDir, tt.want)
+			}
+			if !ok {
+				got = ""
+			}
+			if got != want {
+				t.Errorf("resolvePackage(%q) = %q, want %q", tt.imp, got, want)
+			}
+		})
+	}
+}
+
+// TestResolveAlias_Extends verifies that paths and baseUrl are merged through
+// extends chains, including arrays, node_modules packages and JSONC files,
+// and that patterns and targets are tried the way tsc tries them.
+func TestResolveAlias_Extends(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"node_modules/@acme/tsconfig/package.json": `{"name": "@acme/tsconfig", "tsconfig": "./base.json"}`,
+		"node_modules/@acme/tsconfig/base.json": `{
+  "compilerOptions": { "paths": { "#shared/*": ["./shared/*"] } }
+}`,
+		"config/tsconfig.base.json": `{
+  // Comments and trailing commas are allowed.
+  "compilerOptions": {
+    "baseUrl": "..", /* relative to this file */
+    "paths": {
+      "@/*": ["./src/*", "./generated/*"],
+      "@/components/*": ["./ui/components/*"],
+      "@env": ["${configDir}/env"],
+      "*": ["./types/*"],
+    },
+  },
+}`,
+		"tsconfig.json":    `{"extends": ["@acme/tsconfig", "./config/tsconfig.base"]}`,
+		"generated/api.ts": "",
+		"src/utils.ts":     "",
+		"legacy/module.ts": "",
+	})
+	InvalidateTSConfigCache(tmpDir)
+
+	tests := []struct {
+		importPath string
+		want       string // Relative to tmpDir; empty when unresolved
+	}{
+		{"@/utils", "src/utils"},
+		{"@/api", "generated/api"},                      // Falls back to the second target
+		{"@/missing", "src/missing"},                    // Pending on the first target
+		{"@/components/button", "ui/components/button"}, // Longest prefix wins
+		{"@env", "env"},
+		{"legacy/module", "legacy/module"}, // baseUrl
+		{"#shared/x", ""},                  // Overridden by the later extends
+		{"react", ""},                      // Catch-all with no match
+	}
+	for _, tt := range tests {
+		got, ok := ResolveAlias(tt.importPath, tmpDir)
+		want := ""
+		if tt.want != "" {
+			want = filepath.Join(tmpDir, tt.want)
+		}
+		if !ok {
+			got = ""
+		}
+		if got != want {
+			t.Errorf("ResolveAlias(%q) = %q, want %q", tt.importPath, got, want)
+		}
+	}
+}
+
+// TestGraph_NearestTSConfig verifies that each file resolves aliases with the
+// tsconfig closest to it, that solution-style configs defer to the referenced
+// project that includes the file, and that invalidating the cache picks up
+// edits.
+func TestGraph_NearestTSConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"tsconfig.json":         `{"files": [], "references": [{"path": "./tsconfig.app.json"}, {"path": "./tsconfig.node.json"}]}`,
+		"tsconfig.app.json":     `{"compilerOptions": {"paths": {"@/*": ["./src/*"]}}, "include": ["src"]}`,
+		"tsconfig.node.json":    `{"compilerOptions": {"paths": {"@/*": ["./scripts/*"]}}, "include": ["scripts/**/*.ts"]}`,
+		"src/utils.ts":          "export const util = 1;",
+		"src/utils.test.ts":     "import { util } from '@/utils';",
+		"scripts/utils.ts":      "export const script = 1;",
+		"scripts/build.test.ts": "import { script } from '@/utils';",
+
+		"packages/a/tsconfig.json":      `{"compilerOptions": {"paths": {"~/*": ["./lib/*"]}}}`,
+		"packages/a/lib/value.ts":       "export const value = 1;",
+		"packages/a/test/value.test.ts": "import { value } from '~/value';",
+	})
+	InvalidateTSConfigCache(tmpDir)
+
+	g := NewGraphWithRoot(tmpDir)
+	if err := g.Build(tmpDir); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	cases := map[string]string{
+		"src/utils.ts":            "src/utils.test.ts",
+		"scripts/utils.ts":        "scripts/build.test.ts",
+		"packages/a/lib/value.ts": "packages/a/test/value.test.ts",
+	}
+	for dep, test := range cases {
+		affected := g.GetAffectedDependents(filepath.Join(tmpDir, dep))
+		if _, ok := affected[filepath.Join(tmpDir, test)]; !ok || len(affected) != 1 {
+			t.Errorf("Expected only %s to depend on %s; got %v", test, dep, affected)
+		}
+	}
+
+	writeFiles(t, tmpDir, map[string]string{
+		"packages/a/tsconfig.json": `{"compilerOptions": {"paths": {"~/*": ["./src/*"]}}}`,
+		"packages/a/src/value.ts":  "export const value = 2;",
+	})
+	InvalidateTSConfigCache(tmpDir)
+	testPath := filepath.Join(tmpDir, "packages/a/test/value.test.ts")
+	g.Update(testPath)
+	if _, ok := g.Forward[testPath][filepath.Join(tmpDir, "packages/a/src/value.ts")]; !ok {
+		t.Errorf("Expected the edited tsconfig to be used after invalidation; got %v", g.Forward[testPath])
+	}
+}
+
+// TestGraph_JestModuleNameMapper verifies that moduleNameMapper entries in a
+// package.json "jest" field link imports, trying each target in turn and
+// leaving package targets such as identity-obj-proxy alone.
+func TestGraph_JestModuleNameMapper(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"package.json": `{
+  "jest": {
+    "moduleNameMapper": {
+      "^@/(.*)$": "<rootDir>/src/$1",
+      "^~(.*)$": ["<rootDir>/missing$1", "<rootDir>/lib$1"],
+      "\\.css$": "identity-obj-proxy"
+    }
+  }
+}`,
+		"src/utils.ts":  "export const util = 1;",
+		"lib/format.ts": "export const format = 1;",
+		"styles.css":    "",
+		"app.test.ts":   "import { util } from '@/utils';\nimport { format } from '~/format';\nimport './styles.css';",
+	})
+	InvalidateRunnerAliases(filepath.Join(tmpDir, "package.json"))
+
+	g := NewGraphWithRoot(tmpDir)
+	if err := g.Build(tmpDir); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	testPath := filepath.Join(tmpDir, "app.test.ts")
+	want := map[string]DependencyType{
+		filepath.Join(tmpDir, "src/utils.ts"):  DepRegular,
+		filepath.Join(tmpDir, "lib/format.ts"): DepRegular,
+	}
+	if got := g.Forward[testPath]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Forward[app.test.ts] = %v, want %v", got, want)
+	}
+}
+
+// TestGraph_RunnerConfigAliases verifies that aliases from JS Jest and Vite
+// configs, evaluated with node, link imports in the files below them, and
+// that invalidating a config re-evaluates it.
+func TestGraph_RunnerConfigAliases(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not installed")
+	}
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"jest/jest.config.js": `module.exports = {
+  rootDir: '.',
+  moduleNameMapper: { '^@app/(.*)$': '<rootDir>/src/$1' },
+};`,
+		"jest/src/utils.ts":     "export const util = 1;",
+		"jest/test/app.test.ts": "import { util } from '@app/utils';",
+
+		"vite/vite.config.mjs": `export default () => ({
+  resolve: { alias: [{ find: /^#(\w+)$/, replacement: '/src/$1' }] },
+  test: { alias: { '@lib': new URL('./lib', import.meta.url).pathname } },
+});`,
+		"vite/src/utils.ts":       "export const util = 1;",
+		"vite/lib/format.ts":      "export const format = 1;",
+		"vite/test/utils.test.ts": "import { util } from '#utils';\nimport { format } from '@lib/format';\nimport { v } from '@library';",
+	})
+	jestConfig := filepath.Join(tmpDir, "jest/jest.config.js")
+	InvalidateRunnerAliases(jestConfig)
+	InvalidateRunnerAliases(filepath.Join(tmpDir, "vite/vite.config.mjs"))
+
+	g := NewGraphWithRoot(tmpDir)
+	if err := g.Build(tmpDir); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	jestTest := filepath.Join(tmpDir, "jest/test/app.test.ts")
+	if _, ok := g.Forward[jestTest][filepath.Join(tmpDir, "jest/src/utils.ts")]; !ok {
+		t.Errorf("Expected the jest.config.js mapper to link app.test.ts; got %v", g.Forward[jestTest])
+	}
+	viteTest := filepath.Join(tmpDir, "vite/test/utils.test.ts")
+	want := map[string]DependencyType{
+		filepath.Join(tmpDir, "vite/src/utils.ts"):  DepRegular,
+		filepath.Join(tmpDir, "vite/lib/format.ts"): DepRegular,
+	}
+	if got := g.Forward[viteTest]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Forward[utils.test.ts] = %v, want %v", got, want)
+	}
+
+	writeFiles(t, tmpDir, map[string]string{
+		"jest/jest.config.js": `module.exports = { moduleNameMapper: { '^@app/(.*)$': '<rootDir>/lib/$1' } };`,
+		"jest/lib/utils.ts":   "export const util = 2;",
+	})
+	InvalidateRunnerAliases(jestConfig)
+	g.Update(jestTest)
+	if _, ok := g.Forward[jestTest][filepath.Join(tmpDir, "jest/lib/utils.ts")]; !ok {
+		t.Errorf("Expected the edited jest.config.js to be used after invalidation; got %v", g.Forward[jestTest])
+	}
+}
+
+// TestGraph_NonCodeDependencies verifies that imports of JSON, stylesheets,
+// GraphQL documents and other assets become edges, including assets created
+// after the importing file was parsed.
+func TestGraph_NonCodeDependencies(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"fixtures/user.json": `{"name": "a"}`,
+		"Button.module.css":  ".button {}",
+		"query.graphql":      "query { user { name } }",
+		"icon.svg":           "<svg />",
+		"config.json":        "{}",
+		"app.test.ts": `import user from './fixtures/user.json';
+import styles from './Button.module.css';
+import query from './query.graphql';
+import Icon from './icon.svg?react';
+import later from './fixtures/later.json';
+const config = require('./config');`,
+	})
+
+	g := NewGraph()
+	if err := g.Build(tmpDir); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	testPath := filepath.Join(tmpDir, "app.test.ts")
+	for _, asset := range []string{"fixtures/user.json", "Button.module.css", "query.graphql", "icon.svg", "config.json"} {
+		assetPath := filepath.Join(tmpDir, asset)
+		if _, ok := g.GetAffectedDependents(assetPath)[testPath]; !ok {
+			t.Errorf("Expected app.test.ts to depend on %s; got %v", asset, g.Forward[testPath])
+		}
+		if !g.Tracks(assetPath) {
+			t.Errorf("Expected the graph to track %s", asset)
+		}
+	}
+
+	later := filepath.Join(tmpDir, "fixtures/later.json")
+	if !g.Tracks(later) {
+		t.Errorf("Expected the graph to track the pending import of later.json")
+	}
+	if g.Tracks(filepath.Join(tmpDir, "unrelated.json")) {
+		t.Errorf("Expected an unimported file not to be tracked")
+	}
+
+	writeFiles(t, tmpDir, map[string]string{"fixtures/later.json": "[]"})
+	g.Update(later)
+	if _, ok := g.GetAffectedDependents(later)[testPath]; !ok {
+		t.Errorf("Expected app.test.ts to depend on later.json once created; got %v", g.Forward[testPath])
+	}
+}
+
+// TestGraph_ComponentsAndESMExtensions verifies that Vue, Svelte and Astro
+// components join the graph through their script blocks, and that ESM
+// imports naming the compiled .js/.mjs/.cjs file resolve to the TypeScript
+// source, including one created later.
+func TestGraph_ComponentsAndESMExtensions(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"src/useCount.mts":    "export const useCount = () => 1;",
+		"src/format.cts":      "export const format = String;",
+		"src/Counter.vue":     "<script setup lang=\"ts\">\nimport { useCount } from './useCount.mjs';\n</script>\n<template><p /></template>",
+		"src/Badge.svelte":    "<script lang=\"ts\">\n  import Counter from './Counter.vue';\n  const f = require('./format.cjs');\n</script>",
+		"src/Page.astro":      "---\nimport Badge from './Badge.svelte';\nimport { helper } from './helper.js';\n---\n<Badge />",
+		"src/Page.test.mts":   "import Page from './Page.astro';",
+		"src/Counter.test.ts": "import Counter from './Counter.vue';",
+	})
+
+	g := NewGraph()
+	if err := g.Build(tmpDir); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	affected := g.GetAffectedDependents(filepath.Join(tmpDir, "src/useCount.mts"))
+	for _, name := range []string{"src/Counter.vue", "src/Badge.svelte", "src/Page.astro", "src/Page.test.mts", "src/Counter.test.ts"} {
+		if _, ok := affected[filepath.Join(tmpDir, name)]; !ok {
+			t.Errorf("Expected %s to be affected by useCount.mts; got %v", name, affected)
+		}
+	}
+	badge := filepath.Join(tmpDir, "src/Badge.svelte")
+	if _, ok := g.Forward[badge][filepath.Join(tmpDir, "src/format.cts")]; !ok {
+		t.Errorf("Expected './format.cjs' to resolve to format.cts; got %v", g.Forward[badge])
+	}
+
+	helper := filepath.Join(tmpDir, "src/helper.ts")
+	writeFiles(t, tmpDir, map[string]string{"src/helper.ts": "export const helper = 1;"})
+	g.Update(helper)
+	if _, ok := g.GetAffectedDependents(helper)[filepath.Join(tmpDir, "src/Page.test.mts")]; !ok {
+		t.Errorf("Expected the pending './helper.js' import to link to the new helper.ts")
+	}
+}
+
+func TestGraph_Cache(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(t.TempDir(), "graph.json")
+	writeFiles(t, tmpDir, map[string]string{
+		"tsconfig.json":   `{"compilerOptions": {"baseUrl": ".", "paths": {"@/*": ["src/*"]}}}`,
+		"src/a.ts":        "export const a = 1;",
+		"src/b.ts":        "export const b = 1;",
+		"src/app.ts":      "import { a } from '@/a';",
+		"src/app.test.ts": "import './app';\nimport './later';",
+	})
+	app := filepath.Join(tmpDir, "src/app.ts")
+	testPath := filepath.Join(tmpDir, "src/app.test.ts")
+
+	build := func() *Graph {
+		t.Helper()
+		g := NewGraphWithRoot(tmpDir)
+		g.SetCache(cachePath)
+		if err := g.Build(tmpDir); err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		return g
+	}
+	dependsOn := func(g *Graph, dependent, dependency string) bool {
+		_, ok := g.Forward[dependent][filepath.Join(tmpDir, dependency)]
+		return ok
+	}
+
+	if g := build(); g.parsed.Load() != 4 {
+		t.Fatalf("Expected the first build to parse 4 files, parsed %d", g.parsed.Load())
+	}
+
+	g := build()
+	if n := g.parsed.Load(); n != 0 {
+		t.Errorf("Expected an unchanged tree to be read from the cache, parsed %d", n)
+	}
+	if !dependsOn(g, app, "src/a.ts") || !dependsOn(g, testPath, "src/app.ts") {
+		t.Errorf("Expected cached edges; got %v", g.Forward)
+	}
+
+	// Touched without changes: the content hash still matches.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(app, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if g := build(); g.parsed.Load() != 0 {
+		t.Errorf("Expected a touched file to be read from the cache, parsed %d", g.parsed.Load())
+	}
+
+	// An edited file, and a created file that a cached import waited for.
+	writeFiles(t, tmpDir, map[string]string{
+		"src/app.ts":   "import { b } from '@/b';",
+		"src/later.ts": "export {};",
+	})
+	g = build()
+	if n := g.parsed.Load(); n != 2 {
+		t.Errorf("Expected the edited and created files to be parsed, parsed %d", n)
+	}
+	if !dependsOn(g, app, "src/b.ts") || dependsOn(g, app, "src/a.ts") {
+		t.Errorf("Expected app.ts to depend on b.ts only; got %v", g.Forward[app])
+	}
+	if !dependsOn(g, testPath, "src/later.ts") {
+		t.Errorf("Expected the cached pending import to link to later.ts; got %v", g.Forward[testPath])
+	}
+
+	// A deleted dependency makes its importer resolve again.
+	if err := os.Remove(filepath.Join(tmpDir, "src/b.ts")); err != nil {
+		t.Fatal(err)
+	}
+	g = build()
+	if n := g.parsed.Load(); n != 1 {
+		t.Errorf("Expected the importer of a deleted file to be parsed, parsed %d", n)
+	}
+	if dependsOn(g, app, "src/b.ts") {
+		t.Errorf("Expected no edge to the deleted b.ts; got %v", g.Forward[app])
+	}
+
+	// A config change discards the whole cache.
+	writeFiles(t, tmpDir, map[string]string{
+		"tsconfig.json": `{"compilerOptions": {"baseUrl": ".", "paths": {"@/*": ["lib/*"]}}}`,
+	})
+	InvalidateTSConfigCache(tmpDir)
+	if g := build(); g.parsed.Load() != 4 {
+		t.Errorf("Expected a tsconfig change to re-parse all 4 files, parsed %d", g.parsed.Load())
+	}
+}
+
+func TestGraph_CacheTracksExtendedConfigs(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(t.TempDir(), "graph.json")
+	writeFiles(t, tmpDir, map[string]string{
+		"tsconfig.json":     `{"extends": "./configs/base.json"}`,
+		"configs/base.json": `{"compilerOptions": {"baseUrl": ".."}}`,
+		"src/a.ts":          "export const a = 1;",
+		"src/app.test.ts":   "import 'src/a';",
+	})
+	InvalidateTSConfigCache(tmpDir)
+
+	build := func() *Graph {
+		t.Helper()
+		g := NewGraphWithRoot(tmpDir)
+		g.SetCache(cachePath)
+		if err := g.Build(tmpDir); err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		return g
+	}
+
+	build()
+	if g := build(); g.parsed.Load() != 0 {
+		t.Fatalf("Expected an unchanged tree to be read from the cache, parsed %d", g.parsed.Load())
+	}
+
+	// The base is not named tsconfig.*, so only the recorded inputs catch it.
+	writeFiles(t, tmpDir, map[string]string{
+		"configs/base.json": `{"compilerOptions": {"baseUrl": "../src"}}`,
+	})
+	InvalidateTSConfigCache(tmpDir)
+	if g := build(); g.parsed.Load() != 2 {
+		t.Errorf("Expected a change to an extended config to re-parse both files, parsed %d", g.parsed.Load())
+	}
+}
+
+func TestGraph_Remove(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"a.ts":        "export const a = 1;",
+		"b.ts":        "import { a } from './a';",
+		"app.test.ts": "import { b } from './b';",
+	})
+	a := filepath.Join(tmpDir, "a.ts")
+	b := filepath.Join(tmpDir, "b.ts")
+	testPath := filepath.Join(tmpDir, "app.test.ts")
+
+	g := NewGraph()
+	if err := g.Build(tmpDir); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if err := os.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	dependents := g.Remove(a).Dependents()
+	if want := map[string]struct{}{b: {}, testPath: {}}; !reflect.DeepEqual(dependents, want) {
+		t.Errorf("Expected the former dependents %v, got %v", want, dependents)
+	}
+	if _, ok := g.Forward[b][a]; ok {
+		t.Errorf("Expected no edge to the deleted a.ts; got %v", g.Forward[b])
+	}
+	if _, ok := g.Reverse[a]; ok {
+		t.Errorf("Expected a.ts to be gone from Reverse; got %v", g.Reverse[a])
+	}
+	if !g.Tracks(a) {
+		t.Errorf("Expected b.ts's import of a.ts to be pending")
+	}
+
+	// Restored: the pending import links again.
+	writeFiles(t, tmpDir, map[string]string{"a.ts": "export const a = 2;"})
+	g.Update(a)
+	if _, ok := g.GetAffectedDependents(a)[testPath]; !ok {
+		t.Errorf("Expected app.test.ts to depend on the restored a.ts; got %v", g.Forward[b])
+	}
+
+	// Update of a deleted file removes it too.
+	if err := os.Remove(b); err != nil {
+		t.Fatal(err)
+	}
+	g.Update(b)
+	if _, ok := g.Forward[b]; ok {
+		t.Errorf("Expected the deleted b.ts to be removed; got %v", g.Forward[b])
+	}
+	if _, ok := g.Reverse[a][b]; ok {
+		t.Errorf("Expected no edge from the deleted b.ts; got %v", g.Reverse[a])
+	}
+	if _, ok := g.Forward[testPath][b]; ok || !g.Tracks(b) {
+		t.Errorf("Expected app.test.ts's import of b.ts to be pending; got %v", g.Forward[testPath])
+	}
+}
+
+func TestGraph_Explain(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"format.ts":      "export const format = 1;",
+		"index.ts":       "export * from './format';",
+		"price.ts":       "const { format } = require('./index');",
+		"price.test.ts":  "import { price } from './price';\nimport { format } from './format';",
+		"lazy.test.ts":   "const price = await import('./price');",
+		"mocked.test.ts": "import { price } from './price';\njest.mock('./price');",
+		"later.test.ts":  "import { later } from './later';",
+		"unrelated.ts":   "export {};",
+	})
+	path := func(name string) string { return filepath.Join(tmpDir, name) }
+
+	g := NewGraph()
+	if err := g.Build(tmpDir); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	writeFiles(t, tmpDir, map[string]string{"later.ts": "import './format';"})
+	g.Update(path("later.ts"))
+
+	x := g.Explain(path("format.ts"))
+	if got, want := x.Dependents(), g.GetAffectedDependents(path("format.ts")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected Explain to agree with GetAffectedDependents: %v vs %v", got, want)
+	}
+
+	tests := []struct {
+		file string
+		want []Hop
+	}{
+		// The direct import is shorter than the chain through price.ts.
+		{"price.test.ts", []Hop{{path("price.test.ts"), path("format.ts"), KindImport}}},
+		{"lazy.test.ts", []Hop{
+			{path("index.ts"), path("format.ts"), KindReExport},
+			{path("price.ts"), path("index.ts"), KindRequire},
+			{path("lazy.test.ts"), path("price.ts"), KindDynamic},
+		}},
+		// Linked from a pending import when later.ts appeared.
+		{"later.test.ts", []Hop{
+			{path("later.ts"), path("format.ts"), KindImport},
+			{path("later.test.ts"), path("later.ts"), KindImport},
+		}},
+		{"format.ts", nil},
+	}
+	for _, tt := range tests {
+		chain, ok := x.Chain(path(tt.file))
+		if !ok || !reflect.DeepEqual(chain, tt.want) {
+			t.Errorf("Chain(%s) = %v, %v; want %v", tt.file, chain, ok, tt.want)
+		}
+	}
+
+	if _, ok := x.Chain(path("mocked.test.ts")); ok {
+		t.Errorf("Expected mocked.test.ts not to be affected")
+	}
+	if _, ok := x.Chain(path("unrelated.ts")); ok {
+		t.Errorf("Expected unrelated.ts not to be affected")
+	}
+	if want := []Hop{{path("mocked.test.ts"), path("price.ts"), KindMock}}; !reflect.DeepEqual(x.Pruned, want) {
+		t.Errorf("Pruned = %v, want %v", x.Pruned, want)
+	}
+}
@@ -0,0 +1,223 @@
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// cacheVersion identifies the cache format and the parser output it stores.
+// Bump it when either changes so caches written by older builds are ignored.
+const cacheVersion = 3
+
+// graphCache is the on-disk form of a graph: each source file's imports,
+// keyed by path, with the stamp of the file they were parsed from.
+type graphCache struct {
+	Version int                   `json:"version"`
+	Config  string                `json:"config"` // Fingerprint of the configs that steer resolution
+	Inputs  map[string]string     `json:"inputs"` // SHA-256 of each config file resolution read, by path
+	Files   map[string]cacheEntry `json:"files"`
+}
+
+// cacheEntry is a file's parse result and the modification time (UnixNano),
+// size and SHA-256 of the content it came from.
+type cacheEntry struct {
+	ModTime    int64              `json:"mtime"`
+	Size       int64              `json:"size"`
+	Hash       string             `json:"hash"`
+	Resolved   []ResolvedImport   `json:"resolved,omitempty"`
+	Unresolved []UnresolvedImport `json:"unresolved,omitempty"`
+}
+
+// SetCache makes Build load the graph cache at path, re-parsing only files
+// whose modification time, size and content changed since, and save it when
+// done. Call it before Build.
+func (g *Graph) SetCache(path string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cachePath = path
+	g.entries = make(map[string]cacheEntry)
+}
+
+// SaveCache writes the parse results of the graph to the cache set with
+// SetCache. It does nothing without a cache.
+func (g *Graph) SaveCache() error {
+	g.mu.RLock()
+	path := g.cachePath
+	var inputs []string
+	for input := range g.inputs {
+		inputs = append(inputs, input)
+	}
+	g.mu.RUnlock()
+	if path == "" {
+		return nil
+	}
+
+	hashes := hashInputs(append(inputs, resolutionInputs()...))
+	g.mu.Lock()
+	g.inputs = hashes
+	data, err := json.Marshal(graphCache{Version: cacheVersion, Config: g.configHash, Inputs: hashes, Files: g.entries})
+	g.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so a crash never leaves a truncated cache.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadCache reads the cache at path and returns its entries and inputs. It
+// returns nil if the file is missing, invalid, written by another version,
+// built under other configs, or if any config file read during resolution
+// changed since.
+func loadCache(path, configHash string) (map[string]cacheEntry, map[string]string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil
+	}
+	var cache graphCache
+	if json.Unmarshal(data, &cache) != nil || cache.Version != cacheVersion || cache.Config != configHash {
+		return nil, nil
+	}
+	for input, hash := range cache.Inputs {
+		if hashFile(input) != hash {
+			return nil, nil
+		}
+	}
+	return cache.Files, cache.Inputs
+}
+
+// resolutionInputs returns the config files import resolution has read in
+// this process: every tsconfig in the extends and references chains, wherever
+// it lives and whatever it is named, and the runner configs whose aliases
+// were evaluated.
+func resolutionInputs() []string {
+	var inputs []string
+	for _, cache := range []*sync.Map{&rawConfigs, &aliasRules} {
+		cache.Range(func(key, _ any) bool {
+			inputs = append(inputs, key.(string))
+			return true
+		})
+	}
+	return inputs
+}
+
+// hashInputs maps each of paths to the SHA-256 of its content.
+func hashInputs(paths []string) map[string]string {
+	hashes := make(map[string]string, len(paths))
+	for _, path := range paths {
+		if _, ok := hashes[path]; !ok {
+			hashes[path] = hashFile(path)
+		}
+	}
+	return hashes
+}
+
+// hashFile returns the hex SHA-256 of the file at path, or "" if it cannot be
+// read, so a missing input that appears later also counts as a change.
+func hashFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// configFingerprint hashes what import resolution depends on besides the
+// importing file: the contents of the tsconfig, runner and package.json files
+// among configs, and the workspace packages. Any change to them invalidates
+// the whole cache, as does a change to the config files recorded as the
+// cache's inputs, which covers bases outside the walk such as node_modules.
+func configFingerprint(configs []string, pkgs []Package) string {
+	sort.Strings(configs)
+	h := sha256.New()
+	for _, path := range configs {
+		h.Write([]byte(path + "\x00"))
+		if data, err := os.ReadFile(path); err == nil {
+			h.Write(data)
+		}
+		h.Write([]byte{0})
+	}
+	for _, pkg := range pkgs {
+		h.Write([]byte(pkg.Name + "\x00" + pkg.Root + "\x00"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// parse returns the imports of the source file at path. With a cache, it
+// also returns the file's new cache entry, reusing prev's imports instead of
+// parsing when the file still has prev's stamp or content.
+func (g *Graph) parse(path string, prev *cacheEntry) (*ImportResult, *cacheEntry, error) {
+	if !g.caching() {
+		result, err := g.parser.ParseImports(path)
+		return result, nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	entry := &cacheEntry{ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+	if prev != nil && prev.ModTime == entry.ModTime && prev.Size == entry.Size && prev.linksExist() {
+		return prev.result(), prev, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	sum := sha256.Sum256(content)
+	entry.Hash = hex.EncodeToString(sum[:])
+	if prev != nil && prev.Hash == entry.Hash && prev.linksExist() {
+		// Touched but unchanged, e.g. by a branch switch
+		entry.Resolved, entry.Unresolved = prev.Resolved, prev.Unresolved
+		return entry.result(), entry, nil
+	}
+
+	result := g.parser.parseContent(path, content)
+	g.parsed.Add(1)
+	entry.Resolved, entry.Unresolved = result.Resolved, result.Unresolved
+	return result, entry, nil
+}
+
+func (g *Graph) caching() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.cachePath != ""
+}
+
+// linksExist reports whether every file the entry resolved to still exists.
+// A deleted dependency means the import resolves elsewhere or not at all.
+func (e *cacheEntry) linksExist() bool {
+	for _, imp := range e.Resolved {
+		if _, err := os.Stat(imp.Path); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *cacheEntry) result() *ImportResult {
+	return &ImportResult{Resolved: e.Resolved, Unresolved: e.Unresolved}
+}
@@ -0,0 +1,154 @@
+package analysis
+
+import (
+	"bytes"
+	"path/filepath"
+	"strconv"
+)
+
+// componentScript extracts the code of a Vue, Svelte or Astro component: its
+// <script> blocks and, for Astro, the frontmatter between the leading ---
+// fences. Vue and Svelte blocks may load their code or styles from a file
+// with src="...", which becomes an import. jsx reports whether any block is
+// JSX (lang="tsx" or "jsx").
+func componentScript(path string, src []byte) (code []byte, jsx bool) {
+	var b bytes.Buffer
+	if filepath.Ext(path) == ".astro" {
+		if frontmatter, ok := astroFrontmatter(src); ok {
+			b.Write(frontmatter)
+			b.WriteString("\n;\n")
+		}
+	}
+
+	for i := 0; i < len(src); {
+		lt := bytes.IndexByte(src[i:], '<')
+		if lt < 0 {
+			break
+		}
+		i += lt
+		if bytes.HasPrefix(src[i:], []byte("<!--")) {
+			end := bytes.Index(src[i+4:], []byte("-->"))
+			if end < 0 {
+				break
+			}
+			i += 4 + end + 3
+			continue
+		}
+		tag := blockTag(src[i:])
+		if tag == "" {
+			i++
+			continue
+		}
+		attrs, bodyStart := openTag(src, i+1+len(tag))
+		if bodyStart < 0 {
+			break
+		}
+		closing := indexFold(src[bodyStart:], "</"+tag)
+		if closing < 0 {
+			closing = len(src) - bodyStart
+		}
+		body := src[bodyStart : bodyStart+closing]
+		i = bodyStart + closing
+
+		if ref, ok := attrs["src"]; ok && ref != "" {
+			b.WriteString("import " + strconv.Quote(ref) + ";\n")
+		}
+		if tag != "script" {
+			continue
+		}
+		switch attrs["lang"] {
+		case "tsx", "jsx":
+			jsx = true
+		}
+		b.Write(body)
+		b.WriteString("\n;\n")
+	}
+	return b.Bytes(), jsx
+}
+
+// blockTag returns the name of the top-level block tag at the start of s, or
+// "" if it is not one.
+func blockTag(s []byte) string {
+	for _, tag := range []string{"script", "style"} {
+		if len(s) > len(tag)+1 && bytes.EqualFold(s[1:1+len(tag)], []byte(tag)) {
+			switch s[1+len(tag)] {
+			case '>', ' ', '\t', '\n', '\r', '/':
+				return tag
+			}
+		}
+	}
+	return ""
+}
+
+// openTag reads the attributes of the tag whose name ends at i, returning
+// them lowercased by name and the index after the tag's ">". Values may be
+// quoted with either quote and contain ">". bodyStart is -1 if the tag never
+// ends.
+func openTag(src []byte, i int) (attrs map[string]string, bodyStart int) {
+	attrs = map[string]string{}
+	for i < len(src) {
+		switch c := src[i]; {
+		case c == '>':
+			return attrs, i + 1
+		case c == '/' || c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		default:
+			start := i
+			for i < len(src) && bytes.IndexByte([]byte(" \t\n\r=/>"), src[i]) < 0 {
+				i++
+			}
+			name := string(bytes.ToLower(src[start:i]))
+			value := ""
+			if i < len(src) && src[i] == '=' {
+				i++
+				if i < len(src) && (src[i] == '"' || src[i] == '\'') {
+					end := bytes.IndexByte(src[i+1:], src[i])
+					if end < 0 {
+						return attrs, -1
+					}
+					value = string(src[i+1 : i+1+end])
+					i += end + 2
+				} else {
+					start := i
+					for i < len(src) && bytes.IndexByte([]byte(" \t\n\r>"), src[i]) < 0 {
+						i++
+					}
+					value = string(src[start:i])
+				}
+			}
+			attrs[name] = value
+		}
+	}
+	return attrs, -1
+}
+
+// astroFrontmatter returns the code between an Astro component's leading ---
+// fences.
+func astroFrontmatter(src []byte) ([]byte, bool) {
+	rest := bytes.TrimLeft(bytes.TrimPrefix(src, []byte("\ufeff")), " \t\r\n")
+	if !bytes.HasPrefix(rest, []byte("---")) {
+		return nil, false
+	}
+	rest = rest[3:]
+	for i := 0; i < len(rest); {
+		nl := bytes.IndexByte(rest[i:], '\n')
+		if nl < 0 {
+			return nil, false
+		}
+		i += nl + 1
+		if bytes.HasPrefix(rest[i:], []byte("---")) {
+			return rest[:i], true
+		}
+	}
+	return nil, false
+}
+
+// indexFold is bytes.Index ignoring ASCII case in sep.
+func indexFold(s []byte, sep string) int {
+	for i := 0; i+len(sep) <= len(s); i++ {
+		if bytes.EqualFold(s[i:i+len(sep)], []byte(sep)) {
+			return i
+		}
+	}
+	return -1
+}
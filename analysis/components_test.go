@@ -0,0 +1,68 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComponentScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		src     string
+		want    []string
+		wantJSX bool
+	}{
+		{"vue script and setup", "Button.vue", `<template>
+  <button :class="$style.button"><Icon /></button>
+</template>
+
+<script>
+import { defineComponent } from 'vue';
+</script>
+
+<script setup lang="ts">
+import Icon from './Icon.vue';
+const n = <number>props.count;
+</script>
+
+<style module src="./button.css"></style>`, []string{"vue", "./Icon.vue", "./button.css"}, false},
+		{"vue tsx and external script", "List.vue", `<script lang="tsx" src="./list.tsx"></script>
+<script setup lang='tsx' generic="T extends Item<'a'>">
+import Row from './Row';
+const render = () => <Row title="it's" />;
+</script>`, []string{"./list.tsx", "./Row"}, true},
+		{"vue comments", "C.vue", `<!-- <script>import x from './commented';</script> -->
+<template><p>import y from './in-template'</p></template>
+<SCRIPT>import z from './upper';</SCRIPT>`, []string{"./upper"}, false},
+		{"svelte module and instance", "Card.svelte", `<script context="module" lang="ts">
+  export const prerender = true;
+  import { load } from './load';
+</script>
+<script lang="ts">
+  import Button from './Button.svelte';
+</script>
+{#if ok}<Button />{/if}`, []string{"./load", "./Button.svelte"}, false},
+		{"astro frontmatter and scripts", "index.astro", `---
+import Layout from '../layouts/Layout.astro';
+const posts = await import('./posts');
+---
+<Layout><h1>import nothing from './text'</h1></Layout>
+<script>
+  import { track } from './analytics';
+</script>`, []string{"../layouts/Layout.astro", "./posts", "./analytics"}, false},
+		{"astro without frontmatter", "plain.astro", `<p>---</p><script>import './a';</script>`, []string{"./a"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, jsx := componentScript(tt.path, []byte(tt.src))
+			got := specPaths(scanImports(code, jsx))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("imports = %q, want %q\ncode:\n%s", got, tt.want, code)
+			}
+			if jsx != tt.wantJSX {
+				t.Errorf("jsx = %v, want %v", jsx, tt.wantJSX)
+			}
+		})
+	}
+}
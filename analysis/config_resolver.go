@@ -1,139 +1,505 @@
 package analysis
 
 import (
+	"bytes"
 	"encoding/json"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 )
 
-// tsConfig mirrors the subset of tsconfig.json we care about.
-type tsConfig struct {
+// rawTSConfig mirrors the subset of a single tsconfig file we care about,
+// before its extends chain is merged. Pointers tell an unset field from an
+// empty one, which still overrides the base.
+type rawTSConfig struct {
+	Extends         extendsList `json:"extends"`
 	CompilerOptions struct {
-		BaseUrl string              `json:"baseUrl"`
-		Paths   map[string][]string `json:"paths"`
+		BaseUrl *string              `json:"baseUrl"`
+		Paths   *map[string][]string `json:"paths"`
 	} `json:"compilerOptions"`
+	Files      *[]string `json:"files"`
+	Include    *[]string `json:"include"`
+	Exclude    *[]string `json:"exclude"`
+	References []struct {
+		Path string `json:"path"`
+	} `json:"references"`
 }
 
-// configCache caches parsed tsconfig data per project root to avoid re-reading disk on
-// every import. The cache is invalidated implicitly when the process restarts.
-var configCache sync.Map // map[root string]*tsConfig
-
-// loadTSConfig reads and parses tsconfig.json from root, with in-process caching.
-// Returns nil if the file does not exist or cannot be parsed.
-func loadTSConfig(root string) *tsConfig {
-	if v, ok := configCache.Load(root); ok {
-		if cfg, ok := v.(*tsConfig); ok {
-			return cfg
-		}
-	}
+// extendsList is "extends", which is a string or, since TypeScript 5.0, an
+// array of them.
+type extendsList []string
 
-	tscPath := filepath.Join(root, "tsconfig.json")
-	data, err := os.ReadFile(tscPath)
-	if err != nil {
-		// File not present – cache nil so we don't retry every call.
-		configCache.Store(root, (*tsConfig)(nil))
+func (l *extendsList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*l = extendsList{one}
 		return nil
 	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
 
-	var cfg tsConfig
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		configCache.Store(root, (*tsConfig)(nil))
-		return nil
-	}
+// tsConfig is a tsconfig file with its extends chain merged. Paths in it are
+// absolute.
+type tsConfig struct {
+	path string
+	dir  string
+
+	baseURL  string        // Empty if unset
+	paths    []pathMapping // Most specific first
+	pathsDir string        // Directory of the config that set paths
+
+	files, include, exclude []string // Nil if unset
+	references              []string // Referenced config files
+}
 
-	configCache.Store(root, &cfg)
-	return &cfg
+// pathMapping is one compilerOptions.paths entry.
+type pathMapping struct {
+	pattern string
+	targets []string
 }
 
-// InvalidateTSConfigCache removes the cached tsconfig for root.
-// Call this when tsconfig.json is known to have changed.
+// Caches by absolute path, cleared by InvalidateTSConfigCache. rawConfigs and
+// mergedConfigs hold nil for a missing or invalid file; nearestConfigs maps a
+// directory to the closest tsconfig.json path, or "".
+var (
+	rawConfigs     sync.Map // map[string]*rawTSConfig
+	mergedConfigs  sync.Map // map[string]*tsConfig
+	nearestConfigs sync.Map // map[string]string
+)
+
+// defaultExclude is what tsc excludes when a config sets no exclude.
+var defaultExclude = []string{"node_modules", "bower_components", "jspm_packages"}
+
+// InvalidateTSConfigCache drops every cached tsconfig under root, including
+// configs it extends from node_modules. Call this when a tsconfig is known to
+// have changed.
 func InvalidateTSConfigCache(root string) {
-	configCache.Delete(root)
+	for _, cache := range []*sync.Map{&rawConfigs, &mergedConfigs, &nearestConfigs} {
+		cache.Range(func(key, _ any) bool {
+			if isWithin(key.(string), root) {
+				cache.Delete(key)
+			}
+			return true
+		})
+	}
 }
 
 // ResolveAlias attempts to map importPath to an absolute filesystem path using
-// the tsconfig.json compilerOptions.paths and baseUrl at root.
+// the compilerOptions.paths and baseUrl of the tsconfig.json at root, merged
+// with the configs it extends.
 //
-// Pattern matching supports the wildcard suffix `*`, e.g.:
+// Patterns may contain one `*`, e.g.:
 //
 //	"@/*" → ["./src/*"]  resolves  "@/utils"  →  "<root>/src/utils"
 //
 // Returns (resolvedAbsPath, true) on success, ("", false) if no alias matched.
 func ResolveAlias(importPath string, root string) (string, bool) {
-	cfg := loadTSConfig(root)
+	cfg := loadTSConfig(filepath.Join(root, "tsconfig.json"))
 	if cfg == nil {
 		return "", false
 	}
+	return cfg.resolve(importPath, NewParser().findFile)
+}
 
-	baseURL := cfg.CompilerOptions.BaseUrl
-	paths := cfg.CompilerOptions.Paths
-	if len(paths) == 0 {
+// resolveAlias resolves importPath with the tsconfig that compiles
+// sourcePath: the nearest tsconfig.json up to the project root or, for a
+// solution-style config, the referenced project that includes the file.
+func (p *Parser) resolveAlias(importPath, sourcePath string) (string, bool) {
+	if p.root == "" {
+		return "", false
+	}
+	nearest := nearestTSConfig(filepath.Dir(sourcePath), p.root)
+	if nearest == "" {
+		return "", false
+	}
+	cfg := loadTSConfig(nearest)
+	if cfg == nil {
 		return "", false
 	}
+	return cfg.projectFor(sourcePath, map[string]bool{}).resolve(importPath, p.findFile)
+}
 
-	// Try each pattern in order.
-	for pattern, targets := range paths {
-		if len(targets) == 0 {
-			continue
+// nearestTSConfig returns the tsconfig.json closest to dir, looking no higher
+// than root, or "" if there is none.
+func nearestTSConfig(dir, root string) string {
+	if cached, ok := nearestConfigs.Load(dir); ok {
+		return cached.(string)
+	}
+	found := ""
+	if _, err := os.Stat(filepath.Join(dir, "tsconfig.json")); err == nil {
+		found = filepath.Join(dir, "tsconfig.json")
+	} else if parent := filepath.Dir(dir); dir != root && parent != dir && isWithin(parent, root) {
+		found = nearestTSConfig(parent, root)
+	}
+	nearestConfigs.Store(dir, found)
+	return found
+}
+
+// loadTSConfig returns the config at path merged with everything it extends,
+// with in-process caching. Returns nil if the file does not exist or cannot
+// be parsed.
+func loadTSConfig(path string) *tsConfig {
+	if cached, ok := mergedConfigs.Load(path); ok {
+		return cached.(*tsConfig)
+	}
+
+	cfg := &tsConfig{path: path, dir: filepath.Dir(path)}
+	if !cfg.apply(path, map[string]bool{}) {
+		cfg = nil
+	} else {
+		// References are the one setting extends does not pass on.
+		for _, ref := range loadRawTSConfig(path).References {
+			refPath := cfg.abs(cfg.dir, ref.Path)
+			if info, err := os.Stat(refPath); err == nil && info.IsDir() {
+				refPath = filepath.Join(refPath, "tsconfig.json")
+			}
+			cfg.references = append(cfg.references, refPath)
 		}
+	}
+	mergedConfigs.Store(path, cfg)
+	return cfg
+}
 
-		remainder, matched := matchAliasPattern(pattern, importPath)
-		if !matched {
-			continue
+// apply merges the config file at path into c: first the configs it extends,
+// in order, then its own settings. Relative paths are resolved against the
+// directory of the file that sets them.
+func (c *tsConfig) apply(path string, seen map[string]bool) bool {
+	raw := loadRawTSConfig(path)
+	if raw == nil || seen[path] {
+		return false
+	}
+	seen[path] = true
+	dir := filepath.Dir(path)
+
+	for _, base := range raw.Extends {
+		if basePath := resolveExtends(dir, base); basePath != "" {
+			c.apply(basePath, seen)
+		}
+	}
+
+	opts := raw.CompilerOptions
+	if opts.BaseUrl != nil {
+		c.baseURL = c.abs(dir, *opts.BaseUrl)
+	}
+	if opts.Paths != nil {
+		c.paths = sortPathMappings(*opts.Paths)
+		c.pathsDir = dir
+	}
+	if raw.Files != nil {
+		c.files = c.absAll(dir, *raw.Files)
+	}
+	if raw.Include != nil {
+		c.include = c.absAll(dir, *raw.Include)
+	}
+	if raw.Exclude != nil {
+		c.exclude = c.absAll(dir, *raw.Exclude)
+	}
+	return true
+}
+
+// loadRawTSConfig reads and parses a single tsconfig file, which may contain
+// comments and trailing commas, with in-process caching.
+func loadRawTSConfig(path string) *rawTSConfig {
+	if cached, ok := rawConfigs.Load(path); ok {
+		return cached.(*rawTSConfig)
+	}
+
+	var cfg *rawTSConfig
+	if data, err := os.ReadFile(path); err == nil {
+		var raw rawTSConfig
+		if json.Unmarshal(stripJSONC(data), &raw) == nil {
+			cfg = &raw
 		}
+	}
+	rawConfigs.Store(path, cfg)
+	return cfg
+}
 
-		// Use the first target (mirrors TypeScript compiler behaviour).
-		target := targets[0]
+// resolveExtends finds the file an "extends" entry in a config in dir names:
+// a path relative to dir, or a package in node_modules, optionally with a
+// path inside it ("@tsconfig/node20/tsconfig.json"). Returns "" if not found.
+func resolveExtends(dir, spec string) string {
+	if filepath.IsAbs(spec) {
+		return configFile(spec)
+	}
+	if strings.HasPrefix(spec, ".") {
+		return configFile(filepath.Join(dir, filepath.FromSlash(spec)))
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		target := filepath.Join(d, "node_modules", filepath.FromSlash(spec))
+		if found := configFile(target); found != "" {
+			return found
+		}
+		if info, err := os.Stat(target); err == nil && info.IsDir() {
+			var manifest struct {
+				TSConfig string `json:"tsconfig"`
+			}
+			if data, err := os.ReadFile(filepath.Join(target, "package.json")); err == nil &&
+				json.Unmarshal(data, &manifest) == nil && manifest.TSConfig != "" {
+				return configFile(filepath.Join(target, manifest.TSConfig))
+			}
+			return configFile(filepath.Join(target, "tsconfig.json"))
+		}
+		if filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
 
-		// Substitute wildcard in target with the remainder.
-		var relative string
-		if strings.HasSuffix(target, "/*") || strings.HasSuffix(target, "\\*") {
-			base := target[:len(target)-2] // strip the trailing /*
-			relative = filepath.Join(base, remainder)
-		} else {
-			relative = target
+// configFile returns path, or path with ".json" added, whichever is a file.
+func configFile(path string) string {
+	for _, candidate := range []string{path, path + ".json"} {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
 		}
+	}
+	return ""
+}
 
-		// Resolve relative to baseUrl (which itself is relative to root).
-		var absPath string
-		if baseURL != "" {
-			absBase := filepath.Join(root, baseURL)
-			absPath = filepath.Join(absBase, relative)
-		} else {
-			absPath = filepath.Join(root, relative)
+// sortPathMappings orders paths the way tsc tries them: exact patterns first,
+// then wildcard patterns by the length of their prefix before "*", longest
+// first.
+func sortPathMappings(paths map[string][]string) []pathMapping {
+	mappings := make([]pathMapping, 0, len(paths))
+	for pattern, targets := range paths {
+		mappings = append(mappings, pathMapping{pattern: pattern, targets: targets})
+	}
+	rank := func(pattern string) int {
+		prefix, _, wildcard := strings.Cut(pattern, "*")
+		if !wildcard {
+			return len(pattern) + 1<<20
 		}
+		return len(prefix)
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		ri, rj := rank(mappings[i].pattern), rank(mappings[j].pattern)
+		if ri != rj {
+			return ri > rj
+		}
+		return mappings[i].pattern < mappings[j].pattern
+	})
+	return mappings
+}
 
-		return absPath, true
+// resolve maps importPath through paths, then baseUrl. Each target of the
+// matching pattern is tried in order and the first one found wins; if none
+// exists yet, the first is returned so the import can be linked once it is
+// created. Only the catch-all "*" pattern is skipped in that case, since it
+// matches every package import.
+func (c *tsConfig) resolve(importPath string, find func(string) (string, bool)) (string, bool) {
+	for _, mapping := range c.paths {
+		star, ok := matchAliasPattern(mapping.pattern, importPath)
+		if !ok {
+			continue
+		}
+		base := c.baseURL
+		if base == "" {
+			base = c.pathsDir
+		}
+		first := ""
+		for _, target := range mapping.targets {
+			candidate := c.abs(base, strings.Replace(target, "*", star, 1))
+			if _, found := find(candidate); found {
+				return candidate, true
+			}
+			if first == "" {
+				first = candidate
+			}
+		}
+		if first != "" && mapping.pattern != "*" {
+			return first, true
+		}
+		break
 	}
 
+	if c.baseURL != "" && !strings.HasPrefix(importPath, ".") {
+		candidate := filepath.Join(c.baseURL, filepath.FromSlash(importPath))
+		if _, found := find(candidate); found {
+			return candidate, true
+		}
+	}
 	return "", false
 }
 
-// matchAliasPattern checks whether importPath matches a tsconfig path alias pattern.
+// matchAliasPattern checks whether importPath matches a tsconfig path alias
+// pattern, which may contain one "*":
 //
-// Supported forms:
 //   - Exact:    "@/utils"   matches   "@/utils"                   (remainder = "")
 //   - Wildcard: "@/*"       matches   "@/components/button"       (remainder = "components/button")
 func matchAliasPattern(pattern, importPath string) (remainder string, ok bool) {
-	if strings.HasSuffix(pattern, "/*") || strings.HasSuffix(pattern, "\\*") {
-		// Wildcard pattern: strip trailing /* from pattern and check prefix.
-		prefix := pattern[:len(pattern)-2]
-		if strings.HasPrefix(importPath, prefix+"/") {
-			return importPath[len(prefix)+1:], true
+	prefix, suffix, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return "", importPath == pattern
+	}
+	if len(importPath) < len(prefix)+len(suffix) ||
+		!strings.HasPrefix(importPath, prefix) || !strings.HasSuffix(importPath, suffix) {
+		return "", false
+	}
+	return importPath[len(prefix) : len(importPath)-len(suffix)], true
+}
+
+// projectFor returns the config that compiles file: c if it includes the
+// file, otherwise the first referenced project that does. Solution-style
+// configs ("files": [] plus references) rely on this. Falls back to c.
+func (c *tsConfig) projectFor(file string, seen map[string]bool) *tsConfig {
+	if c.includes(file) {
+		return c
+	}
+	seen[c.path] = true
+	for _, ref := range c.references {
+		if seen[ref] {
+			continue
 		}
-		// Also allow exact match of the prefix (import "@" when pattern is "@/*").
-		if importPath == prefix {
-			return "", true
+		if refCfg := loadTSConfig(ref); refCfg != nil {
+			if project := refCfg.projectFor(file, seen); project.includes(file) {
+				return project
+			}
 		}
-		return "", false
 	}
+	return c
+}
 
-	// Exact pattern match.
-	if importPath == pattern {
-		return "", true
+// includes reports whether file is part of the project, going by files,
+// include and exclude.
+func (c *tsConfig) includes(file string) bool {
+	for _, f := range c.files {
+		if f == file {
+			return true
+		}
+	}
+	include := c.include
+	if include == nil && c.files == nil {
+		include = []string{filepath.Join(c.dir, "**", "*")}
 	}
+	exclude := c.exclude
+	if exclude == nil {
+		exclude = c.absAll(c.dir, defaultExclude)
+	}
+	return matchesAnySpec(include, file) && !matchesAnySpec(exclude, file)
+}
 
-	return "", false
+// abs resolves p, relative to dir, substituting ${configDir} with the
+// directory of the config being merged into.
+func (c *tsConfig) abs(dir, p string) string {
+	p = filepath.FromSlash(strings.ReplaceAll(p, "${configDir}", c.dir))
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(dir, p)
+}
+
+func (c *tsConfig) absAll(dir string, paths []string) []string {
+	abs := make([]string, len(paths))
+	for i, p := range paths {
+		abs[i] = c.abs(dir, p)
+	}
+	return abs
+}
+
+// matchesAnySpec reports whether file matches one of the include or exclude
+// specs, which use "*", "?" and "**/" wildcards. A spec whose last segment
+// has no wildcard also matches everything below it, as a directory.
+func matchesAnySpec(specs []string, file string) bool {
+	fileParts := strings.Split(filepath.ToSlash(file), "/")
+	for _, spec := range specs {
+		specParts := strings.Split(filepath.ToSlash(spec), "/")
+		if matchSegments(specParts, fileParts) {
+			return true
+		}
+		if !strings.ContainsAny(specParts[len(specParts)-1], "*?") &&
+			matchSegments(append(specParts, "**", "*"), fileParts) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against a glob's segments, where "**"
+// stands for any number of directories.
+func matchSegments(glob, parts []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(glob[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(glob[0], parts[0]); !ok {
+			return false
+		}
+		glob, parts = glob[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// isWithin reports whether p is root or inside it.
+func isWithin(p, root string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// stripJSONC removes the comments and trailing commas that tsconfig files
+// allow, so the rest parses as JSON.
+func stripJSONC(data []byte) []byte {
+	var out []byte
+	scanJSON(data, func(i int) int {
+		switch {
+		case data[i] == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			return i
+		case data[i] == '/' && i+1 < len(data) && data[i+1] == '*':
+			if end := bytes.Index(data[i+2:], []byte("*/")); end >= 0 {
+				return i + end + 4
+			}
+			return len(data)
+		}
+		out = append(out, data[i])
+		return i + 1
+	}, func(str []byte) { out = append(out, str...) })
+
+	// Comments are gone, so a comma only has whitespace before a closing
+	// bracket when it trails.
+	data, out = out, nil
+	scanJSON(data, func(i int) int {
+		if data[i] == ',' {
+			rest := bytes.TrimLeft(data[i+1:], " \t\r\n")
+			if len(rest) > 0 && (rest[0] == '}' || rest[0] == ']') {
+				return i + 1
+			}
+		}
+		out = append(out, data[i])
+		return i + 1
+	}, func(str []byte) { out = append(out, str...) })
+	return out
+}
+
+// scanJSON walks data, passing each string literal whole to str and the
+// index of every other byte to other, which returns where to continue.
+func scanJSON(data []byte, other func(i int) int, str func([]byte)) {
+	for i := 0; i < len(data); {
+		if data[i] != '"' {
+			i = other(i)
+			continue
+		}
+		end := i + 1
+		for end < len(data) && data[end] != '"' {
+			if data[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		end = min(end+1, len(data))
+		str(data[i:end])
+		i = end
+	}
 }
@@ -1,9 +1,13 @@
 package analysis
 
 import (
+	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/jesspatton/lazytest/filesystem"
 )
@@ -28,29 +32,79 @@ type Graph struct {
 	parser *Parser
 	root   string
 	mu     sync.RWMutex
+
+	// kinds holds the form of each import: file -> dependency (or pending
+	// import path) -> kind.
+	kinds map[string]map[string]ImportKind
+
+	// cachePath is the graph cache set with SetCache, entries the cache
+	// entries of the parsed files, configHash the fingerprint of the
+	// configs they were resolved under, and inputs the config files read
+	// while resolving them, with their hashes.
+	cachePath  string
+	entries    map[string]cacheEntry
+	configHash string
+	inputs     map[string]string
+	parsed     atomic.Int64 // Files parsed rather than read from the cache
 }
 
 // NewGraph creates a new dependency graph without TS alias resolution.
-// Use NewGraphWithRoot to enable tsconfig.json path alias resolution.
+// Use NewGraphWithRoot to enable tsconfig path alias resolution.
 func NewGraph() *Graph {
 	return NewGraphWithRoot("")
 }
 
 // NewGraphWithRoot creates a Graph that resolves TypeScript path aliases by
-// reading tsconfig.json at root.
+// reading the tsconfig.json nearest each file, up to root.
 func NewGraphWithRoot(root string) *Graph {
 	return &Graph{
 		Forward:        make(map[string]map[string]DependencyType),
 		Reverse:        make(map[string]map[string]DependencyType),
 		PendingImports: make(map[string]map[string]DependencyType),
+		kinds:          make(map[string]map[string]ImportKind),
 		parser:         NewParserWithRoot(root),
 		root:           root,
 	}
 }
 
-// Build walks the root directory and builds the graph.
+// SetPackages lets imports of the given workspace packages, such as
+// "@acme/utils", resolve to the packages' source files. Call it before Build.
+func (g *Graph) SetPackages(pkgs []Package) {
+	g.parser.SetPackages(pkgs)
+}
+
+// Build walks the root directory and builds the graph. With a cache set,
+// unchanged files are read from it and the cache is saved afterwards.
 func (g *Graph) Build(root string) error {
-	fileListQueue := filesystem.StreamFiles(root)
+	var sources, configs, others []string
+	for f := range filesystem.StreamFiles(root) {
+		switch {
+		case filesystem.IsSourceFile(f.Filename):
+			sources = append(sources, f.Location)
+		case filesystem.IsConfigFile(f.Filename):
+			configs = append(configs, f.Location)
+		default:
+			others = append(others, f.Location)
+		}
+	}
+
+	var cached map[string]cacheEntry
+	if g.caching() {
+		configHash := configFingerprint(configs, g.parser.packages)
+		g.mu.Lock()
+		g.configHash = configHash
+		cached, g.inputs = loadCache(g.cachePath, configHash)
+		g.mu.Unlock()
+	}
+
+	fileListQueue := make(chan string, 100)
+	go func() {
+		for _, path := range sources {
+			fileListQueue <- path
+		}
+		close(fileListQueue)
+	}()
+
 	var wg sync.WaitGroup
 
 	// Use a fixed number of workers for now, or could be runtime.NumCPU()
@@ -60,26 +114,53 @@ func (g *Graph) Build(root string) error {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for f := range fileListQueue {
-				if filesystem.IsSourceFile(f.Filename) {
-					g.Update(f.Location)
+			for path := range fileListQueue {
+				var prev *cacheEntry
+				if entry, ok := cached[path]; ok {
+					prev = &entry
 				}
+				g.update(path, prev)
 			}
 		}()
 	}
 
 	wg.Wait()
-	return nil
+
+	// Cached imports of files that did not exist when they were parsed are
+	// still pending; link them to the files that exist now.
+	g.mu.Lock()
+	for _, files := range [][]string{sources, configs, others} {
+		for _, path := range files {
+			g.linkPending(path)
+		}
+	}
+	g.mu.Unlock()
+
+	return g.SaveCache()
 }
 
-// Update re-parses a specific file and updates the graph.
+// Update re-parses a specific file and updates the graph. Files that are not
+// source code (JSON, stylesheets, fixtures) have no imports to parse; updating
+// one only links the files that were waiting for it to exist.
 func (g *Graph) Update(path string) {
-	// Parse outside the lock
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		g.Remove(path)
+		return
+	}
 	if !filesystem.IsSourceFile(filepath.Base(path)) {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.linkPending(path)
 		return
 	}
+	g.update(path, nil)
+}
 
-	result, err := g.parser.ParseImports(path)
+// update parses the source file at path, or takes its imports from prev, and
+// replaces its edges in the graph.
+func (g *Graph) update(path string, prev *cacheEntry) {
+	// Parse outside the lock
+	result, entry, err := g.parse(path, prev)
 	if err != nil {
 		return // Ignore errors for now
 	}
@@ -87,6 +168,10 @@ func (g *Graph) Update(path string) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	if entry != nil && g.entries != nil {
+		g.entries[path] = *entry
+	}
+
 	// Clear old dependencies for this file from Reverse map
 	if oldDeps, ok := g.Forward[path]; ok {
 		for dep := range oldDeps {
@@ -96,12 +181,14 @@ func (g *Graph) Update(path string) {
 
 	// Update Forward map
 	g.Forward[path] = make(map[string]DependencyType)
+	g.kinds[path] = make(map[string]ImportKind)
 	for _, imp := range result.Resolved {
 		depType := DepRegular
 		if imp.Mocked {
 			depType = DepMocked
 		}
 		g.Forward[path][imp.Path] = depType
+		g.kinds[path][imp.Path] = imp.Kind
 		g.addReverseDependency(imp.Path, path, depType)
 	}
 
@@ -111,34 +198,92 @@ func (g *Graph) Update(path string) {
 		if unresolved.Mocked {
 			depType = DepMocked
 		}
+		g.kinds[path][unresolved.Path] = unresolved.Kind
 		g.addPendingImport(unresolved.Path, path, depType)
 	}
 
-	// Check if this new/updated file resolves any pending imports.
-	// The pending import path is the absolute path WITHOUT extension (from resolvePaths).
-	// Instead of iterating over all pending imports (O(N)), we generate the possible keys
-	// this file could satisfy and look them up directly (O(1)).
+	g.linkPending(path)
+}
 
-	candidates := []string{}
+// Remove deletes path, a file that no longer exists, from the graph. Its
+// importers are parsed again, so their imports of it resolve to another file
+// or wait in PendingImports for it to come back. Remove returns how the
+// change reached the files that depended on path before it was removed.
+func (g *Graph) Remove(path string) *Explanation {
+	affected := g.Explain(path)
 
-	// 1. Exact match (e.g. import "./foo.js" -> /path/to/foo.js)
-	candidates = append(candidates, path)
+	g.mu.Lock()
+	if deps, ok := g.Forward[path]; ok {
+		for dep := range deps {
+			g.removeReverseDependency(dep, path)
+		}
+		delete(g.Forward, path)
+	}
+	for importPath, waiting := range g.PendingImports {
+		delete(waiting, path)
+		if len(waiting) == 0 {
+			delete(g.PendingImports, importPath)
+		}
+	}
+	var importers []string
+	for importer := range g.Reverse[path] {
+		importers = append(importers, importer)
+		delete(g.Forward[importer], path)
+	}
+	delete(g.Reverse, path)
+	delete(g.kinds, path)
+	delete(g.entries, path)
+	g.mu.Unlock()
+
+	// An importer deleted too is removed by its own event.
+	for _, importer := range importers {
+		g.update(importer, nil)
+	}
+	return affected
+}
 
-	// 2. Strip extension (e.g. import "./foo" -> /path/to/foo)
-	ext := filepath.Ext(path)
-	if ext != "" {
-		candidates = append(candidates, strings.TrimSuffix(path, ext))
+// ReadsConfig reports whether resolving the graph's imports read path as a
+// config: a tsconfig in an extends or references chain, whatever its name,
+// or a runner config with aliases. Files recorded as inputs of a loaded cache
+// count too, since no file was re-read for them. The watcher uses it to treat
+// edits to such files as config changes.
+func (g *Graph) ReadsConfig(path string) bool {
+	g.mu.RLock()
+	_, ok := g.inputs[path]
+	g.mu.RUnlock()
+	if ok {
+		return true
 	}
+	_, ok = rawConfigs.Load(path)
+	return ok
+}
 
-	// 3. Index files (e.g. import "./foo" -> /path/to/foo/index.ts -> /path/to/foo)
-	// We check if the file is an index file and add the parent directory as a candidate.
-	name := filepath.Base(path)
-	nameNoExt := strings.TrimSuffix(name, ext)
-	if nameNoExt == "index" {
-		candidates = append(candidates, filepath.Dir(path))
+// Tracks reports whether the graph links path: a parsed file, a dependency of
+// one, or an import waiting for the file to exist. The watcher uses it to
+// pass on edits to imported files it would otherwise ignore, such as JSON
+// fixtures and CSS modules.
+func (g *Graph) Tracks(path string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if _, ok := g.Forward[path]; ok {
+		return true
+	}
+	if _, ok := g.Reverse[path]; ok {
+		return true
+	}
+	for _, candidate := range pendingCandidates(path) {
+		if _, ok := g.PendingImports[candidate]; ok {
+			return true
+		}
 	}
+	return false
+}
 
-	for _, candidate := range candidates {
+// linkPending checks if a new or updated file resolves any pending imports.
+// The caller must hold the write lock.
+func (g *Graph) linkPending(path string) {
+	for _, candidate := range pendingCandidates(path) {
 		if dependents, ok := g.PendingImports[candidate]; ok {
 			// It's a match! Link them.
 			for dep, depType := range dependents {
@@ -148,6 +293,9 @@ func (g *Graph) Update(path string) {
 					g.Forward[dep] = make(map[string]DependencyType)
 				}
 				g.Forward[dep][path] = depType
+				if kinds, ok := g.kinds[dep]; ok {
+					kinds[path] = kinds[candidate]
+				}
 			}
 			// Remove from pending
 			delete(g.PendingImports, candidate)
@@ -155,6 +303,55 @@ func (g *Graph) Update(path string) {
 	}
 }
 
+// pendingCandidates returns the pending import keys the file at path could
+// satisfy. The pending import path is the absolute path WITHOUT extension
+// (from resolvePaths). Instead of iterating over all pending imports (O(N)),
+// we generate the possible keys this file could satisfy and look them up
+// directly (O(1)).
+func pendingCandidates(path string) []string {
+	candidates := []string{}
+
+	// 1. Exact match (e.g. import "./foo.js" -> /path/to/foo.js)
+	candidates = append(candidates, path)
+
+	// 2. Strip extension (e.g. import "./foo" -> /path/to/foo)
+	ext := filepath.Ext(path)
+	if ext != "" {
+		candidates = append(candidates, strings.TrimSuffix(path, ext))
+	}
+
+	// 3. ESM imports of the compiled name (e.g. import "./foo.js" -> /path/to/foo.ts)
+	for jsExt, sources := range esmExtensions {
+		for _, source := range sources {
+			if ext == source {
+				candidates = append(candidates, strings.TrimSuffix(path, ext)+jsExt)
+			}
+		}
+	}
+
+	// 4. Index files (e.g. import "./foo" -> /path/to/foo/index.ts -> /path/to/foo)
+	// We check if the file is an index file and add the parent directory as a candidate.
+	name := filepath.Base(path)
+	nameNoExt := strings.TrimSuffix(name, ext)
+	if nameNoExt == "index" {
+		candidates = append(candidates, filepath.Dir(path))
+	}
+	return candidates
+}
+
+// Files returns the sorted paths of every file parsed into the graph.
+func (g *Graph) Files() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	files := make([]string, 0, len(g.Forward))
+	for path := range g.Forward {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
 // GetAffectedDependents returns files that transitively depend on path, but stops
 // BFS propagation along any edge where the dependent mocks the dependency
 // (i.e. depType == DepMocked). This prevents false-positive test queuing:
@@ -167,40 +364,94 @@ func (g *Graph) Update(path string) {
 //	mocked.test.ts is excluded because it mocks middle.ts, insulating itself
 //	from changes in leaf.ts.
 func (g *Graph) GetAffectedDependents(path string) map[string]struct{} {
+	return g.Explain(path).Dependents()
+}
+
+// Hop is one import in a chain: File imports Dependency.
+type Hop struct {
+	File       string
+	Dependency string
+	Kind       ImportKind
+}
+
+// Explanation tells why files are affected by a change to Changed: the
+// shortest import chain that reaches each one, and the mocked imports that
+// kept the change from spreading further.
+type Explanation struct {
+	Changed string
+	Pruned  []Hop // Mocked imports the search stopped at, sorted by file
+
+	via map[string]Hop // Affected file -> the import it was reached through
+}
+
+// Explain runs the search behind GetAffectedDependents, recording how each
+// dependent was reached. Dependents are visited in path order, so the chain
+// chosen among equally short ones is stable.
+func (g *Graph) Explain(path string) *Explanation {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
-	visited := make(map[string]bool)
-	dependents := make(map[string]struct{})
+	x := &Explanation{Changed: path, via: make(map[string]Hop)}
+	visited := map[string]bool{path: true}
 
 	queue := []string{path}
-	visited[path] = true
-
 	for len(queue) > 0 {
 		current := queue[0]
 		queue = queue[1:]
 
-		if deps, ok := g.Reverse[current]; ok {
-			for dep, depType := range deps {
-				if !visited[dep] {
-					visited[dep] = true
-
-					if depType == DepMocked {
-						// The dependent mocks 'current': it is insulated from upstream
-						// changes. Exclude it from results and stop traversal here.
-						continue
-					}
+		deps := make([]string, 0, len(g.Reverse[current]))
+		for dep := range g.Reverse[current] {
+			deps = append(deps, dep)
+		}
+		sort.Strings(deps)
 
-					dependents[dep] = struct{}{}
-					queue = append(queue, dep)
-				}
+		for _, dep := range deps {
+			if visited[dep] {
+				continue
 			}
+			visited[dep] = true
+
+			hop := Hop{File: dep, Dependency: current, Kind: g.kinds[dep][current]}
+			if g.Reverse[current][dep] == DepMocked {
+				// The dependent mocks 'current': it is insulated from upstream
+				// changes. Exclude it from results and stop traversal here.
+				x.Pruned = append(x.Pruned, hop)
+				continue
+			}
+
+			x.via[dep] = hop
+			queue = append(queue, dep)
 		}
 	}
 
+	sort.Slice(x.Pruned, func(i, j int) bool { return x.Pruned[i].File < x.Pruned[j].File })
+	return x
+}
+
+// Dependents returns the affected files.
+func (x *Explanation) Dependents() map[string]struct{} {
+	dependents := make(map[string]struct{}, len(x.via))
+	for file := range x.via {
+		dependents[file] = struct{}{}
+	}
 	return dependents
 }
 
+// Chain returns the imports that lead from Changed to file, starting with
+// the one of Changed. ok is false if file is not affected.
+func (x *Explanation) Chain(file string) (chain []Hop, ok bool) {
+	for file != x.Changed {
+		hop, found := x.via[file]
+		if !found {
+			return nil, false
+		}
+		chain = append(chain, hop)
+		file = hop.Dependency
+	}
+	slices.Reverse(chain)
+	return chain, true
+}
+
 // Internal helpers
 
 func (g *Graph) addPendingImport(importPath, dependent string, depType DependencyType) {
@@ -0,0 +1,345 @@
+package analysis
+
+// ImportKind is the form that names a module in a source file.
+type ImportKind int
+
+const (
+	KindImport     ImportKind = iota // import x from 'a', import 'a'
+	KindReExport                     // export { x } from 'a'
+	KindDynamic                      // import('a')
+	KindRequire                      // require('a')
+	KindMock                         // jest.mock('a'), vi.mock('a', factory)
+	KindActual                       // jest.requireActual('a'), vi.importActual('a')
+	KindManualMock                   // The __mocks__ file that stands in for a mocked module
+)
+
+func (k ImportKind) String() string {
+	switch k {
+	case KindReExport:
+		return "re-export"
+	case KindDynamic:
+		return "dynamic import"
+	case KindRequire:
+		return "require"
+	case KindMock:
+		return "mock"
+	case KindActual:
+		return "actual import"
+	case KindManualMock:
+		return "manual mock"
+	}
+	return "import"
+}
+
+// importSpec is a module specifier found in a source file.
+type importSpec struct {
+	path   string
+	kind   ImportKind
+	mocked bool // Replaced by a mock (jest.mock, vi.mock) that does not use the real module
+	manual bool // Mocked without a factory, so a __mocks__ file stands in for it
+}
+
+// mockMethods lists, per mocking object, the methods whose first argument is
+// a mocked module.
+var mockMethods = map[string][]string{
+	"jest": {"mock", "doMock", "setMock"},
+	"vi":   {"mock", "doMock"},
+}
+
+// actualMethods load the real module from inside a mock factory:
+// jest.requireActual and vi.importActual.
+var actualMethods = []string{"requireActual", "importActual"}
+
+// importOriginal is the name Vitest gives the factory argument that loads the
+// real module: vi.mock('./a', async (importOriginal) => ...).
+const importOriginal = "importOriginal"
+
+// openMock is a mock call whose factory is being read.
+type openMock struct {
+	spec  int // Index in specs
+	depth int // Paren depth outside the call
+}
+
+// importScanner walks a file's tokens looking for the forms that name a
+// module:
+//
+//	import x, { y } from 'a'     export { x } from 'a'
+//	import 'a'                   export * as ns from 'a'
+//	import('a')                  require('a')
+//	jest.mock('a')               vi.mock('a', factory)
+//	jest.requireActual('a')      vi.importActual('a')
+//
+// A mock whose factory loads the module it mocks (a partial mock) still runs
+// the real code, so it is recorded as a plain import.
+type importScanner struct {
+	lx      *lexer
+	prev    token // Token before the last one returned by next
+	cur     token // Last token returned by next
+	back    token // Token pushed back by unread, if hasBack
+	hasBack bool
+	depth   int        // Open parens
+	mocks   []openMock // Mock calls enclosing the current token
+	specs   []importSpec
+}
+
+// scanImports returns the module specifiers in src, in source order. jsx
+// enables JSX parsing, which plain TypeScript files must not use because
+// their type assertions (<T>x) look like tags.
+func scanImports(src []byte, jsx bool) []importSpec {
+	s := &importScanner{lx: newLexer(src, jsx)}
+	for {
+		tok := s.next()
+		if tok.kind == tokEOF {
+			return s.specs
+		}
+		if tok.kind != tokIdent {
+			continue
+		}
+		if s.prev.is(".") {
+			if isActualMethod(tok.text) {
+				s.actualForm()
+			}
+			continue
+		}
+		if s.prev.is("?.") {
+			continue
+		}
+		switch string(tok.text) {
+		case "import":
+			s.importForm()
+		case "export":
+			s.exportForm()
+		case "require":
+			s.callForm(KindRequire)
+		case importOriginal:
+			// Only a call counts, not the factory's parameter list.
+			call := s.next()
+			if len(s.mocks) > 0 && (call.is("(") || call.is("<")) {
+				s.partial()
+			}
+			s.unread(call)
+		default:
+			if methods, ok := mockMethods[string(tok.text)]; ok {
+				s.mockForm(methods)
+			}
+		}
+	}
+}
+
+func (s *importScanner) next() token {
+	var tok token
+	if s.hasBack {
+		tok, s.hasBack = s.back, false
+	} else {
+		tok = s.lx.next()
+		s.trackParens(tok)
+	}
+	s.prev, s.cur = s.cur, tok
+	return tok
+}
+
+// trackParens counts parens as tokens are lexed, closing mock calls as their
+// closing paren goes by.
+func (s *importScanner) trackParens(tok token) {
+	switch {
+	case tok.is("("):
+		s.depth++
+	case tok.is(")"):
+		s.depth--
+		for len(s.mocks) > 0 && s.mocks[len(s.mocks)-1].depth >= s.depth {
+			s.mocks = s.mocks[:len(s.mocks)-1]
+		}
+	}
+}
+
+// unread makes next return the last token again, so a token that ended a
+// failed match can start the next one.
+func (s *importScanner) unread(tok token) {
+	s.back, s.hasBack = tok, true
+	s.cur = s.prev
+}
+
+func (s *importScanner) add(path []byte, kind ImportKind) {
+	if len(path) > 0 {
+		s.specs = append(s.specs, importSpec{path: string(path), kind: kind})
+	}
+}
+
+// partial marks the innermost open mock as using the real module.
+func (s *importScanner) partial() {
+	spec := &s.specs[s.mocks[len(s.mocks)-1].spec]
+	spec.mocked = false
+}
+
+// importForm handles the tokens after "import".
+func (s *importScanner) importForm() {
+	tok := s.next()
+	switch {
+	case tok.kind == tokString:
+		s.add(tok.text, KindImport)
+	case tok.is("("):
+		s.callForm(KindDynamic)
+	default:
+		s.unread(tok)
+		s.fromClause(KindImport)
+	}
+}
+
+// exportForm handles the tokens after "export"; only re-exports name a
+// module.
+func (s *importScanner) exportForm() {
+	tok := s.next()
+	if tok.is("type") {
+		tok = s.next()
+	}
+	s.unread(tok)
+	if tok.is("*") || tok.is("{") {
+		s.fromClause(KindReExport)
+	}
+}
+
+// fromClause reads an import or export clause up to "from 'a'". It gives up
+// at the first token that cannot be part of a clause, so "import" in other
+// positions (import.meta, import x = require('a')) costs nothing.
+func (s *importScanner) fromClause(kind ImportKind) {
+	braces := 0
+	for {
+		tok := s.next()
+		switch {
+		case tok.is("from") && braces == 0:
+			if str := s.next(); str.kind == tokString {
+				s.add(str.text, kind)
+			} else {
+				s.unread(str)
+			}
+			return
+		case tok.is("{"):
+			braces++
+		case tok.is("}"):
+			braces--
+		case tok.kind == tokIdent, tok.is(","), tok.is("*"):
+		case tok.kind == tokString && braces > 0:
+			// Arbitrary module namespace names: export { x as "y" }
+		default:
+			s.unread(tok)
+			return
+		}
+	}
+}
+
+// callForm reads "('a')" after require, or "'a')" after "import(" for a
+// dynamic import, which also allows import attributes after the specifier.
+func (s *importScanner) callForm(kind ImportKind) {
+	dynamic := kind == KindDynamic
+	if !dynamic {
+		if tok := s.next(); !tok.is("(") {
+			s.unread(tok)
+			return
+		}
+	}
+	str := s.next()
+	if str.kind != tokString {
+		s.unread(str)
+		return
+	}
+	end := s.next()
+	if end.is(")") || dynamic && end.is(",") {
+		s.add(str.text, kind)
+	}
+	s.unread(end)
+}
+
+// mockForm reads ".mock('a', factory)" after a mocking object such as jest
+// or vi. Vitest also accepts import('a') as the module.
+func (s *importScanner) mockForm(methods []string) {
+	if dot := s.next(); !dot.is(".") {
+		s.unread(dot)
+		return
+	}
+	method := s.next()
+	if method.kind != tokIdent || !contains(methods, method.text) {
+		s.unread(method)
+		return
+	}
+	if open := s.next(); !open.is("(") {
+		s.unread(open)
+		return
+	}
+	depth := s.depth - 1
+
+	str := s.next()
+	if str.is("import") {
+		if open := s.next(); !open.is("(") {
+			s.unread(open)
+			return
+		}
+		str = s.next()
+		if end := s.next(); !end.is(")") {
+			s.unread(end)
+			return
+		}
+	}
+	if str.kind != tokString || len(str.text) == 0 {
+		s.unread(str)
+		return
+	}
+
+	spec := importSpec{path: string(str.text), kind: KindMock, mocked: true}
+	sep := s.next()
+	switch {
+	case sep.is(")"):
+		spec.manual = true
+	case sep.is(","):
+		s.mocks = append(s.mocks, openMock{spec: len(s.specs), depth: depth})
+	}
+	s.specs = append(s.specs, spec)
+	s.unread(sep)
+}
+
+// actualForm reads "('a')" after requireActual or importActual, allowing a
+// type argument in between. Inside the factory of a mock of the same module,
+// it makes that mock partial.
+func (s *importScanner) actualForm() {
+	open := s.next()
+	if open.is("<") {
+		// Skip the type argument: <typeof import('./a')>
+		for angles := 1; angles > 0; {
+			tok := s.next()
+			switch {
+			case tok.kind == tokEOF:
+				return
+			case tok.is("<"):
+				angles++
+			case tok.is(">"):
+				angles--
+			}
+		}
+		open = s.next()
+	}
+	if !open.is("(") {
+		s.unread(open)
+		return
+	}
+	str := s.next()
+	if str.kind != tokString {
+		s.unread(str)
+		return
+	}
+	s.add(str.text, KindActual)
+	if n := len(s.mocks); n > 0 && s.specs[s.mocks[n-1].spec].path == string(str.text) {
+		s.partial()
+	}
+}
+
+func isActualMethod(name []byte) bool {
+	return contains(actualMethods, name)
+}
+
+func contains(list []string, name []byte) bool {
+	for _, s := range list {
+		if string(name) == s {
+			return true
+		}
+	}
+	return false
+}
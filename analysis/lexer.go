@@ -0,0 +1,465 @@
+package analysis
+
+// The lexer below tokenizes JavaScript and TypeScript just well enough to
+// find module specifiers: it skips comments, reads string, template and
+// regex literals as single tokens, and steps over JSX markup, so text that
+// only looks like an import is never mistaken for one.
+
+// tokenKind classifies a token.
+type tokenKind uint8
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString   // '...', "..." or a template without substitutions; text is the content
+	tokTemplate // Part of a template with substitutions
+	tokNumber
+	tokRegex
+	tokJSX // A whole JSX element, outside its {expressions}
+	tokPunct
+)
+
+// token is a slice of the source; text excludes a string's quotes.
+type token struct {
+	kind tokenKind
+	text []byte
+}
+
+// is reports whether t is the identifier or punctuator s.
+func (t token) is(s string) bool {
+	return (t.kind == tokIdent || t.kind == tokPunct) && string(t.text) == s
+}
+
+// frameKind says what a '{' returns to once its '}' is reached.
+type frameKind uint8
+
+const (
+	frameBlock    frameKind = iota // A block or object literal
+	frameTemplate                  // ${ in a template literal
+	frameJSXAttr                   // {expression} as a JSX attribute
+	frameJSXChild                  // {expression} between JSX tags
+)
+
+// frame is an open '{'. For JSX frames, depth is the number of open elements
+// to resume with.
+type frame struct {
+	kind  frameKind
+	depth int
+}
+
+// lexer produces the tokens of a JS/TS source file.
+type lexer struct {
+	src    []byte
+	pos    int
+	jsx    bool // Whether '<' can start a JSX element (false for .ts files)
+	frames []frame
+	prev   token // Last token, to tell a regex from a division and JSX from less-than
+}
+
+func newLexer(src []byte, jsx bool) *lexer {
+	lx := &lexer{src: src, jsx: jsx}
+	if len(src) > 1 && src[0] == '#' && src[1] == '!' {
+		lx.skipLine()
+	}
+	return lx
+}
+
+// next returns the next token, or a tokEOF token at the end of the source.
+func (lx *lexer) next() token {
+	tok := lx.scan()
+	lx.prev = tok
+	return tok
+}
+
+func (lx *lexer) scan() token {
+	for {
+		lx.skipSpaceAndComments()
+		if lx.pos >= len(lx.src) {
+			return token{kind: tokEOF}
+		}
+		start := lx.pos
+		c := lx.src[lx.pos]
+		switch {
+		case isIdentStart(c):
+			lx.pos++
+			for lx.pos < len(lx.src) && isIdentPart(lx.src[lx.pos]) {
+				lx.pos++
+			}
+			return token{kind: tokIdent, text: lx.src[start:lx.pos]}
+		case isDigit(c) || c == '.' && lx.pos+1 < len(lx.src) && isDigit(lx.src[lx.pos+1]):
+			for lx.pos < len(lx.src) && (isIdentPart(lx.src[lx.pos]) || lx.src[lx.pos] == '.') {
+				lx.pos++
+			}
+			return token{kind: tokNumber, text: lx.src[start:lx.pos]}
+		case c == '\'' || c == '"':
+			return lx.scanString(c)
+		case c == '`':
+			lx.pos++
+			return lx.scanTemplate()
+		case c == '#':
+			// Private field: lexes like an identifier.
+			lx.pos++
+			for lx.pos < len(lx.src) && isIdentPart(lx.src[lx.pos]) {
+				lx.pos++
+			}
+			return token{kind: tokIdent, text: lx.src[start:lx.pos]}
+		case c == '{':
+			lx.pos++
+			lx.frames = append(lx.frames, frame{kind: frameBlock})
+			return token{kind: tokPunct, text: lx.src[start:lx.pos]}
+		case c == '}':
+			lx.pos++
+			if len(lx.frames) == 0 {
+				return token{kind: tokPunct, text: lx.src[start:lx.pos]}
+			}
+			f := lx.frames[len(lx.frames)-1]
+			lx.frames = lx.frames[:len(lx.frames)-1]
+			switch f.kind {
+			case frameTemplate:
+				return lx.scanTemplate()
+			case frameJSXAttr, frameJSXChild:
+				if tok, ok := lx.scanJSX(f.kind == frameJSXAttr, f.depth); ok {
+					return tok
+				}
+				// Stopped at another {expression}: lex it as code.
+				continue
+			}
+			return token{kind: tokPunct, text: lx.src[start:lx.pos]}
+		case c == '/' && lx.expressionStart():
+			return lx.scanRegex()
+		case c == '<' && lx.jsx && lx.expressionStart() && lx.pos+1 < len(lx.src) &&
+			(isIdentStart(lx.src[lx.pos+1]) || lx.src[lx.pos+1] == '>') && !lx.typeParamsAhead():
+			if tok, ok := lx.scanJSX(true, 0); ok {
+				return tok
+			}
+			continue
+		case c == '?' && lx.pos+1 < len(lx.src) && lx.src[lx.pos+1] == '.' &&
+			!(lx.pos+2 < len(lx.src) && isDigit(lx.src[lx.pos+2])):
+			lx.pos += 2
+			return token{kind: tokPunct, text: lx.src[start:lx.pos]}
+		default:
+			lx.pos++
+			return token{kind: tokPunct, text: lx.src[start:lx.pos]}
+		}
+	}
+}
+
+// typeParamsAhead reports whether the '<' at the current position opens the
+// type parameters of a generic arrow function, such as "<T,>(x: T) => x" or
+// "<T extends unknown>(x: T) => x", which TSX allows where JSX could start:
+// an identifier followed by ',', "extends" or '>', then a parenthesized
+// parameter list and "=>", optionally after a return type.
+func (lx *lexer) typeParamsAhead() bool {
+	i := lx.skipSpace(lx.pos + 1)
+	if i >= len(lx.src) || !isIdentStart(lx.src[i]) {
+		return false
+	}
+	for i < len(lx.src) && isIdentPart(lx.src[i]) {
+		i++
+	}
+	i = lx.skipSpace(i)
+	switch {
+	case i < len(lx.src) && (lx.src[i] == ',' || lx.src[i] == '>'):
+	case i+len("extends") < len(lx.src) && string(lx.src[i:i+len("extends")]) == "extends" &&
+		!isIdentPart(lx.src[i+len("extends")]):
+	default:
+		return false
+	}
+
+	i = lx.matching(lx.pos, '<', '>')
+	if i < 0 {
+		return false
+	}
+	i = lx.skipSpace(i + 1)
+	if i >= len(lx.src) || lx.src[i] != '(' {
+		return false
+	}
+	i = lx.matching(i, '(', ')')
+	if i < 0 {
+		return false
+	}
+	i = lx.skipSpace(i + 1)
+	if i < len(lx.src) && lx.src[i] == ':' {
+		// A return type: the arrow follows it on the same statement.
+		for i < len(lx.src) && lx.src[i] != ';' && lx.src[i] != '{' && string(lx.src[i:min(i+2, len(lx.src))]) != "=>" {
+			i++
+		}
+	}
+	return i+1 < len(lx.src) && lx.src[i] == '=' && lx.src[i+1] == '>'
+}
+
+// matching returns the index of the close bracket that balances the open
+// bracket at i, or -1 if there is none. An arrow's '>' does not close an
+// angle bracket.
+func (lx *lexer) matching(i int, open, close byte) int {
+	depth := 0
+	for ; i < len(lx.src); i++ {
+		switch c := lx.src[i]; {
+		case c == open:
+			depth++
+		case c == close && !(close == '>' && i > 0 && lx.src[i-1] == '='):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case c == '"' || c == '\'' || c == '`':
+			end := indexFrom(lx.src, i+1, string(c))
+			if end < 0 {
+				return -1
+			}
+			i = end
+		}
+	}
+	return -1
+}
+
+// skipSpace returns the index of the first non-whitespace byte at or after i.
+func (lx *lexer) skipSpace(i int) int {
+	for i < len(lx.src) {
+		switch lx.src[i] {
+		case ' ', '\t', '\n', '\r':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// expressionStart reports whether the previous token leaves the lexer where
+// an expression can begin, so '/' starts a regex and '<' a JSX element.
+func (lx *lexer) expressionStart() bool {
+	switch lx.prev.kind {
+	case tokEOF:
+		return true
+	case tokNumber, tokString, tokTemplate, tokRegex, tokJSX:
+		return false
+	case tokIdent:
+		return isOperatorKeyword(lx.prev.text)
+	}
+	switch lx.prev.text[len(lx.prev.text)-1] {
+	case ')', ']', '}':
+		return false
+	}
+	return true
+}
+
+// operatorKeywords are the keywords after which an expression begins.
+var operatorKeywords = []string{
+	"return", "typeof", "instanceof", "in", "of", "new", "delete", "void",
+	"throw", "case", "do", "else", "yield", "await",
+}
+
+func isOperatorKeyword(word []byte) bool {
+	for _, kw := range operatorKeywords {
+		if string(word) == kw {
+			return true
+		}
+	}
+	return false
+}
+
+func (lx *lexer) skipSpaceAndComments() {
+	for lx.pos < len(lx.src) {
+		c := lx.src[lx.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			lx.pos++
+		case c == '/' && lx.pos+1 < len(lx.src) && lx.src[lx.pos+1] == '/':
+			lx.skipLine()
+		case c == '/' && lx.pos+1 < len(lx.src) && lx.src[lx.pos+1] == '*':
+			end := indexFrom(lx.src, lx.pos+2, "*/")
+			if end < 0 {
+				lx.pos = len(lx.src)
+			} else {
+				lx.pos = end + 2
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (lx *lexer) skipLine() {
+	for lx.pos < len(lx.src) && lx.src[lx.pos] != '\n' {
+		lx.pos++
+	}
+}
+
+// scanString reads a quoted string. An unterminated string ends at the line
+// break so one stray quote cannot swallow the rest of the file.
+func (lx *lexer) scanString(quote byte) token {
+	lx.pos++
+	start := lx.pos
+	for lx.pos < len(lx.src) {
+		switch lx.src[lx.pos] {
+		case quote:
+			text := lx.src[start:lx.pos]
+			lx.pos++
+			return token{kind: tokString, text: text}
+		case '\\':
+			lx.pos += 2
+			continue
+		case '\n':
+			return token{kind: tokString, text: lx.src[start:lx.pos]}
+		}
+		lx.pos++
+	}
+	lx.pos = len(lx.src)
+	return token{kind: tokString, text: lx.src[start:]}
+}
+
+// scanTemplate reads a template literal from just after its opening backtick
+// or the '}' closing a substitution, up to the closing backtick or the next
+// '${'. A template without substitutions is returned as a tokString.
+func (lx *lexer) scanTemplate() token {
+	start := lx.pos
+	whole := lx.pos > 0 && lx.src[lx.pos-1] == '`'
+	for lx.pos < len(lx.src) {
+		switch lx.src[lx.pos] {
+		case '`':
+			text := lx.src[start:lx.pos]
+			lx.pos++
+			if whole {
+				return token{kind: tokString, text: text}
+			}
+			return token{kind: tokTemplate, text: text}
+		case '\\':
+			lx.pos += 2
+			continue
+		case '$':
+			if lx.pos+1 < len(lx.src) && lx.src[lx.pos+1] == '{' {
+				lx.pos += 2
+				lx.frames = append(lx.frames, frame{kind: frameTemplate})
+				// A head is followed by an expression: report its "${" as
+				// punctuation so a leading '/' is read as a regex.
+				return token{kind: tokPunct, text: lx.src[lx.pos-2 : lx.pos]}
+			}
+		}
+		lx.pos++
+	}
+	lx.pos = len(lx.src)
+	return token{kind: tokTemplate, text: lx.src[start:]}
+}
+
+// scanRegex reads a regex literal including its flags. It stops at a line
+// break, which a regex cannot contain.
+func (lx *lexer) scanRegex() token {
+	start := lx.pos
+	lx.pos++
+	inClass := false
+	for lx.pos < len(lx.src) {
+		c := lx.src[lx.pos]
+		switch {
+		case c == '\\':
+			lx.pos += 2
+			continue
+		case c == '\n':
+			return token{kind: tokRegex, text: lx.src[start:lx.pos]}
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '/' && !inClass:
+			lx.pos++
+			for lx.pos < len(lx.src) && isIdentPart(lx.src[lx.pos]) {
+				lx.pos++
+			}
+			return token{kind: tokRegex, text: lx.src[start:lx.pos]}
+		}
+		lx.pos++
+	}
+	lx.pos = min(lx.pos, len(lx.src))
+	return token{kind: tokRegex, text: lx.src[start:]}
+}
+
+// scanJSX skips JSX markup: inside a tag when inTag, otherwise between tags,
+// with depth elements open. It returns a tokJSX once the outermost element
+// closes. At a '{' it pushes a frame to resume from and returns false so the
+// expression is lexed as code.
+func (lx *lexer) scanJSX(inTag bool, depth int) (token, bool) {
+	start := lx.pos
+	for lx.pos < len(lx.src) {
+		c := lx.src[lx.pos]
+		if inTag {
+			switch {
+			case c == '<' && lx.pos+1 < len(lx.src) && lx.src[lx.pos+1] == '/':
+				// Closing tag: skip its name.
+				end := indexFrom(lx.src, lx.pos, ">")
+				if end < 0 {
+					lx.pos = len(lx.src)
+					continue
+				}
+				lx.pos = end + 1
+				depth--
+				inTag = false
+				if depth <= 0 {
+					return token{kind: tokJSX, text: lx.src[start:lx.pos]}, true
+				}
+				continue
+			case c == '<':
+				depth++
+			case c == '/' && lx.pos+1 < len(lx.src) && lx.src[lx.pos+1] == '>':
+				lx.pos += 2
+				depth--
+				inTag = false
+				if depth <= 0 {
+					return token{kind: tokJSX, text: lx.src[start:lx.pos]}, true
+				}
+				continue
+			case c == '>':
+				inTag = false
+			case c == '"' || c == '\'':
+				end := indexFrom(lx.src, lx.pos+1, string(c))
+				if end < 0 {
+					lx.pos = len(lx.src)
+					continue
+				}
+				lx.pos = end
+			case c == '{':
+				lx.pos++
+				lx.frames = append(lx.frames, frame{kind: frameJSXAttr, depth: depth})
+				lx.prev = token{kind: tokPunct, text: lx.src[lx.pos-1 : lx.pos]}
+				return token{}, false
+			}
+			lx.pos++
+			continue
+		}
+
+		switch c {
+		case '<':
+			// An opening or closing tag; closing tags are handled in tag mode.
+			inTag = true
+			continue
+		case '{':
+			lx.pos++
+			lx.frames = append(lx.frames, frame{kind: frameJSXChild, depth: depth})
+			lx.prev = token{kind: tokPunct, text: lx.src[lx.pos-1 : lx.pos]}
+			return token{}, false
+		}
+		lx.pos++
+	}
+	return token{kind: tokJSX, text: lx.src[start:]}, true
+}
+
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$' || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// indexFrom returns the index of sep in src at or after from, or -1.
+func indexFrom(src []byte, from int, sep string) int {
+	for i := from; i+len(sep) <= len(src); i++ {
+		if src[i] == sep[0] && string(src[i:i+len(sep)]) == sep {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,240 @@
+package analysis
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func specPaths(specs []importSpec) []string {
+	var paths []string
+	for _, spec := range specs {
+		path := spec.path
+		if spec.mocked {
+			path += " (mocked)"
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestScanImports(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"import forms", `
+import a from './a';
+import * as b from "./b";
+import c, { d as e, type F } from './c';
+import './side-effect';
+import type { G } from './types';
+import {
+  h,
+  i,
+} from './multi';`, []string{"./a", "./b", "./c", "./side-effect", "./types", "./multi"}},
+		{"export forms", `
+export { a } from './a';
+export * from './b';
+export * as ns from './c';
+export type { T } from './d';
+export { x as "string name" } from './e';
+export const local = 1;
+export default function () {}`, []string{"./a", "./b", "./c", "./d", "./e"}},
+		{"require and dynamic import", `
+const a = require('./a');
+const b = await import('./b');
+const c = import("./c", { with: { type: "json" } });
+const d = import(` + "`./d`" + `);
+import e = require('./e');`, []string{"./a", "./b", "./c", "./d", "./e"}},
+		{"jest mocks", `
+jest.mock('./a');
+jest.doMock("./b", () => ({}));
+jest.setMock('./c', {});
+jest.fn('./not-a-module');`, []string{"./a (mocked)", "./b (mocked)", "./c (mocked)"}},
+		{"vitest mocks", `
+vi.mock('./a');
+vi.doMock("./b", () => ({ b: vi.fn() }));
+vi.mock(import('./c'), () => ({}));
+vi.fn('./not-a-module');`, []string{"./a (mocked)", "./b (mocked)", "./c (mocked)"}},
+		{"partial mocks", `
+jest.mock('./a', () => ({ ...jest.requireActual('./a'), a: jest.fn() }));
+vi.mock('./b', async () => {
+  const actual = await vi.importActual<typeof import('./b')>('./b');
+  return { ...actual, b: vi.fn() };
+});
+vi.mock('./c', async (importOriginal) => ({ ...(await importOriginal()), c: 1 }));
+vi.mock('./d', async (importOriginal) => ({ d: 1 }));
+jest.mock('./e', () => ({ e: jest.requireActual('./other').e }));
+const real = jest.requireActual('./f');`, []string{"./a", "./a", "./b", "./b", "./c", "./d (mocked)", "./e (mocked)", "./other", "./f"}},
+		{"comments", `
+// import a from './line-comment';
+/* import b from './block-comment';
+   require('./block-comment-2'); */
+/**
+ * @example import c from './doc-comment'
+ */
+import real from './real';`, []string{"./real"}},
+		{"strings and templates", `
+const s = "import a from './in-string'";
+const t = 'require("./in-string-2")';
+const u = ` + "`import b from './in-template' ${require('./in-substitution')} import('./tail')`" + `;
+import real from './real';`, []string{"./in-substitution", "./real"}},
+		{"import far from from", `
+import { a } from './a';
+const imported = true;
+const data = { from: './not-a-module' };
+function load(from) { return from; }`, []string{"./a"}},
+		{"member access", `
+obj.require('./a');
+loader.import('./b');
+const meta = import.meta.url;
+maybe?.require('./c');`, nil},
+		{"regex literals", `
+const re = /import a from '.\/in-regex'/;
+const quote = /['"]/g;
+const cls = /[/]import('x')/;
+const ratio = total / count / 2;
+import real from './real';`, []string{"./real"}},
+		{"regex after keyword", `
+function f(s) { return /'/.test(s); }
+import real from './real';`, []string{"./real"}},
+		{"jsx text", `
+import React from 'react';
+const el = <p className="note">Don't import x from './in-jsx' // here</p>;
+const frag = <>it's {require('./in-expression')} <b>ok</b></>;
+const nested = <A render={() => <B title='a "b"' />}>{items.map(i => <C key={i} />)}</A>;
+import after from './after';`, []string{"react", "./in-expression", "./after"}},
+		{"tsx generic arrow with comma", `
+const id = <T,>(x: T) => x;
+import after from './after';`, []string{"./after"}},
+		{"tsx generic arrow with extends", `
+const id = <T extends unknown>(x: T): T => x;
+import after from './after';`, []string{"./after"}},
+		{"tsx generic arrow before dynamic import and mock", `
+const id = <T,>(x: T) => x;
+import('./later');
+jest.mock('./mocked');`, []string{"./later", "./mocked (mocked)"}},
+		{"shebang", "#!/usr/bin/env node\nconst a = require('./a');", []string{"./a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := specPaths(scanImports([]byte(tt.src), true))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanImports_ManualMocks(t *testing.T) {
+	specs := scanImports([]byte(`
+jest.mock('./auto');
+jest.mock('./factory', () => ({}));
+vi.mock('axios');`), false)
+	var manual []string
+	for _, spec := range specs {
+		if spec.manual {
+			manual = append(manual, spec.path)
+		}
+	}
+	if want := []string{"./auto", "axios"}; !reflect.DeepEqual(manual, want) {
+		t.Errorf("manual mocks = %q, want %q", manual, want)
+	}
+}
+
+func TestScanImports_Kinds(t *testing.T) {
+	specs := scanImports([]byte(`
+import a from './a';
+export * from './b';
+const c = await import('./c');
+const d = require('./d');
+jest.mock('./e', () => ({ ...jest.requireActual('./f') }));`), false)
+	var got []string
+	for _, spec := range specs {
+		got = append(got, spec.path+" "+spec.kind.String())
+	}
+	want := []string{"./a import", "./b re-export", "./c dynamic import", "./d require", "./e mock", "./f actual import"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestScanImports_TypeAssertionWithoutJSX(t *testing.T) {
+	src := `
+const a = <Config>JSON.parse(raw);
+const b = <string>value, c = 'it\'s';
+import real from './real';`
+	got := specPaths(scanImports([]byte(src), false))
+	if want := []string{"./real"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// The regexes ParseImports used before the lexer, kept as the benchmark
+// baseline.
+var (
+	benchImportFrom   = regexp.MustCompile(`import[\s\S]*?from\s+['"]([^'"]+)['"]`)
+	benchSideEffect   = regexp.MustCompile(`import\s+['"]([^'"]+)['"]`)
+	benchRequire      = regexp.MustCompile(`require\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+	benchJestMock     = regexp.MustCompile(`jest\.(?:mock|doMock|setMock)\s*\(\s*['"]([^'"]+)['"]`)
+	benchDynamic      = regexp.MustCompile(`import\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+	benchExportFrom   = regexp.MustCompile(`export[\s\S]*?from\s+['"]([^'"]+)['"]`)
+	benchImportRegexs = []*regexp.Regexp{benchImportFrom, benchSideEffect, benchRequire, benchJestMock, benchDynamic, benchExportFrom}
+)
+
+func regexImports(text string) int {
+	n := 0
+	for _, re := range benchImportRegexs {
+		n += len(re.FindAllStringSubmatch(text, -1))
+	}
+	return n
+}
+
+// benchSource returns a ~1 MB component file: imports at the top, then
+// functions with JSX, templates, regexes and comments.
+func benchSource() []byte {
+	var b strings.Builder
+	for i := range 40 {
+		b.WriteString("import { helper" + string(rune('a'+i%26)) + " } from './helpers';\n")
+	}
+	b.WriteString("export * from './shared';\nconst lazy = () => import('./lazy');\njest.mock('./api');\n")
+	body := `
+/**
+ * Renders a row. Exported for the table, which imports it from here.
+ */
+export function Row({ item, onSelect }) {
+  const label = ` + "`${item.name} (${item.count})`" + `;
+  const valid = /^[a-z][\w-]*$/i.test(item.id) && item.total / item.count > 0.5;
+  // Keep the same reference between renders.
+  return (
+    <li className="row" onClick={() => onSelect(item.id)}>
+      <span title={label}>{label}</span>
+      {valid ? <Badge kind="ok" /> : <Badge kind="error">Invalid</Badge>}
+    </li>
+  );
+}
+`
+	for b.Len() < 1<<20 {
+		b.WriteString(body)
+	}
+	return []byte(b.String())
+}
+
+func BenchmarkScanImports(b *testing.B) {
+	src := benchSource()
+	b.Run("lexer", func(b *testing.B) {
+		b.SetBytes(int64(len(src)))
+		for b.Loop() {
+			scanImports(src, true)
+		}
+	})
+	b.Run("regex", func(b *testing.B) {
+		b.SetBytes(int64(len(src)))
+		for b.Loop() {
+			regexImports(string(src))
+		}
+	})
+}
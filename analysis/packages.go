@@ -0,0 +1,291 @@
+package analysis
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Package is a workspace package that bare specifiers can import by name.
+type Package struct {
+	Name string // Name from package.json, e.g. "@acme/utils"
+	Root string // Absolute path to the package directory
+}
+
+// packageManifest mirrors the package.json fields used to find the file a
+// package import loads.
+type packageManifest struct {
+	Exports *jsonNode `json:"exports"`
+	Module  string    `json:"module"`
+	Main    string    `json:"main"`
+	Types   string    `json:"types"`
+}
+
+// jsonNode is a JSON value made of strings, arrays and objects whose keys
+// keep their order, which JSON configs rely on: the conditions of
+// package.json "exports", or Jest's moduleNameMapper. For exports, it is a
+// target path, an array of fallbacks, or an object of subpaths or conditions.
+type jsonNode struct {
+	target string
+	list   []*jsonNode
+	keys   []string
+	values []*jsonNode
+}
+
+func (n *jsonNode) UnmarshalJSON(data []byte) error {
+	return n.decode(json.NewDecoder(bytes.NewReader(data)))
+}
+
+func (n *jsonNode) decode(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok := tok.(type) {
+	case string:
+		n.target = tok
+	case json.Delim:
+		for dec.More() {
+			var key json.Token
+			if tok == '{' {
+				if key, err = dec.Token(); err != nil {
+					return err
+				}
+			}
+			child := &jsonNode{}
+			if err := child.decode(dec); err != nil {
+				return err
+			}
+			if tok == '{' {
+				n.keys = append(n.keys, key.(string))
+				n.values = append(n.values, child)
+			} else {
+				n.list = append(n.list, child)
+			}
+		}
+		_, err = dec.Token() // Closing delimiter
+		return err
+	}
+	// null, booleans and numbers export nothing.
+	return nil
+}
+
+// isSubpathMap reports whether the node maps subpaths ("." and "./x") rather
+// than conditions.
+func (n *jsonNode) isSubpathMap() bool {
+	return len(n.keys) > 0 && strings.HasPrefix(n.keys[0], ".")
+}
+
+// exportConditions are the conditions whose targets are followed. Every
+// matching target is a candidate, in the order the package lists them, since
+// the one that leads back to a source file is not always the first.
+var exportConditions = map[string]bool{
+	"source":      true,
+	"types":       true,
+	"import":      true,
+	"module":      true,
+	"require":     true,
+	"node":        true,
+	"development": true,
+	"default":     true,
+}
+
+// buildDirs are output directories that usually mirror a package's src.
+var buildDirs = map[string]bool{"dist": true, "build": true, "lib": true, "out": true}
+
+// declarationExts are the extensions of type declaration files, which have a
+// source file next to them or in src.
+var declarationExts = []string{".d.ts", ".d.mts", ".d.cts"}
+
+// outputExts are the extensions of compiled JavaScript.
+var outputExts = []string{".js", ".mjs", ".cjs"}
+
+// SetPackages makes the parser resolve bare specifiers that name one of pkgs
+// to the package's source files. Call it before parsing.
+func (p *Parser) SetPackages(pkgs []Package) {
+	p.packages = pkgs
+}
+
+// resolvePackage resolves imp, a bare specifier such as "@acme/utils" or
+// "@acme/utils/format", to a source file of a workspace package.
+func (p *Parser) resolvePackage(imp string) (string, bool) {
+	pkg, subpath, ok := p.matchPackage(imp)
+	if !ok {
+		return "", false
+	}
+	manifest := p.loadManifest(pkg.Root)
+
+	var targets []string
+	switch {
+	case manifest != nil && manifest.Exports != nil:
+		node, star, ok := manifest.Exports.subpath(subpath)
+		if !ok {
+			return "", false
+		}
+		targets = node.targets(star)
+	case subpath != ".":
+		targets = []string{subpath}
+	default:
+		if manifest != nil {
+			for _, field := range []string{manifest.Module, manifest.Main, manifest.Types} {
+				if field != "" {
+					targets = append(targets, field)
+				}
+			}
+		}
+		targets = append(targets, "index")
+	}
+
+	for _, target := range targets {
+		if found, ok := p.sourceFile(pkg.Root, target); ok {
+			return found, true
+		}
+	}
+	return "", false
+}
+
+// matchPackage finds the package imp names, preferring the longest name so
+// "@acme/ui-kit" does not match "@acme/ui". subpath is "." for the package
+// itself or "./rest" for a deep import.
+func (p *Parser) matchPackage(imp string) (pkg Package, subpath string, ok bool) {
+	for _, candidate := range p.packages {
+		name := candidate.Name
+		if name == "" || len(name) <= len(pkg.Name) {
+			continue
+		}
+		switch {
+		case imp == name:
+			pkg, subpath, ok = candidate, ".", true
+		case strings.HasPrefix(imp, name+"/"):
+			pkg, subpath, ok = candidate, "."+imp[len(name):], true
+		}
+	}
+	return pkg, subpath, ok
+}
+
+// loadManifest reads the package.json in root, caching the result. It
+// returns nil if the file is missing or invalid.
+func (p *Parser) loadManifest(root string) *packageManifest {
+	if cached, ok := p.manifests.Load(root); ok {
+		return cached.(*packageManifest)
+	}
+	var manifest *packageManifest
+	if data, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil {
+		var m packageManifest
+		if json.Unmarshal(data, &m) == nil {
+			manifest = &m
+		}
+	}
+	p.manifests.Store(root, manifest)
+	return manifest
+}
+
+// subpath returns the exports entry for subpath and the text a wildcard in
+// its targets stands for. An exact key wins over patterns; among patterns the
+// one with the longest prefix before "*" wins.
+func (n *jsonNode) subpath(subpath string) (node *jsonNode, star string, ok bool) {
+	if !n.isSubpathMap() {
+		// Sugar for { ".": n }
+		return n, "", subpath == "."
+	}
+
+	best := -1
+	for i, key := range n.keys {
+		if key == subpath {
+			return n.values[i], "", true
+		}
+		prefix, suffix, found := strings.Cut(key, "*")
+		if !found || len(prefix) <= best {
+			continue
+		}
+		if len(subpath) >= len(prefix)+len(suffix) &&
+			strings.HasPrefix(subpath, prefix) && strings.HasSuffix(subpath, suffix) {
+			node, star, ok = n.values[i], subpath[len(prefix):len(subpath)-len(suffix)], true
+			best = len(prefix)
+		}
+	}
+	return node, star, ok
+}
+
+// targets returns the paths the node leads to under exportConditions, with
+// "*" replaced by star.
+func (n *jsonNode) targets(star string) []string {
+	if n.target != "" {
+		return []string{strings.ReplaceAll(n.target, "*", star)}
+	}
+	var targets []string
+	for _, item := range n.list {
+		targets = append(targets, item.targets(star)...)
+	}
+	for i, key := range n.keys {
+		if exportConditions[key] {
+			targets = append(targets, n.values[i].targets(star)...)
+		}
+	}
+	return targets
+}
+
+// sourceFile maps target, a path relative to the package root that may name
+// a build output or a declaration file, to the source file it comes from:
+//
+//	./src/index.ts          the file itself
+//	./src/index.js          src/index.ts, for TypeScript's ESM imports
+//	./dist/esm/index.js     src/esm/index.ts, then src/index.ts
+//	./dist/index.d.ts       src/index.ts
+//
+// Build outputs are only used when no source file is found.
+func (p *Parser) sourceFile(root, target string) (string, bool) {
+	rel := filepath.Clean(filepath.FromSlash(target))
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	stem := trimOutputExt(rel)
+	parts := strings.Split(stem, string(filepath.Separator))
+	built := buildDirs[parts[0]] && len(parts) > 1
+
+	var candidates []string
+	if !isDeclaration(rel) && !built {
+		candidates = append(candidates, rel)
+	}
+	if stem != rel && !built {
+		candidates = append(candidates, stem)
+	}
+	if built {
+		for i := 1; i < len(parts); i++ {
+			candidates = append(candidates, filepath.Join(append([]string{"src"}, parts[i:]...)...))
+		}
+		if !isDeclaration(rel) {
+			candidates = append(candidates, rel)
+		}
+	}
+
+	for _, candidate := range candidates {
+		if found, ok := p.findFile(filepath.Join(root, candidate)); ok && !isDeclaration(found) {
+			return found, true
+		}
+	}
+	return "", false
+}
+
+// trimOutputExt strips a declaration or compiled JavaScript extension from
+// path.
+func trimOutputExt(path string) string {
+	for _, ext := range append(declarationExts, outputExts...) {
+		if strings.HasSuffix(path, ext) {
+			return strings.TrimSuffix(path, ext)
+		}
+	}
+	return path
+}
+
+func isDeclaration(path string) bool {
+	for _, ext := range declarationExts {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,20 +3,26 @@ package analysis
 import (
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"sync"
+
+	"github.com/jesspatton/lazytest/filesystem"
 )
 
 // Parser handles parsing of source files to extract dependencies.
 type Parser struct {
-	// root is the project root used for locating tsconfig.json.
-	// Empty string means alias resolution is skipped.
+	// root is the project root; each file uses the nearest tsconfig.json up
+	// to it. Empty string means alias resolution is skipped.
 	root string
 
 	// dirCache caches os.ReadDir results to avoid repeated I/O for case-sensitive lookups.
 	// Map key: directory path (string). Map value: []os.DirEntry.
 	dirCache sync.Map
+
+	// packages are the workspace packages bare specifiers resolve to, and
+	// manifests caches their package.json by package root.
+	packages  []Package
+	manifests sync.Map
 }
 
 // NewParser creates a new Parser without a project root (alias resolution disabled).
@@ -25,28 +31,11 @@ func NewParser() *Parser {
 }
 
 // NewParserWithRoot creates a Parser that can resolve TypeScript path aliases
-// by reading tsconfig.json relative to root.
+// by reading the tsconfig.json files under root.
 func NewParserWithRoot(root string) *Parser {
 	return &Parser{root: root}
 }
 
-// Import regex patterns
-var (
-	// import ... from '...'
-	// Use [\s\S]*? to match across newlines non-greedily
-	importFromRegex = regexp.MustCompile(`import[\s\S]*?from\s+['"]([^'"]+)['"]`)
-	// import '...'
-	importSideEffectRegex = regexp.MustCompile(`import\s+['"]([^'"]+)['"]`)
-	// require('...')
-	requireRegex = regexp.MustCompile(`require\s*\(\s*['"]([^'"]+)['"]\s*\)`)
-	// jest.mock('...'), jest.doMock('...'), jest.setMock('...')
-	jestMockRegex = regexp.MustCompile(`jest\.(?:mock|doMock|setMock)\s*\(\s*['"]([^'"]+)['"]`)
-	// dynamic import: import('...')
-	dynamicImportRegex = regexp.MustCompile(`import\s*\(\s*['"]([^'"]+)['"]\s*\)`)
-	// re-export: export { ... } from '...' or export * from '...'
-	exportFromRegex = regexp.MustCompile(`export[\s\S]*?from\s+['"]([^'"]+)['"]`)
-)
-
 // ImportResult contains resolved and unresolved imports.
 type ImportResult struct {
 	Resolved   []ResolvedImport
@@ -56,12 +45,14 @@ type ImportResult struct {
 type ResolvedImport struct {
 	Path   string
 	Mocked bool
+	Kind   ImportKind
 }
 
 type UnresolvedImport struct {
 	Path       string // The raw import string (e.g. "./utils")
 	SourcePath string // The file doing the import
 	Mocked     bool
+	Kind       ImportKind
 }
 
 // ParseImports extracts imported file paths from a given file.
@@ -70,76 +61,94 @@ func (p *Parser) ParseImports(filePath string) (*ImportResult, error) {
 	if err != nil {
 		return nil, err
 	}
+	return p.parseContent(filePath, content), nil
+}
 
-	var seenImports = make(map[string]bool)
-	var mockedImports = make(map[string]bool)
-	text := string(content)
-
-	// Helper to add import
-	addImport := func(path string) {
-		seenImports[path] = true
+// parseContent extracts the imports of content, the source of filePath.
+func (p *Parser) parseContent(filePath string, content []byte) *ImportResult {
+	jsx := allowsJSX(filePath)
+	if filesystem.IsComponentFile(filePath) {
+		content, jsx = componentScript(filePath, content)
 	}
 
-	// Check for "import ... from"
-	matches := importFromRegex.FindAllStringSubmatch(text, -1)
-	for _, match := range matches {
-		if len(match) > 1 {
-			addImport(match[1])
+	// seenImports holds the first form naming each module, preferring an
+	// import to the mock call that replaces it.
+	var seenImports = make(map[string]ImportKind)
+	var mockedImports = make(map[string]bool)
+	var manualMocks = make(map[string]bool)
+	for _, spec := range scanImports(content, jsx) {
+		if kind, ok := seenImports[spec.path]; !ok || kind == KindMock {
+			seenImports[spec.path] = spec.kind
 		}
-	}
-
-	// Check for "import '...'"
-	matches = importSideEffectRegex.FindAllStringSubmatch(text, -1)
-	for _, match := range matches {
-		if len(match) > 1 {
-			addImport(match[1])
+		if spec.mocked {
+			mockedImports[spec.path] = true
+		}
+		if spec.manual {
+			manualMocks[spec.path] = true
 		}
 	}
 
-	// Check for "require"
-	matches = requireRegex.FindAllStringSubmatch(text, -1)
-	for _, match := range matches {
-		if len(match) > 1 {
-			addImport(match[1])
-		}
+	var rawImports []string
+	for imp := range seenImports {
+		rawImports = append(rawImports, imp)
 	}
 
-	// Check for "jest.mock"
-	matches = jestMockRegex.FindAllStringSubmatch(text, -1)
-	for _, match := range matches {
-		if len(match) > 1 {
-			path := match[1]
-			mockedImports[path] = true
-			addImport(path)
-		}
+	result := p.resolvePaths(filePath, rawImports, seenImports, mockedImports)
+	for imp := range manualMocks {
+		p.resolveManualMock(result, filePath, imp)
 	}
+	return result
+}
 
-	// Check for dynamic imports: import('...')
-	matches = dynamicImportRegex.FindAllStringSubmatch(text, -1)
-	for _, match := range matches {
-		if len(match) > 1 {
-			addImport(match[1])
+// resolveManualMock adds the __mocks__ file that stands in for imp, mocked
+// without a factory, as a regular dependency of sourcePath: the
+// __mocks__/<name> next to a project module, or the one in the project root
+// for a package. A mock file that does not exist yet is left pending.
+func (p *Parser) resolveManualMock(result *ImportResult, sourcePath, imp string) {
+	var mockPath string
+	switch {
+	case strings.HasPrefix(imp, "."):
+		mockPath = p.adjacentMock(filepath.Join(filepath.Dir(sourcePath), imp))
+	case p.root == "":
+		return
+	default:
+		if module, ok := p.resolveAlias(imp, sourcePath); ok {
+			mockPath = p.adjacentMock(module)
+		} else {
+			mockPath = filepath.Join(p.root, "__mocks__", filepath.FromSlash(imp))
 		}
 	}
 
-	// Check for re-exports: export { ... } from '...' and export * from '...'
-	matches = exportFromRegex.FindAllStringSubmatch(text, -1)
-	for _, match := range matches {
-		if len(match) > 1 {
-			addImport(match[1])
-		}
+	if foundPath, ok := p.findFile(mockPath); ok {
+		result.Resolved = append(result.Resolved, ResolvedImport{Path: foundPath, Kind: KindManualMock})
+	} else {
+		result.Unresolved = append(result.Unresolved, UnresolvedImport{Path: mockPath, SourcePath: sourcePath, Kind: KindManualMock})
 	}
+}
 
-	var rawImports []string
-	for imp := range seenImports {
-		rawImports = append(rawImports, imp)
+// adjacentMock returns the extensionless path of the manual mock for the
+// module at modulePath (itself possibly without extension).
+func (p *Parser) adjacentMock(modulePath string) string {
+	if found, ok := p.findFile(modulePath); ok {
+		modulePath = strings.TrimSuffix(found, filepath.Ext(found))
 	}
+	return filepath.Join(filepath.Dir(modulePath), "__mocks__", filepath.Base(modulePath))
+}
 
-	return p.resolvePaths(filePath, rawImports, mockedImports), nil
+// allowsJSX reports whether the file at path may contain JSX. TypeScript only
+// allows it in .tsx files; elsewhere <T>x is a type assertion. Components
+// decide per script block; see componentScript.
+func allowsJSX(path string) bool {
+	switch filepath.Ext(path) {
+	case ".ts", ".mts", ".cts":
+		return false
+	}
+	return true
 }
 
-// resolvePaths converts relative imports and TS path aliases to absolute paths.
-func (p *Parser) resolvePaths(sourcePath string, imports []string, mockedImports map[string]bool) *ImportResult {
+// resolvePaths converts relative imports, runner and TS path aliases and
+// imports of workspace packages to absolute paths.
+func (p *Parser) resolvePaths(sourcePath string, imports []string, kinds map[string]ImportKind, mockedImports map[string]bool) *ImportResult {
 	result := &ImportResult{
 		Resolved:   []ResolvedImport{},
 		Unresolved: []UnresolvedImport{},
@@ -149,17 +158,30 @@ func (p *Parser) resolvePaths(sourcePath string, imports []string, mockedImports
 	for _, imp := range imports {
 		var absPath string
 		isMocked := mockedImports[imp]
+		kind := kinds[imp]
+		if isMocked {
+			kind = KindMock
+		}
+
+		// Runner aliases apply first, to any specifier
+		spec := imp
+		if mapped, ok := p.mapRunnerAlias(imp, sourcePath); ok {
+			spec = mapped
+		}
+
+		// Bundler queries name a way to load the file, not another file:
+		// './icon.svg?react', './data.txt?raw'
+		if i := strings.IndexByte(spec, '?'); i > 0 {
+			spec = spec[:i]
+		}
 
-		if strings.HasPrefix(imp, ".") {
+		if filepath.IsAbs(spec) {
+			absPath = spec
+		} else if strings.HasPrefix(spec, ".") {
 			// Relative import
-			absPath = filepath.Join(dir, imp)
-		} else if p.root != "" {
-			// Try TS path alias resolution
-			if resolved, ok := ResolveAlias(imp, p.root); ok {
-				absPath = resolved
-			} else {
-				continue // node_modules or unresolvable
-			}
+			absPath = filepath.Join(dir, spec)
+		} else if resolved, ok := p.resolveBare(spec, sourcePath); ok {
+			absPath = resolved
 		} else {
 			continue // node_modules or unresolvable
 		}
@@ -169,6 +191,7 @@ func (p *Parser) resolvePaths(sourcePath string, imports []string, mockedImports
 			result.Resolved = append(result.Resolved, ResolvedImport{
 				Path:   foundPath,
 				Mocked: isMocked,
+				Kind:   kind,
 			})
 		} else {
 			// Store as unresolved, but we need the POTENTIAL absolute path (without extension)
@@ -177,6 +200,7 @@ func (p *Parser) resolvePaths(sourcePath string, imports []string, mockedImports
 				Path:       absPath, // This is the absolute path prefix (e.g. /path/to/utils)
 				SourcePath: sourcePath,
 				Mocked:     isMocked,
+				Kind:       kind,
 			})
 		}
 	}
@@ -184,12 +208,41 @@ func (p *Parser) resolvePaths(sourcePath string, imports []string, mockedImports
 	return result
 }
 
-// findFile attempts to find a file by adding common extensions.
+// resolveBare resolves a bare specifier in sourcePath through the TS path
+// aliases, then the workspace packages. Anything else comes from
+// node_modules.
+func (p *Parser) resolveBare(imp, sourcePath string) (string, bool) {
+	if resolved, ok := p.resolveAlias(imp, sourcePath); ok {
+		return resolved, true
+	}
+	return p.resolvePackage(imp)
+}
+
+// esmExtensions maps the extension an ESM import names to the TypeScript
+// sources it may compile from, as NodeNext resolution requires writing
+// './util.js' for util.ts.
+var esmExtensions = map[string][]string{
+	".js":  {".ts", ".tsx"},
+	".jsx": {".tsx"},
+	".mjs": {".mts"},
+	".cjs": {".cts"},
+}
+
+// findFile attempts to find a file by adding common extensions, or by
+// swapping a JavaScript extension for its TypeScript source.
 func (p *Parser) findFile(pathWithoutExt string) (string, bool) {
-	extensions := []string{"", ".ts", ".js", ".tsx", ".jsx", "/index.ts", "/index.js", "/index.tsx", "/index.jsx"}
+	extensions := []string{"", ".ts", ".js", ".tsx", ".jsx", ".mts", ".mjs", ".cts", ".cjs", ".json", "/index.ts", "/index.js", "/index.tsx", "/index.jsx"}
 
+	candidates := make([]string, 0, len(extensions)+2)
 	for _, ext := range extensions {
-		fullPath := pathWithoutExt + ext
+		candidates = append(candidates, pathWithoutExt+ext)
+	}
+	ext := filepath.Ext(pathWithoutExt)
+	for _, source := range esmExtensions[ext] {
+		candidates = append(candidates, strings.TrimSuffix(pathWithoutExt, ext)+source)
+	}
+
+	for _, fullPath := range candidates {
 		if info, err := os.Stat(fullPath); err == nil && !info.IsDir() {
 			// Found a match, now get the actual on-disk name to handle case sensitivity
 			dir := filepath.Dir(fullPath)
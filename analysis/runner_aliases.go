@@ -0,0 +1,372 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// runnerConfigPrefixes name the Jest, Vite and Vitest config files whose
+// aliases apply to the files below them.
+var runnerConfigPrefixes = []string{"jest.config.", "vite.config.", "vitest.config."}
+
+// runnerConfigTimeout bounds evaluating one config file with node.
+const runnerConfigTimeout = 15 * time.Second
+
+// aliasRule is a moduleNameMapper entry or a resolve.alias entry: a
+// specifier matching pattern is replaced by the first target that exists,
+// with $1... substituted from the match.
+type aliasRule struct {
+	pattern *regexp.Regexp
+	targets []string
+	dir     string // Root-relative targets ("/src") resolve against this
+}
+
+// runnerAliases is a config file's rules, loaded once.
+type runnerAliases struct {
+	once  sync.Once
+	rules []aliasRule
+}
+
+// Caches by absolute path, cleared by InvalidateRunnerAliases. aliasConfigs
+// maps a directory to the nearest runner config files.
+var (
+	aliasRules   sync.Map // map[string]*runnerAliases
+	aliasConfigs sync.Map // map[string][]string
+)
+
+// InvalidateRunnerAliases drops the cached aliases of the runner config at
+// path, so the next lookup evaluates it again, and forgets which directories
+// use which configs in case one was added or removed.
+func InvalidateRunnerAliases(path string) {
+	aliasRules.Delete(path)
+	aliasConfigs.Clear()
+}
+
+// mapRunnerAlias rewrites imp in sourcePath with the moduleNameMapper and
+// resolve.alias rules of the runner configs nearest to it. A relative result
+// is resolved against sourcePath's directory, as Jest and Vite do; a bare
+// result is a new specifier for the caller to resolve.
+func (p *Parser) mapRunnerAlias(imp, sourcePath string) (string, bool) {
+	if p.root == "" {
+		return "", false
+	}
+	for _, config := range nearestRunnerConfigs(filepath.Dir(sourcePath), p.root) {
+		for _, rule := range loadRunnerAliases(config) {
+			match := rule.pattern.FindStringSubmatchIndex(imp)
+			if match == nil {
+				continue
+			}
+			first := ""
+			for _, target := range rule.targets {
+				mapped := expandMatch(rule.pattern, imp, target, match)
+				if filepath.IsAbs(mapped) || strings.HasPrefix(mapped, ".") {
+					mapped = p.aliasTarget(mapped, filepath.Dir(sourcePath), rule.dir)
+				}
+				if first == "" {
+					first = mapped
+				}
+				if filepath.IsAbs(mapped) {
+					if _, ok := p.findFile(mapped); ok {
+						return mapped, true
+					}
+				}
+			}
+			return first, first != ""
+		}
+	}
+	return "", false
+}
+
+// aliasTarget makes a mapped path absolute: relative to the importing file's
+// directory, or for a leading "/" that is not a file, relative to the config
+// directory as Vite's root-relative paths are.
+func (p *Parser) aliasTarget(mapped, sourceDir, configDir string) string {
+	if !filepath.IsAbs(mapped) {
+		return filepath.Join(sourceDir, mapped)
+	}
+	if _, ok := p.findFile(mapped); ok {
+		return mapped
+	}
+	if rooted := filepath.Join(configDir, mapped); isWithin(rooted, configDir) {
+		if _, ok := p.findFile(rooted); ok {
+			return rooted
+		}
+	}
+	return mapped
+}
+
+// expandMatch substitutes $n (and $$ for "$") in target from a match of
+// pattern in s.
+func expandMatch(pattern *regexp.Regexp, s, target string, match []int) string {
+	var b strings.Builder
+	for i := 0; i < len(target); i++ {
+		if target[i] != '$' || i+1 == len(target) {
+			b.WriteByte(target[i])
+			continue
+		}
+		if target[i+1] == '$' {
+			b.WriteByte('$')
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(target) && isDigit(target[j]) {
+			j++
+		}
+		n, err := strconv.Atoi(target[i+1 : j])
+		if err != nil || n > pattern.NumSubexp() {
+			b.WriteByte('$')
+			continue
+		}
+		if match[2*n] >= 0 {
+			b.WriteString(s[match[2*n]:match[2*n+1]])
+		}
+		i = j - 1
+	}
+	return b.String()
+}
+
+// nearestRunnerConfigs returns the runner configs in the closest directory to
+// dir, up to root, that has any: Jest, Vite and Vitest config files and a
+// package.json with a "jest" field.
+func nearestRunnerConfigs(dir, root string) []string {
+	if cached, ok := aliasConfigs.Load(dir); ok {
+		return cached.([]string)
+	}
+	var configs []string
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, entry := range entries {
+			name := entry.Name()
+			if isRunnerConfig(name) || name == "package.json" && hasJestField(filepath.Join(dir, name)) {
+				configs = append(configs, filepath.Join(dir, name))
+			}
+		}
+	}
+	if parent := filepath.Dir(dir); len(configs) == 0 && dir != root && parent != dir && isWithin(parent, root) {
+		configs = nearestRunnerConfigs(parent, root)
+	}
+	aliasConfigs.Store(dir, configs)
+	return configs
+}
+
+func isRunnerConfig(name string) bool {
+	for _, prefix := range runnerConfigPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasJestField(path string) bool {
+	var manifest struct {
+		Jest json.RawMessage `json:"jest"`
+	}
+	data, err := os.ReadFile(path)
+	return err == nil && json.Unmarshal(data, &manifest) == nil && len(manifest.Jest) > 0
+}
+
+// loadRunnerAliases returns the rules of the config at path, loading them on
+// first use. JSON configs are read directly; JS and TS configs are evaluated
+// with node.
+func loadRunnerAliases(path string) []aliasRule {
+	cached, _ := aliasRules.LoadOrStore(path, &runnerAliases{})
+	entry := cached.(*runnerAliases)
+	entry.once.Do(func() {
+		var config *runnerAliasConfig
+		switch name := filepath.Base(path); {
+		case name == "package.json":
+			var manifest struct {
+				Jest jestAliasConfig `json:"jest"`
+			}
+			if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &manifest) == nil {
+				config = manifest.Jest.normalize(filepath.Dir(path))
+			}
+		case strings.HasSuffix(name, ".json"):
+			var jest jestAliasConfig
+			if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &jest) == nil {
+				config = jest.normalize(filepath.Dir(path))
+			}
+		default:
+			config = evalRunnerConfig(path)
+		}
+		if config != nil {
+			entry.rules = config.compile(filepath.Dir(path))
+		}
+	})
+	return entry.rules
+}
+
+// runnerAliasConfig is the alias rules of a config, as printed by
+// runnerConfigScript.
+type runnerAliasConfig struct {
+	RootDir string            `json:"rootDir"`
+	Rules   []aliasRuleConfig `json:"rules"`
+}
+
+// aliasRuleConfig is one rule of a runnerAliasConfig.
+type aliasRuleConfig struct {
+	Regex   string   `json:"regex"` // Pattern for moduleNameMapper and RegExp finds
+	Flags   string   `json:"flags"`
+	Find    string   `json:"find"` // String find of resolve.alias
+	Targets []string `json:"targets"`
+}
+
+// jestAliasConfig is the part of a JSON Jest config used for aliases.
+type jestAliasConfig struct {
+	RootDir          string    `json:"rootDir"`
+	ModuleNameMapper *jsonNode `json:"moduleNameMapper"`
+}
+
+// normalize converts a JSON Jest config in dir to the shape node prints,
+// keeping the mapper's order since the first matching entry wins.
+func (j jestAliasConfig) normalize(dir string) *runnerAliasConfig {
+	config := &runnerAliasConfig{RootDir: dir}
+	if j.RootDir != "" {
+		config.RootDir = filepath.Join(dir, j.RootDir)
+	}
+	if j.ModuleNameMapper == nil {
+		return config
+	}
+	for i, key := range j.ModuleNameMapper.keys {
+		value := j.ModuleNameMapper.values[i]
+		targets := []string{value.target}
+		if value.target == "" {
+			targets = targets[:0]
+			for _, item := range value.list {
+				targets = append(targets, item.target)
+			}
+		}
+		config.Rules = append(config.Rules, aliasRuleConfig{Regex: key, Targets: targets})
+	}
+	return config
+}
+
+// compile turns the config's rules into aliasRules, expanding <rootDir> and
+// skipping JavaScript regexes RE2 cannot compile.
+func (c *runnerAliasConfig) compile(dir string) []aliasRule {
+	rootDir := c.RootDir
+	if rootDir == "" {
+		rootDir = dir
+	}
+	var rules []aliasRule
+	for _, r := range c.Rules {
+		var source string
+		if r.Find != "" {
+			// A string find matches the specifier or a path below it.
+			source = "^" + regexp.QuoteMeta(r.Find) + "(/.*)?$"
+		} else {
+			source = r.Regex
+			if strings.Contains(r.Flags, "i") {
+				source = "(?i)" + source
+			}
+		}
+		pattern, err := regexp.Compile(source)
+		if err != nil || len(r.Targets) == 0 {
+			continue
+		}
+		targets := make([]string, len(r.Targets))
+		for i, target := range r.Targets {
+			target = strings.ReplaceAll(target, "<rootDir>", filepath.ToSlash(rootDir))
+			if r.Find != "" {
+				target = strings.ReplaceAll(target, "$", "$$") + "$1"
+			}
+			targets[i] = filepath.FromSlash(target)
+		}
+		rules = append(rules, aliasRule{pattern: pattern, targets: targets, dir: dir})
+	}
+	return rules
+}
+
+// runnerConfigMarker precedes the script's output, so anything the config
+// itself prints is ignored.
+const runnerConfigMarker = "\x00lazytest-aliases:"
+
+// runnerConfigScript loads the config file in argv[1] and prints its
+// moduleNameMapper, resolve.alias and test.alias. Vite configs go through
+// the project's own vite, which compiles TypeScript; TypeScript Jest configs
+// through jest-config.
+const runnerConfigScript = `
+import { createRequire } from 'node:module';
+import { pathToFileURL } from 'node:url';
+import path from 'node:path';
+
+const file = process.argv[1];
+const dir = path.dirname(file);
+const require = createRequire(file);
+const env = { command: 'serve', mode: 'test' };
+const importFile = async (f) => (await import(pathToFileURL(f).href)).default;
+
+async function load() {
+  if (path.basename(file).startsWith('jest.')) {
+    if (/\.[cm]?ts$/.test(file)) {
+      const { readInitialOptions } = require('jest-config');
+      return (await readInitialOptions(file)).config;
+    }
+    return importFile(file);
+  }
+  try {
+    const vite = await import(pathToFileURL(require.resolve('vite')).href);
+    const loaded = await vite.loadConfigFromFile(env, file, dir, 'silent');
+    return loaded && loaded.config;
+  } catch {
+    return importFile(file);
+  }
+}
+
+let config = await load();
+if (typeof config === 'function') config = await config(env);
+config = config || {};
+
+const out = { rootDir: config.rootDir ? path.resolve(dir, config.rootDir) : dir, rules: [] };
+for (const [regex, targets] of Object.entries(config.moduleNameMapper || {})) {
+  out.rules.push({ regex, targets: [].concat(targets) });
+}
+for (const alias of [config.resolve && config.resolve.alias, config.test && config.test.alias]) {
+  const entries = Array.isArray(alias)
+    ? alias
+    : Object.entries(alias || {}).map(([find, replacement]) => ({ find, replacement }));
+  for (const { find, replacement } of entries) {
+    if (typeof replacement !== 'string') continue;
+    if (find instanceof RegExp) {
+      out.rules.push({ regex: find.source, flags: find.flags, targets: [replacement] });
+    } else if (typeof find === 'string') {
+      out.rules.push({ find, targets: [replacement] });
+    }
+  }
+}
+process.stdout.write(MARKER + JSON.stringify(out));
+`
+
+// evalRunnerConfig evaluates a JS or TS config with node. It returns nil if
+// node is missing or the config fails to load.
+func evalRunnerConfig(path string) *runnerAliasConfig {
+	ctx, cancel := context.WithTimeout(context.Background(), runnerConfigTimeout)
+	defer cancel()
+
+	script := strings.Replace(runnerConfigScript, "MARKER", strconv.Quote(runnerConfigMarker), 1)
+	cmd := exec.CommandContext(ctx, "node", "--input-type=module", "-e", script, path)
+	cmd.Dir = filepath.Dir(path)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	i := bytes.LastIndex(out, []byte(runnerConfigMarker))
+	if i < 0 {
+		return nil
+	}
+	var config runnerAliasConfig
+	if json.Unmarshal(out[i+len(runnerConfigMarker):], &config) != nil {
+		return nil
+	}
+	return &config
+}
@@ -0,0 +1,147 @@
+// Package config loads the per-user settings that apply to every project, as
+// opposed to the per-project .lazytest.json read by the runner package.
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// UserConfig holds the settings read from the user config file.
+type UserConfig struct {
+	// Keybindings maps action names (e.g. "down", "copy_output") to the keys
+	// that trigger them, replacing that action's default keys. An empty list
+	// unbinds the action.
+	Keybindings map[string][]string `json:"keybindings,omitempty"`
+
+	// Theme is a built-in theme name, the name of a theme file in the
+	// themes directory (see ThemeDir), or a path to a theme file.
+	Theme string `json:"theme,omitempty"`
+
+	// Layout is the pane arrangement. It is rewritten by SaveLayout whenever
+	// the split or orientation is changed from the UI.
+	Layout Layout `json:"layout,omitempty"`
+}
+
+// Layout describes how the explorer and output panes share the screen.
+type Layout struct {
+	// SplitRatio is the explorer's share of the split, between 0 and 1. Zero
+	// means the default even split.
+	SplitRatio float64 `json:"split_ratio,omitempty"`
+
+	// Orientation is "auto", "horizontal" (side by side) or "vertical"
+	// (stacked). Auto stacks the panes below VerticalBelow columns.
+	Orientation string `json:"orientation,omitempty"`
+
+	// VerticalBelow is the terminal width under which auto orientation stacks
+	// the panes. Zero means the default.
+	VerticalBelow int `json:"vertical_below,omitempty"`
+}
+
+// Path returns the location of the user config file: $LAZYTEST_CONFIG if set,
+// otherwise lazytest/config.json under the OS user config directory.
+func Path() (string, error) {
+	if path := os.Getenv("LAZYTEST_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lazytest", "config.json"), nil
+}
+
+// Dir returns the directory holding the user config file.
+func Dir() (string, error) {
+	path, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(path), nil
+}
+
+// ThemeDir returns the directory searched for theme files by name.
+func ThemeDir() string {
+	dir, err := Dir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "themes")
+}
+
+// CacheDir returns the cache directory for the project at root, under
+// $LAZYTEST_CACHE_DIR if set or lazytest/ in the OS user cache directory.
+// Each project gets its own subdirectory named after it and a hash of its
+// path.
+func CacheDir(root string) (string, error) {
+	base := os.Getenv("LAZYTEST_CACHE_DIR")
+	if base == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(dir, "lazytest")
+	}
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(base, fmt.Sprintf("%s-%x", filepath.Base(root), sum[:6])), nil
+}
+
+// Load reads the user config file. A missing file is not an error and yields
+// the zero UserConfig.
+func Load() (UserConfig, error) {
+	path, err := Path()
+	if err != nil {
+		return UserConfig{}, nil
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads the user config from path. A missing file yields the zero
+// UserConfig.
+func LoadFile(path string) (UserConfig, error) {
+	var cfg UserConfig
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return UserConfig{}, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// SaveLayout stores layout in the user config file at path, keeping every
+// other setting in the file as written.
+func SaveLayout(path string, layout Layout) error {
+	raw := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("invalid %s: %w", path, err)
+		}
+	}
+
+	encoded, err := json.Marshal(layout)
+	if err != nil {
+		return err
+	}
+	raw["layout"] = encoded
+
+	data, err = json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadFile_Missing(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("Expected no error for a missing file, got %v", err)
+	}
+	if cfg.Keybindings != nil {
+		t.Errorf("Expected zero config, got %+v", cfg)
+	}
+}
+
+func TestLoadFile_Keybindings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"keybindings": {"down": ["n", "down"], "next_match": ["j"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{"down": {"n", "down"}, "next_match": {"j"}}
+	if !reflect.DeepEqual(cfg.Keybindings, want) {
+		t.Errorf("Expected %v, got %v", want, cfg.Keybindings)
+	}
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"keybindings": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("Expected an error for malformed JSON")
+	}
+}
+
+func TestPath_EnvOverride(t *testing.T) {
+	t.Setenv("LAZYTEST_CONFIG", "/tmp/custom.json")
+	path, err := Path()
+	if err != nil || path != "/tmp/custom.json" {
+		t.Errorf("Expected env override, got %q, %v", path, err)
+	}
+}
+
+func TestSaveLayout_KeepsOtherSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lazytest", "config.json")
+	if err := SaveLayout(path, Layout{SplitRatio: 0.3}); err != nil {
+		t.Fatalf("Expected the file and its directory to be created, got %v", err)
+	}
+	if err := os.WriteFile(path, []byte(`{"theme": "nord", "layout": {"split_ratio": 0.3}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveLayout(path, Layout{SplitRatio: 0.65, Orientation: "vertical"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Theme != "nord" {
+		t.Errorf("Expected the theme to survive, got %q", cfg.Theme)
+	}
+	if want := (Layout{SplitRatio: 0.65, Orientation: "vertical"}); cfg.Layout != want {
+		t.Errorf("Expected %+v, got %+v", want, cfg.Layout)
+	}
+}
+
+func TestCacheDir_PerProject(t *testing.T) {
+	t.Setenv("LAZYTEST_CACHE_DIR", "/tmp/lazytest-cache")
+	a, err := CacheDir("/work/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := CacheDir("/other/app")
+	if filepath.Dir(a) != "/tmp/lazytest-cache" || !strings.HasPrefix(filepath.Base(a), "app-") {
+		t.Errorf("Unexpected cache dir %q", a)
+	}
+	if a == b {
+		t.Error("Expected projects with the same name to get different cache dirs")
+	}
+}
@@ -1,10 +1,12 @@
 package engine
 
 import (
+	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/jesspatton/lazytest/filesystem"
+	"github.com/jesspatton/lazytest/runner"
 )
 
 // Accessors
@@ -22,6 +24,9 @@ func (e *Engine) GetWatchedFiles() []string {
 }
 
 func (e *Engine) GetTestOutput(path string) (string, bool) {
+	if screen, ok := e.State.Screens[path]; ok {
+		return screen.String(), true
+	}
 	val, ok := e.State.TestOutputs[path]
 	if !ok {
 		return "", false
@@ -29,15 +34,74 @@ func (e *Engine) GetTestOutput(path string) (string, bool) {
 	return strings.Join(val, ""), true
 }
 
+// SetTerminalSize sets the size of the pseudo-terminal given to PTY runs
+// started from now on.
+func (e *Engine) SetTerminalSize(cols, rows int) {
+	if cols > 0 && rows > 0 {
+		e.ptyCols, e.ptyRows = cols, rows
+	}
+}
+
+// GetTestStderr returns only the stderr lines captured for path's last run.
+// PTY runs merge stderr into the terminal, so they have none.
+func (e *Engine) GetTestStderr(path string) string {
+	return strings.Join(e.State.TestStderr[path], "")
+}
+
+// GetRunnerName returns the detected test runner (e.g. "jest", "vitest") that
+// applies to path, taking workspace packages into account. The result is
+// cached per path until the next config change, since detecting it reads the
+// config files from disk.
+func (e *Engine) GetRunnerName(path string) string {
+	if name, ok := e.runnerNames[path]; ok {
+		return name
+	}
+	name := runner.LoadConfigForPath(path, e.Workspaces).DetectedRunner
+	if e.runnerNames == nil {
+		e.runnerNames = make(map[string]string)
+	}
+	e.runnerNames[path] = name
+	return name
+}
+
 func (e *Engine) GetNodeStatus(path string) (TestStatus, bool) {
 	val, ok := e.State.NodeStatus[path]
 	return val, ok
 }
 
+// StatusVersion changes whenever a test's status changes, so views can cache
+// what they derive from the statuses until it moves.
+func (e *Engine) StatusVersion() int {
+	return e.statusVersion
+}
+
+func (e *Engine) setStatus(path string, status TestStatus) {
+	e.State.NodeStatus[path] = status
+	e.statusVersion++
+}
+
+// TreeVersion changes whenever the tree is replaced or a file is added to or
+// removed from it in place.
+func (e *Engine) TreeVersion() int {
+	return e.treeVersion
+}
+
 func (e *Engine) GetTree() *filesystem.Node {
 	return e.State.Tree
 }
 
+// GetSourceFiles returns the non-test source files known to the dependency
+// graph. It is empty until the initial graph build completes.
+func (e *Engine) GetSourceFiles() []string {
+	var files []string
+	for _, path := range e.Graph.Files() {
+		if !filesystem.IsTestFileByPath(path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
 
 
 func (e *Engine) IsWatched(path string) bool {
@@ -54,3 +118,14 @@ func (e *Engine) IsSmartMode() bool {
 func (e *Engine) HasAnyOutput() bool {
 	return len(e.State.TestOutputs) > 0
 }
+
+// WorkspaceName returns the name of the workspace package containing path, or
+// the project directory's name outside any workspace.
+func (e *Engine) WorkspaceName(path string) string {
+	for _, ws := range e.Workspaces {
+		if strings.HasPrefix(path, ws.Root+string(filepath.Separator)) {
+			return ws.Name
+		}
+	}
+	return filepath.Base(e.State.RootPath)
+}
@@ -2,36 +2,90 @@ package engine
 
 import (
 	"fmt"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jesspatton/lazytest/filesystem"
 	"github.com/jesspatton/lazytest/runner"
+	"github.com/jesspatton/lazytest/terminal"
 )
 
 // Actions
 
 func (e *Engine) TriggerTest(node *filesystem.Node) tea.Cmd {
+	return e.triggerTest(node, e.reasonHeader(node.Path)+fmt.Sprintf("Running %s...\n", node.Name))
+}
+
+// CanUpdateSnapshots reports whether the test at path runs under Jest or
+// Vitest, the runners UpdateSnapshots knows the update flag of.
+func (e *Engine) CanUpdateSnapshots(path string) bool {
+	switch e.GetRunnerName(path) {
+	case "jest", "vitest":
+		return true
+	}
+	return false
+}
+
+// UpdateSnapshots re-runs the test at path with the runner's update flag (-u),
+// rewriting that file's snapshots only. The flag is appended to the command
+// that applies to path, so a custom command or override must forward extra
+// arguments to the runner, e.g. "npm test --". It reports an error instead
+// when CanUpdateSnapshots is false.
+func (e *Engine) UpdateSnapshots(path string) tea.Cmd {
+	node := filesystem.NodeFromPath(path)
+	if !e.CanUpdateSnapshots(path) {
+		return func() tea.Msg {
+			return NotificationMsg{Message: fmt.Sprintf("Cannot update snapshots of %s: only Jest and Vitest are supported.", node.Name), IsError: true}
+		}
+	}
+	return e.triggerTest(node, fmt.Sprintf("Updating snapshots for %s...\n", node.Name), "-u")
+}
+
+// triggerTest starts node's test with header as the first output line and
+// extraArgs appended to the runner command.
+func (e *Engine) triggerTest(node *filesystem.Node, header string, extraArgs ...string) tea.Cmd {
 	e.State.RunningNodes[node.Path] = node
 	e.State.LastRunNode = node
 	
-	output := fmt.Sprintf("Running %s...\n", node.Name)
+	output := header
 	e.State.TestOutputs[node.Path] = []string{output}
-	e.State.NodeStatus[node.Path] = StatusRunning
+	delete(e.State.TestStderr, node.Path)
+	delete(e.State.Screens, node.Path)
+	e.setStatus(node.Path, StatusRunning)
 	// Track in affected suite regardless of mode
 	e.State.Affected[node.Path] = struct{}{}
 
 	job, err := runner.PrepareJob(node.Path, e.Workspaces)
 	if err != nil {
 		e.State.TestOutputs[node.Path] = append(e.State.TestOutputs[node.Path], "Error: Could not find package.json\n")
-		e.State.NodeStatus[node.Path] = StatusFail
+		e.setStatus(node.Path, StatusFail)
 		delete(e.State.RunningNodes, node.Path)
 		e.UpdateSortedAffected()
 		return nil
 	}
 
 	e.UpdateSortedAffected()
+	cols, rows := e.ptyCols, e.ptyRows
+	if job.PTY {
+		// GetTestOutput reads the screen from now on, so it gets the header too.
+		e.State.Screens[node.Path] = terminal.NewScreen(cols, rows)
+		e.appendOutput(node.Path, header)
+	}
+	now := time.Now()
+	e.State.StartedAt[node.Path] = now
+	if e.State.Suite.Started.IsZero() {
+		e.State.Suite.Started = now
+	}
+	e.lastRunID++
+	runID := e.lastRunID
+	e.runIDs[node.Path] = runID
+	args := append(job.Args, extraArgs...)
 	return func() tea.Msg {
-		e.runner.Run(job.Command, job.Args, job.Root, node.Path)
+		if job.PTY {
+			e.runner.RunPTY(job.Command, args, job.Root, node.Path, runID, cols, rows)
+		} else {
+			e.runner.Run(job.Command, args, job.Root, node.Path, runID)
+		}
 		return nil
 	}
 }
@@ -68,6 +122,18 @@ func (e *Engine) ToggleWatch(path string) {
 	}
 }
 
+// SetWatched adds (watched=true) or removes (watched=false) every path in
+// paths from the watched set.
+func (e *Engine) SetWatched(paths []string, watched bool) {
+	for _, path := range paths {
+		if watched {
+			e.State.Watched[path] = struct{}{}
+		} else {
+			delete(e.State.Watched, path)
+		}
+	}
+}
+
 func (e *Engine) ClearWatched() {
 	e.State.Watched = make(map[string]struct{})
 }
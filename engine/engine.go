@@ -10,6 +10,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jesspatton/lazytest/analysis"
 	"github.com/jesspatton/lazytest/filesystem"
+	"github.com/jesspatton/lazytest/history"
 	"github.com/jesspatton/lazytest/runner"
 )
 
@@ -21,22 +22,68 @@ type Engine struct {
 	Graph               *analysis.Graph
 	ProjectConfig       runner.Config
 	Workspaces          []runner.Workspace // Nil for single-package repos
+	History             *history.Store     // Finished runs; in memory unless replaced by a persisted store
 	InitialNotification string
+
+	graphCache string // Graph cache file; empty to rebuild from scratch on launch
+
+	statusVersion int               // Bumped on every NodeStatus change
+	treeVersion   int               // Bumped on every change to State.Tree
+	runnerNames   map[string]string // Detected runner per test path, reset on config change
+
+	// runIDs holds the latest run of each test; updates from an older run
+	// that was killed by a restart are dropped.
+	runIDs    map[string]int
+	lastRunID int
+
+	ptyCols, ptyRows int // Size of new PTYs; follows the output pane
 }
 
+// Default PTY size until the UI reports the output pane's size.
+const (
+	defaultPTYCols = 120
+	defaultPTYRows = 40
+)
+
 // New creates a new Engine instance.
 func New(rootPath string) *Engine {
 	e := &Engine{
 		State:         NewState(rootPath),
 		runner:        runner.NewRunner(),
-		Graph:         analysis.NewGraphWithRoot(rootPath),
 		ProjectConfig: runner.LoadConfig(rootPath),
 		Workspaces:    runner.DiscoverWorkspaces(rootPath),
+		History:       history.NewMemory(),
+		runIDs:        make(map[string]int),
+		ptyCols:       defaultPTYCols,
+		ptyRows:       defaultPTYRows,
 	}
+	e.Graph = e.newGraph()
 	e.State.WelcomeMessage = e.generateWelcome()
 	return e
 }
 
+// newGraph returns an empty dependency graph that resolves imports of the
+// workspace packages to their sources.
+func (e *Engine) newGraph() *analysis.Graph {
+	g := analysis.NewGraphWithRoot(e.State.RootPath)
+	pkgs := make([]analysis.Package, len(e.Workspaces))
+	for i, ws := range e.Workspaces {
+		pkgs[i] = analysis.Package{Name: ws.Name, Root: ws.Root}
+	}
+	g.SetPackages(pkgs)
+	if e.graphCache != "" {
+		g.SetCache(e.graphCache)
+	}
+	return g
+}
+
+// SetGraphCache stores the dependency graph at path between launches, so
+// Init only re-parses the files that changed since. Call it before Init.
+func (e *Engine) SetGraphCache(path string) {
+	e.graphCache = path
+	e.Graph.SetCache(path)
+}
+
 // generateWelcome builds the startup banner shown in the output pane.
 func (e *Engine) generateWelcome() string {
 	var sb strings.Builder
@@ -91,6 +138,9 @@ func (e *Engine) Update(msg tea.Msg) tea.Cmd {
 	case WatcherMsg:
 		return e.handleWatcherMsg(string(msg))
 
+	case RenameMsg:
+		return e.handleRename(msg)
+
 	case WatcherErrorMsg:
 		return e.handleWatcherError(msg)
 
@@ -114,12 +164,26 @@ func (e *Engine) Update(msg tea.Msg) tea.Cmd {
 }
 
 func (e *Engine) handleWatcherMsg(path string) tea.Cmd {
-	if filesystem.IsConfigFile(path) {
+	if e.isConfigFile(path) {
 		return e.handleConfigChange(path)
 	}
+	if filesystem.IsSnapshotFile(path) {
+		// A snapshot change belongs to the test file that owns it.
+		owner := filesystem.SnapshotOwner(path)
+		if _, err := os.Stat(owner); owner == "" || err != nil {
+			return e.waitForWatcherEvents
+		}
+		return e.handleSourceChange(owner)
+	}
 	return e.handleSourceChange(path)
 }
 
+// isConfigFile reports whether path is a config file that steers the runner
+// or import resolution, including tsconfig bases not named tsconfig.*.
+func (e *Engine) isConfigFile(path string) bool {
+	return filesystem.IsConfigFile(path) || e.Graph.ReadsConfig(path)
+}
+
 func (e *Engine) handleWatcherError(msg WatcherErrorMsg) tea.Cmd {
 	return tea.Batch(
 		func() tea.Msg {
@@ -136,9 +200,17 @@ func (e *Engine) handleConfigChange(path string) tea.Cmd {
 	// 1. Reload runner configuration and workspace list
 	e.ProjectConfig = runner.LoadConfig(e.State.RootPath)
 	e.Workspaces = runner.DiscoverWorkspaces(e.State.RootPath)
+	e.runnerNames = nil
 
-	// 2. Rebuild graph asynchronously
-	e.Graph = analysis.NewGraphWithRoot(e.State.RootPath)
+	// 2. Rebuild graph asynchronously, re-reading tsconfig files and the
+	// aliases of the changed runner config
+	analysis.InvalidateTSConfigCache(e.State.RootPath)
+	analysis.InvalidateRunnerAliases(path)
+	e.Graph = e.newGraph()
+	if e.watcher != nil {
+		e.watcher.SetTracked(e.Graph.Tracks)
+		e.watcher.SetConfigs(e.Graph.ReadsConfig)
+	}
 	e.State.IsBuildingGraph = true
 
 	return tea.Batch(
@@ -154,28 +226,69 @@ func (e *Engine) handleConfigChange(path string) tea.Cmd {
 func (e *Engine) handleSourceChange(path string) tea.Cmd {
 	e.State.IsBuildingGraph = true
 
+	_, err := os.Stat(path)
 	if e.State.Tree != nil {
-		if _, err := os.Stat(path); err == nil {
+		if err == nil {
 			if filesystem.IsTestFileByPath(path) {
 				e.State.Tree.AddNode(path)
+				e.treeVersion++
 			}
 		} else if os.IsNotExist(err) {
 			if filesystem.IsTestFileByPath(path) {
 				e.State.Tree.RemoveNode(path)
+				e.treeVersion++
 			}
 		}
 	}
 
+	// A deleted file the graph knew leaves importers behind to re-queue.
+	deleted := os.IsNotExist(err) && e.Graph.Tracks(path)
+
 	return tea.Batch(
 		e.waitForWatcherEvents,
 		func() tea.Msg {
 			// Update dependency graph
+			if deleted {
+				return GraphUpdateCompleteMsg{RemovedPath: path, Removed: e.Graph.Remove(path)}
+			}
 			e.Graph.Update(path)
 			return GraphUpdateCompleteMsg{SourcePath: path}
 		},
 	)
 }
 
+// handleRename moves a renamed file in the tree and the graph. A watched test
+// stays watched under its new name.
+func (e *Engine) handleRename(msg RenameMsg) tea.Cmd {
+	e.State.IsBuildingGraph = true
+
+	if e.State.Tree != nil {
+		if filesystem.IsTestFileByPath(msg.From) {
+			e.State.Tree.RemoveNode(msg.From)
+			e.treeVersion++
+		}
+		if filesystem.IsTestFileByPath(msg.To) {
+			e.State.Tree.AddNode(msg.To)
+			e.treeVersion++
+		}
+	}
+	if _, ok := e.State.Watched[msg.From]; ok {
+		delete(e.State.Watched, msg.From)
+		if filesystem.IsTestFileByPath(msg.To) {
+			e.State.Watched[msg.To] = struct{}{}
+		}
+	}
+
+	return tea.Batch(
+		e.waitForWatcherEvents,
+		func() tea.Msg {
+			removed := e.Graph.Remove(msg.From)
+			e.Graph.Update(msg.To)
+			return GraphUpdateCompleteMsg{SourcePath: msg.To, RemovedPath: msg.From, Removed: removed}
+		},
+	)
+}
+
 func (e *Engine) handleGraphBuildComplete(msg GraphBuildCompleteMsg) tea.Cmd {
 	e.State.IsBuildingGraph = false
 	if msg.ConfigPath == "" {
@@ -199,7 +312,7 @@ func (e *Engine) handleGraphBuildComplete(msg GraphBuildCompleteMsg) tea.Cmd {
 
 	msgStr := fmt.Sprintf("\nConfig change detected (%s). Reloaded settings and re-queued tests.\n", filepath.Base(msg.ConfigPath))
 	for nodePath := range e.State.RunningNodes {
-		e.State.TestOutputs[nodePath] = append(e.State.TestOutputs[nodePath], msgStr)
+		e.appendOutput(nodePath, msgStr)
 	}
 
 	var nodes []*filesystem.Node
@@ -217,18 +330,45 @@ func (e *Engine) handleGraphUpdateComplete(msg GraphUpdateCompleteMsg) tea.Cmd {
 	e.State.IsBuildingGraph = false
 	path := msg.SourcePath
 
+	// The importers of a removed file are the tests about to break.
+	var removedDependents map[string]struct{}
+	var removedTests []string
+	if msg.Removed != nil {
+		removedDependents = msg.Removed.Dependents()
+		for dep := range removedDependents {
+			if filesystem.IsTestFileByPath(dep) {
+				removedTests = append(removedTests, dep)
+			}
+		}
+		sort.Strings(removedTests)
+	}
+
 	var testsToQueue []string
 	if e.State.SmartMode {
-		// Smart Mode: automatically queue every test transitively affected by this path
-		testsToQueue = e.FindRelatedTests(path)
+		// Smart Mode: automatically queue every test transitively affected by
+		// this path, recording the import chain that reached each one
+		if path != "" {
+			testsToQueue = e.FindRelatedTests(path)
+			e.recordReasons(testsToQueue, e.Graph.Explain(path), false)
+		}
+		if msg.Removed != nil {
+			e.recordReasons(removedTests, msg.Removed, true)
+		}
+		testsToQueue = append(testsToQueue, removedTests...)
 	} else {
 		// Manual Mode: only queue watched tests that are in the affected set
-		dependents := e.Graph.GetAffectedDependents(path)
+		dependents := make(map[string]struct{})
+		if path != "" {
+			dependents = e.Graph.GetAffectedDependents(path)
+		}
 		for watchedPath := range e.State.Watched {
-			affected := watchedPath == path
+			affected := path != "" && watchedPath == path
 			if !affected {
 				_, affected = dependents[watchedPath]
 			}
+			if !affected {
+				_, affected = removedDependents[watchedPath]
+			}
 			if affected {
 				testsToQueue = append(testsToQueue, watchedPath)
 			}
@@ -246,19 +386,54 @@ func (e *Engine) handleGraphUpdateComplete(msg GraphUpdateCompleteMsg) tea.Cmd {
 	return e.enqueueNodes(nodes)
 }
 
+// isStale reports whether an update came from a run of path that has since
+// been restarted. Its remaining output and exit status belong to the killed
+// process, not to the run now shown.
+func (e *Engine) isStale(path string, runID int) bool {
+	return runID != e.runIDs[path]
+}
+
 func (e *Engine) handleOutputUpdate(msg runner.OutputUpdate) tea.Cmd {
-	e.State.TestOutputs[msg.FilePath] = append(e.State.TestOutputs[msg.FilePath], msg.Content+"\n")
+	if e.isStale(msg.FilePath, msg.RunID) {
+		return e.waitForUpdates
+	}
+	if msg.Raw {
+		// Raw chunks come from PTY runs, which write to their screen.
+		if screen, ok := e.State.Screens[msg.FilePath]; ok {
+			screen.Write([]byte(msg.Content))
+		}
+		return e.waitForUpdates
+	}
+	e.appendOutput(msg.FilePath, msg.Content+"\n")
+	if msg.Stderr {
+		e.State.TestStderr[msg.FilePath] = append(e.State.TestStderr[msg.FilePath], msg.Content+"\n")
+	}
 	return e.waitForUpdates
 }
 
+// appendOutput adds text written by LazyTest itself to path's output. For a
+// PTY run it goes through the terminal screen, after the runner's output.
+func (e *Engine) appendOutput(path, text string) {
+	if screen, ok := e.State.Screens[path]; ok {
+		screen.Write([]byte(strings.ReplaceAll(text, "\n", "\r\n")))
+		return
+	}
+	e.State.TestOutputs[path] = append(e.State.TestOutputs[path], text)
+}
+
 func (e *Engine) handleStatusUpdate(msg runner.StatusUpdate) tea.Cmd {
+	if e.isStale(msg.FilePath, msg.RunID) {
+		return e.waitForUpdates
+	}
+	cmds := []tea.Cmd{e.waitForUpdates}
 	if _, exists := e.State.RunningNodes[msg.FilePath]; exists {
+		cmds = append(cmds, e.recordResult(msg.FilePath, msg.Err == nil))
 		if msg.Err == nil {
-			e.State.NodeStatus[msg.FilePath] = StatusPass
-			e.State.TestOutputs[msg.FilePath] = append(e.State.TestOutputs[msg.FilePath], "\nPASS\n")
+			e.setStatus(msg.FilePath, StatusPass)
+			e.appendOutput(msg.FilePath, "\nPASS\n")
 		} else {
-			e.State.NodeStatus[msg.FilePath] = StatusFail
-			e.State.TestOutputs[msg.FilePath] = append(e.State.TestOutputs[msg.FilePath], fmt.Sprintf("\nFAIL: %v\n", msg.Err))
+			e.setStatus(msg.FilePath, StatusFail)
+			e.appendOutput(msg.FilePath, fmt.Sprintf("\nFAIL: %v\n", msg.Err))
 		}
 		delete(e.State.RunningNodes, msg.FilePath)
 	}
@@ -266,20 +441,23 @@ func (e *Engine) handleStatusUpdate(msg runner.StatusUpdate) tea.Cmd {
 	e.UpdateSortedAffected()
 
 	// Process queue
-	cmd := e.ProcessQueue()
-	if cmd != nil {
-		return tea.Batch(e.waitForUpdates, cmd)
+	cmds = append(cmds, e.ProcessQueue())
+	if len(e.State.RunningNodes) == 0 && len(e.State.Queue) == 0 {
+		cmds = append(cmds, e.finishSuite())
 	}
-	return e.waitForUpdates
+	return tea.Batch(cmds...)
 }
 
 func (e *Engine) handleWatcherReady(msg WatcherReadyMsg) tea.Cmd {
 	e.watcher = msg.watcher
+	e.watcher.SetTracked(e.Graph.Tracks)
+	e.watcher.SetConfigs(e.Graph.ReadsConfig)
 	return e.waitForWatcherEvents
 }
 
 func (e *Engine) handleTreeLoaded(msg TreeLoadedMsg) tea.Cmd {
 	e.State.Tree = msg
+	e.treeVersion++
 	return nil
 }
 
@@ -314,6 +492,11 @@ func (e *Engine) waitForWatcherEvents() tea.Msg {
 			return nil
 		}
 		return WatcherMsg(eventPath)
+	case rename, ok := <-e.watcher.Renames:
+		if !ok {
+			return nil
+		}
+		return RenameMsg(rename)
 	case err, ok := <-e.watcher.Errors:
 		if !ok {
 			return nil
@@ -337,6 +520,9 @@ func (e *Engine) buildGraph() tea.Msg {
 
 // Close stops background routines like the file watcher and running processes.
 func (e *Engine) Close() {
+	// Keep the edits of this session for the next launch
+	_ = e.Graph.SaveCache()
+	_ = e.History.Flush()
 	if e.watcher != nil {
 		e.watcher.Close()
 	}
@@ -1,15 +1,20 @@
 package engine
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/analysis"
 	"github.com/jesspatton/lazytest/filesystem"
 	"github.com/jesspatton/lazytest/runner"
+	"github.com/jesspatton/lazytest/terminal"
 )
 
 // flushCmds recursively executes cmds to simulate the Bubbletea event loop for tests.
@@ -195,6 +200,73 @@ func TestUpdateLoop(t *testing.T) {
 	}
 }
 
+func TestTriggerTest_PTYConfigCreatesScreen(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, ".lazytest.json"), []byte(`{"command": "echo <path>", "pty": true}`), 0644)
+	testFile := filepath.Join(tmpDir, "foo.test.js")
+
+	e := New(tmpDir)
+	e.SetTerminalSize(100, 30)
+	if cmd := e.TriggerTest(&filesystem.Node{Path: testFile, Name: "foo.test.js"}); cmd == nil {
+		t.Fatal("Expected TriggerTest to return a command")
+	}
+	if _, ok := e.State.Screens[testFile]; !ok {
+		t.Fatal("Expected a terminal screen for a PTY run")
+	}
+	if out, _ := e.GetTestOutput(testFile); out != "Running foo.test.js...\n" {
+		t.Errorf("Expected the header on the screen, got %q", out)
+	}
+}
+
+func TestUpdateLoop_PTY(t *testing.T) {
+	e := New("/tmp")
+	e.ProjectConfig.MaxConcurrentTests = 0
+	node := &filesystem.Node{Path: "/tmp/foo.test.js", Name: "foo.test.js"}
+	e.State.RunningNodes[node.Path] = node
+	e.State.TestOutputs[node.Path] = []string{"Running foo.test.js...\n"}
+	e.State.Screens[node.Path] = terminal.NewScreen(80, 24)
+
+	// A progress line redrawn in place and split across chunks
+	e.Update(runner.OutputUpdate{FilePath: node.Path, Content: "1/2 tests\r", Raw: true})
+	e.Update(runner.OutputUpdate{FilePath: node.Path, Content: "2/2 tests\r\n", Raw: true})
+	e.Update(runner.StatusUpdate{FilePath: node.Path})
+
+	out, _ := e.GetTestOutput(node.Path)
+	if want := "2/2 tests\n\nPASS\n"; out != want {
+		t.Errorf("Expected output %q, got %q", want, out)
+	}
+}
+
+// TestRestart_DropsUpdatesFromKilledRun verifies that output and the exit
+// status still in flight from a restarted run do not reach the new run.
+func TestRestart_DropsUpdatesFromKilledRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, ".lazytest.json"), []byte(`{"command": "echo <path>", "pty": true}`), 0644)
+	testFile := filepath.Join(tmpDir, "foo.test.js")
+	node := &filesystem.Node{Path: testFile, Name: "foo.test.js"}
+
+	e := New(tmpDir)
+	e.TriggerTest(node)
+	killed := e.runIDs[testFile]
+	e.TriggerTest(node)
+
+	e.Update(runner.OutputUpdate{FilePath: testFile, RunID: killed, Content: "old run\r\n", Raw: true})
+	e.Update(runner.StatusUpdate{FilePath: testFile, RunID: killed, Err: errors.New("signal: killed")})
+	if out, _ := e.GetTestOutput(testFile); strings.Contains(out, "old run") {
+		t.Errorf("Expected the killed run's output to be dropped, got %q", out)
+	}
+	if status, _ := e.GetNodeStatus(testFile); status != StatusRunning {
+		t.Errorf("Expected the new run to keep running, got %v", status)
+	}
+
+	e.Update(runner.OutputUpdate{FilePath: testFile, RunID: e.runIDs[testFile], Content: "new run\r\n", Raw: true})
+	if out, _ := e.GetTestOutput(testFile); !strings.Contains(out, "new run") {
+		t.Errorf("Expected the new run's output, got %q", out)
+	}
+}
+
 func TestSmartQueueing(t *testing.T) {
 	e := New("/tmp")
 	e.ProjectConfig.MaxConcurrentTests = 0
@@ -813,3 +885,405 @@ func TestUpdate_UnknownMsg(t *testing.T) {
 		t.Errorf("Expected nil tea.Cmd for unknown message type, got %v", cmd)
 	}
 }
+
+// TestSnapshotChange_QueuesOwningTest verifies that a change to a .snap file
+// is treated as a change to the test file that owns it.
+func TestSnapshotChange_QueuesOwningTest(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	testFile := filepath.Join(tmpDir, "render.test.ts")
+	snapFile := filepath.Join(tmpDir, "__snapshots__", "render.test.ts.snap")
+	if err := os.MkdirAll(filepath.Dir(snapFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testFile, []byte("test('renders', () => {});"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(snapFile, []byte("exports[`renders 1`] = `x`;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New(tmpDir)
+	e.ProjectConfig.MaxConcurrentTests = 0
+	e.Graph.Build(tmpDir)
+	e.ToggleSmartMode()
+
+	cmd := e.Update(WatcherMsg(snapFile))
+	flushCmds(e, cmd)
+
+	if len(e.State.Queue) != 1 || e.State.Queue[0] != testFile {
+		t.Errorf("Expected owning test %s to be queued, got %v", testFile, e.State.Queue)
+	}
+}
+
+// TestFixtureChange_QueuesImportingTest verifies that editing a JSON fixture
+// a test imports queues that test, and that the watcher reports the fixture.
+func TestFixtureChange_QueuesImportingTest(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	testFile := filepath.Join(tmpDir, "user.test.ts")
+	fixture := filepath.Join(tmpDir, "fixtures", "user.json")
+	if err := os.MkdirAll(filepath.Dir(fixture), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testFile, []byte("import user from './fixtures/user.json';"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fixture, []byte(`{"name": "a"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New(tmpDir)
+	e.ProjectConfig.MaxConcurrentTests = 0
+	e.Graph.Build(tmpDir)
+	e.ToggleSmartMode()
+
+	if !e.Graph.Tracks(fixture) {
+		t.Fatalf("Expected the graph to track %s for the watcher", fixture)
+	}
+
+	cmd := e.Update(WatcherMsg(fixture))
+	flushCmds(e, cmd)
+
+	if len(e.State.Queue) != 1 || e.State.Queue[0] != testFile {
+		t.Errorf("Expected importing test %s to be queued, got %v", testFile, e.State.Queue)
+	}
+}
+
+// TestGetRunnerName_CachedUntilConfigChange verifies that the detected runner
+// is not re-read from disk per call, but is after a config change.
+func TestGetRunnerName_CachedUntilConfigChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	pkg := filepath.Join(tmpDir, "package.json")
+	testFile := filepath.Join(tmpDir, "a.test.ts")
+	if err := os.WriteFile(pkg, []byte(`{"devDependencies": {"jest": "29"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New(tmpDir)
+	if got := e.GetRunnerName(testFile); got != "jest" {
+		t.Fatalf("Expected jest, got %q", got)
+	}
+
+	if err := os.WriteFile(pkg, []byte(`{"devDependencies": {"vitest": "1"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.GetRunnerName(testFile); got != "jest" {
+		t.Errorf("Expected the cached runner until a config change, got %q", got)
+	}
+
+	e.handleConfigChange(pkg)
+	if got := e.GetRunnerName(testFile); got != "vitest" {
+		t.Errorf("Expected vitest after the config change, got %q", got)
+	}
+}
+
+// TestConfigChange_ExtendedTSConfig verifies that a tsconfig base not named
+// tsconfig.* is handled as a config file, also when the graph came from the
+// cache and never read it.
+func TestConfigChange_ExtendedTSConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(t.TempDir(), "graph.json")
+	base := filepath.Join(tmpDir, "configs", "base.json")
+	if err := os.MkdirAll(filepath.Dir(base), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		filepath.Join(tmpDir, "tsconfig.json"): `{"extends": "./configs/base.json"}`,
+		base:                                   `{"compilerOptions": {"baseUrl": ".."}}`,
+		filepath.Join(tmpDir, "app.test.ts"):   "import 'lib';",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	analysis.InvalidateTSConfigCache(tmpDir)
+
+	e := New(tmpDir)
+	e.SetGraphCache(cachePath)
+	e.buildGraph()
+	if !e.isConfigFile(base) {
+		t.Fatalf("Expected %s to be a config file once the graph read it", base)
+	}
+
+	analysis.InvalidateTSConfigCache(tmpDir)
+	e = New(tmpDir)
+	e.SetGraphCache(cachePath)
+	e.buildGraph()
+	if !e.isConfigFile(base) {
+		t.Errorf("Expected %s to be a config file when the graph was loaded from the cache", base)
+	}
+
+	e.Update(WatcherMsg(base))
+	if !e.State.IsBuildingGraph {
+		t.Error("Expected an edit to the base to rebuild the graph")
+	}
+}
+
+func TestGraphCache_PersistsAcrossLaunches(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(t.TempDir(), "graph.json")
+
+	testFile := filepath.Join(tmpDir, "app.test.ts")
+	for name, content := range map[string]string{
+		"a.ts":        "export const a = 1;",
+		"b.ts":        "export const b = 1;",
+		"app.test.ts": "import { a } from './a';",
+	} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := New(tmpDir)
+	e.SetGraphCache(cachePath)
+	e.buildGraph()
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("Expected the graph cache to be saved after the build: %v", err)
+	}
+
+	// An edit during the session is saved on Close.
+	if err := os.WriteFile(testFile, []byte("import { b } from './b';"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e.Graph.Update(testFile)
+	e.Close()
+
+	next := New(tmpDir)
+	next.SetGraphCache(cachePath)
+	next.buildGraph()
+	deps := next.Graph.Forward[testFile]
+	if _, ok := deps[filepath.Join(tmpDir, "b.ts")]; !ok || len(deps) != 1 {
+		t.Errorf("Expected app.test.ts to depend on b.ts only, got %v", deps)
+	}
+}
+
+func TestDeletedModule_QueuesImporters(t *testing.T) {
+	tmpDir := t.TempDir()
+	module := filepath.Join(tmpDir, "format.ts")
+	testFile := filepath.Join(tmpDir, "format.test.ts")
+	if err := os.WriteFile(module, []byte("export const format = 1;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testFile, []byte("import { format } from './format';"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New(tmpDir)
+	e.ProjectConfig.MaxConcurrentTests = 0
+	e.Graph.Build(tmpDir)
+	e.ToggleSmartMode()
+
+	if err := os.Remove(module); err != nil {
+		t.Fatal(err)
+	}
+	flushCmds(e, e.Update(WatcherMsg(module)))
+
+	if len(e.State.Queue) != 1 || e.State.Queue[0] != testFile {
+		t.Errorf("Expected importing test %s to be queued, got %v", testFile, e.State.Queue)
+	}
+	if _, ok := e.Graph.Forward[testFile][module]; ok {
+		t.Errorf("Expected the edge to the deleted module to be removed")
+	}
+}
+
+func TestRename_KeepsWatchAndQueuesImporters(t *testing.T) {
+	tmpDir := t.TempDir()
+	module := filepath.Join(tmpDir, "format.ts")
+	renamedModule := filepath.Join(tmpDir, "formatting.ts")
+	testFile := filepath.Join(tmpDir, "format.test.ts")
+	renamedTest := filepath.Join(tmpDir, "formatting.test.ts")
+	if err := os.WriteFile(module, []byte("export const format = 1;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testFile, []byte("import { format } from './format';"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New(tmpDir)
+	e.ProjectConfig.MaxConcurrentTests = 0
+	e.Graph.Build(tmpDir)
+	e.ToggleWatch(testFile)
+
+	// The watched test is renamed: it stays watched under its new name.
+	if err := os.Rename(testFile, renamedTest); err != nil {
+		t.Fatal(err)
+	}
+	flushCmds(e, e.Update(RenameMsg{From: testFile, To: renamedTest}))
+	if e.IsWatched(testFile) || !e.IsWatched(renamedTest) {
+		t.Errorf("Expected the watch to follow the rename, watched: %v", e.State.Watched)
+	}
+	if _, ok := e.Graph.Forward[testFile]; ok {
+		t.Errorf("Expected the old test path to be removed from the graph")
+	}
+	e.State.Queue = nil
+
+	// The module it imports is renamed: the test is about to break.
+	if err := os.Rename(module, renamedModule); err != nil {
+		t.Fatal(err)
+	}
+	flushCmds(e, e.Update(RenameMsg{From: module, To: renamedModule}))
+	if len(e.State.Queue) != 1 || e.State.Queue[0] != renamedTest {
+		t.Errorf("Expected importing test %s to be queued, got %v", renamedTest, e.State.Queue)
+	}
+	if _, ok := e.Graph.Forward[renamedModule]; !ok {
+		t.Errorf("Expected the renamed module to be parsed into the graph")
+	}
+}
+
+func TestSmartMode_ExplainsAffectedTests(t *testing.T) {
+	tmpDir := t.TempDir()
+	for name, content := range map[string]string{
+		"package.json":   `{"scripts": {"test": "jest"}}`,
+		".lazytest.json": `{"command": "true"}`,
+		"format.ts":      "export const format = 1;",
+		"price.ts":       "export * from './format';",
+		"price.test.ts":  "import { price } from './price';",
+		"mocked.test.ts": "import { price } from './price';\njest.mock('./price');",
+	} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testFile := filepath.Join(tmpDir, "price.test.ts")
+
+	e := New(tmpDir)
+	e.ProjectConfig.MaxConcurrentTests = 0
+	e.Graph.Build(tmpDir)
+	e.ToggleSmartMode()
+	flushCmds(e, e.Update(WatcherMsg(filepath.Join(tmpDir, "format.ts"))))
+
+	want := "Affected: format.ts changed\n" +
+		"  format.ts\n" +
+		"  ← price.ts (re-export)\n" +
+		"  ← price.test.ts (import)\n" +
+		"Stopped at mocks:\n" +
+		"  mocked.test.ts mocks price.ts\n"
+	got, ok := e.ExplainAffected(testFile)
+	if !ok || got != want {
+		t.Errorf("ExplainAffected = %q, %v; want %q", got, ok, want)
+	}
+	if _, ok := e.ExplainAffected(filepath.Join(tmpDir, "mocked.test.ts")); ok {
+		t.Errorf("Expected no explanation for a test that was not queued")
+	}
+
+	// The explanation heads the output of the run it queued, and only that one.
+	e.TriggerTest(filesystem.NodeFromPath(testFile))
+	if out, _ := e.GetTestOutput(testFile); !strings.HasPrefix(out, want+"\nRunning price.test.ts...") {
+		t.Errorf("Expected the explanation above the run output, got %q", out)
+	}
+	e.TriggerTest(filesystem.NodeFromPath(testFile))
+	if out, _ := e.GetTestOutput(testFile); !strings.HasPrefix(out, "Running price.test.ts...") {
+		t.Errorf("Expected a manual rerun without the explanation, got %q", out)
+	}
+}
+
+// TestUpdateSnapshots verifies the update run is tracked like a normal run.
+func TestUpdateSnapshots(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte(`{"devDependencies": {"jest": "29"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".lazytest.json"), []byte(`{"command": "echo"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testFile := filepath.Join(tmpDir, "a.test.js")
+
+	e := New(tmpDir)
+	if cmd := e.UpdateSnapshots(testFile); cmd == nil {
+		t.Fatal("Expected UpdateSnapshots to return a command")
+	}
+	if status, _ := e.GetNodeStatus(testFile); status != StatusRunning {
+		t.Errorf("Expected status Running, got %v", status)
+	}
+	out, _ := e.GetTestOutput(testFile)
+	if !strings.Contains(out, "Updating snapshots for a.test.js") {
+		t.Errorf("Expected update header in output, got %q", out)
+	}
+}
+
+// TestUpdateSnapshots_UnsupportedRunner verifies that -u is only passed to
+// Jest and Vitest.
+func TestUpdateSnapshots_UnsupportedRunner(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte(`{"devDependencies": {"mocha": "10"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testFile := filepath.Join(tmpDir, "a.test.js")
+
+	e := New(tmpDir)
+	if e.CanUpdateSnapshots(testFile) {
+		t.Fatal("Expected mocha not to support snapshot updates")
+	}
+	msg := e.UpdateSnapshots(testFile)()
+	if note, ok := msg.(NotificationMsg); !ok || !note.IsError {
+		t.Errorf("Expected an error notification, got %#v", msg)
+	}
+	if _, running := e.State.RunningNodes[testFile]; running {
+		t.Error("Expected no run to start")
+	}
+}
+
+// TestSuiteHooks verifies a suite spans every test until the runner is idle
+// and that failing tests fire both test and suite hooks.
+func TestSuiteHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts use sh")
+	}
+	tmpDir := t.TempDir()
+	e := New(tmpDir)
+	e.ProjectConfig.MaxConcurrentTests = 0
+	e.ProjectConfig.Hooks = runner.Hooks{
+		OnTestFail:  `echo "$LAZYTEST_PATH" >> failed.txt`,
+		OnSuiteFail: `cat > suite.json`,
+	}
+
+	start := time.Now().Add(-2 * time.Second)
+	e.State.Suite.Started = start
+	for _, path := range []string{"/p/a.test.js", "/p/b.test.js"} {
+		e.State.RunningNodes[path] = filesystem.NodeFromPath(path)
+		e.State.StartedAt[path] = start
+	}
+
+	e.Update(runner.StatusUpdate{FilePath: "/p/a.test.js"})
+	if e.State.Suite.Started.IsZero() {
+		t.Fatal("Expected the suite to continue while a test is running")
+	}
+	if e.State.Durations["/p/a.test.js"] < 2*time.Second {
+		t.Errorf("Expected the test duration to be recorded, got %v", e.State.Durations["/p/a.test.js"])
+	}
+
+	runHookCmd(e.recordResult("/p/b.test.js", false))
+	if e.State.Suite.Passed != 1 || e.State.Suite.Failed != 1 {
+		t.Fatalf("Unexpected suite counts %+v", e.State.Suite)
+	}
+	runHookCmd(e.finishSuite())
+	if !e.State.Suite.Started.IsZero() {
+		t.Error("Expected the suite to be reset")
+	}
+
+	failed, _ := os.ReadFile(filepath.Join(tmpDir, "failed.txt"))
+	if string(failed) != "/p/b.test.js\n" {
+		t.Errorf("Expected on_test_fail for b only, got %q", failed)
+	}
+	var ev runner.HookEvent
+	data, _ := os.ReadFile(filepath.Join(tmpDir, "suite.json"))
+	if err := json.Unmarshal(data, &ev); err != nil {
+		t.Fatalf("Expected on_suite_fail JSON, got %q", data)
+	}
+	if ev.Event != runner.EventSuiteFail || ev.Total != 2 || ev.Failed != 1 || ev.DurationMs < 2000 {
+		t.Errorf("Unexpected suite event %+v", ev)
+	}
+}
+
+// runHookCmd runs cmd and any batched commands synchronously.
+func runHookCmd(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		for _, c := range batch {
+			runHookCmd(c)
+		}
+	}
+}
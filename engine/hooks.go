@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/history"
+	"github.com/jesspatton/lazytest/runner"
+)
+
+// SuiteRun tracks the tests finished since the runner was last idle. A suite
+// starts with the first test triggered from idle and completes when the
+// queue drains, so a Smart Mode rerun of 40 tests is one suite.
+type SuiteRun struct {
+	Started time.Time // Zero while idle
+	Passed  int
+	Failed  int
+}
+
+// recordResult stores a finished test's duration, adds it to the run
+// history, counts it toward the current suite and returns its
+// on_test_pass/on_test_fail hook.
+func (e *Engine) recordResult(path string, passed bool) tea.Cmd {
+	var duration time.Duration
+	if started, ok := e.State.StartedAt[path]; ok {
+		duration = time.Since(started)
+		e.State.Durations[path] = duration
+		delete(e.State.StartedAt, path)
+	}
+
+	e.History.Add(history.Run{Path: path, Passed: passed, DurationMs: duration.Milliseconds(), Time: time.Now()})
+
+	ev := runner.HookEvent{Path: path, DurationMs: duration.Milliseconds()}
+	command := e.ProjectConfig.Hooks.OnTestPass
+	if passed {
+		e.State.Suite.Passed++
+		ev.Event, ev.Status = runner.EventTestPass, "pass"
+	} else {
+		e.State.Suite.Failed++
+		ev.Event, ev.Status = runner.EventTestFail, "fail"
+		command = e.ProjectConfig.Hooks.OnTestFail
+	}
+	ev.Passed, ev.Failed = e.State.Suite.Passed, e.State.Suite.Failed
+	ev.Total = ev.Passed + ev.Failed
+	return tea.Batch(e.flushHistory(), e.runHook(command, ev))
+}
+
+// flushHistory writes the recorded runs to the history file in the
+// background. Concurrent flushes are serialized by the store, and whichever
+// runs first writes every pending run.
+func (e *Engine) flushHistory() tea.Cmd {
+	store := e.History
+	return func() tea.Msg {
+		if err := store.Flush(); err != nil {
+			return NotificationMsg{Message: fmt.Sprintf("Failed to record run history: %v", err), IsError: true}
+		}
+		return nil
+	}
+}
+
+// finishSuite ends the current suite, if any, and returns its
+// on_suite_complete and on_suite_fail hooks, desktop notification and bell.
+func (e *Engine) finishSuite() tea.Cmd {
+	suite := e.State.Suite
+	if suite.Started.IsZero() {
+		return nil
+	}
+	e.State.Suite = SuiteRun{}
+
+	ev := runner.HookEvent{
+		Event:      runner.EventSuiteComplete,
+		Status:     "pass",
+		DurationMs: time.Since(suite.Started).Milliseconds(),
+		Passed:     suite.Passed,
+		Failed:     suite.Failed,
+		Total:      suite.Passed + suite.Failed,
+	}
+	if suite.Failed > 0 {
+		ev.Status = "fail"
+	}
+
+	hooks := e.ProjectConfig.Hooks
+	cmds := []tea.Cmd{e.runHook(hooks.OnSuiteComplete, ev)}
+	if suite.Failed > 0 {
+		failEv := ev
+		failEv.Event = runner.EventSuiteFail
+		cmds = append(cmds, e.runHook(hooks.OnSuiteFail, failEv))
+	}
+	if hooks.Notify {
+		cmds = append(cmds, desktopNotify(ev))
+	}
+	if hooks.Bell {
+		cmds = append(cmds, ringBell)
+	}
+	return tea.Batch(cmds...)
+}
+
+// runHook runs command in the background, reporting a failure as an error
+// notification. An empty command is a no-op.
+func (e *Engine) runHook(command string, ev runner.HookEvent) tea.Cmd {
+	if command == "" {
+		return nil
+	}
+	dir := e.State.RootPath
+	return func() tea.Msg {
+		if err := runner.RunHook(command, dir, ev); err != nil {
+			return NotificationMsg{Message: err.Error(), IsError: true}
+		}
+		return nil
+	}
+}
+
+func desktopNotify(ev runner.HookEvent) tea.Cmd {
+	title := "✅ Tests passed"
+	if ev.Failed > 0 {
+		title = "❌ Tests failed"
+	}
+	return func() tea.Msg {
+		if err := runner.DesktopNotify(title, ev.Summary(), ev.Failed > 0); err != nil {
+			return NotificationMsg{Message: err.Error(), IsError: true}
+		}
+		return nil
+	}
+}
+
+// ringBell writes BEL to stderr, which reaches the terminal without going
+// through the TUI renderer.
+func ringBell() tea.Msg {
+	os.Stderr.WriteString("\a")
+	return nil
+}
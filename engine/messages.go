@@ -1,12 +1,18 @@
 package engine
 
-import "github.com/jesspatton/lazytest/filesystem"
+import (
+	"github.com/jesspatton/lazytest/analysis"
+	"github.com/jesspatton/lazytest/filesystem"
+)
 
 // Messages
 
 // WatcherMsg indicates a file system event occurred.
 type WatcherMsg string
 
+// RenameMsg indicates a file was moved or renamed.
+type RenameMsg filesystem.Rename
+
 // TreeLoadedMsg carries the new file tree after a refresh.
 type TreeLoadedMsg *filesystem.Node
 
@@ -38,6 +44,11 @@ type GraphBuildCompleteMsg struct {
 
 // GraphUpdateCompleteMsg indicates the dependency graph has finished updating for a file.
 type GraphUpdateCompleteMsg struct {
-	SourcePath string
+	SourcePath string // Empty when the file was deleted
+
+	// RemovedPath is a deleted or renamed-away file removed from the graph,
+	// and Removed how the change reached the files that depended on it.
+	RemovedPath string
+	Removed     *analysis.Explanation
 }
 
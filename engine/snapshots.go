@@ -0,0 +1,36 @@
+package engine
+
+import (
+	"sort"
+
+	"github.com/jesspatton/lazytest/filesystem"
+	"github.com/jesspatton/lazytest/output"
+)
+
+// SnapshotEntry describes the snapshot results of one test file's last run.
+type SnapshotEntry struct {
+	TestPath     string
+	SnapshotPath string
+	Report       output.SnapshotReport
+}
+
+// GetSnapshotEntries parses the stored output of every test that has run and
+// returns those whose snapshots failed, were written, or are obsolete, sorted
+// by test path.
+func (e *Engine) GetSnapshotEntries() []SnapshotEntry {
+	var entries []SnapshotEntry
+	for path := range e.State.TestOutputs {
+		out, _ := e.GetTestOutput(path)
+		report := output.ParseSnapshotReport(out)
+		if !report.HasIssues() {
+			continue
+		}
+		entries = append(entries, SnapshotEntry{
+			TestPath:     path,
+			SnapshotPath: filesystem.SnapshotPath(path),
+			Report:       report,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].TestPath < entries[j].TestPath })
+	return entries
+}
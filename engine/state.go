@@ -1,7 +1,10 @@
 package engine
 
 import (
+	"time"
+
 	"github.com/jesspatton/lazytest/filesystem"
+	"github.com/jesspatton/lazytest/terminal"
 )
 
 // TestStatus represents the current state of a test file.
@@ -21,20 +24,26 @@ const (
 // State represents the core business state of the application.
 type State struct {
 	// Data
-	Tree     *filesystem.Node
-	Watched  map[string]struct{}
-	Affected map[string]struct{}
+	Tree           *filesystem.Node
+	Watched        map[string]struct{}
+	Affected       map[string]struct{}
 	SortedAffected []string
+	AffectedBy     map[string]AffectedReason // Why Smart Mode last queued each test
 
 	// Test Execution State
 	Queue       []string
 	NodeStatus  map[string]TestStatus
 	TestOutputs map[string][]string
+	TestStderr  map[string][]string         // Lines written to stderr, subset of TestOutputs
+	Screens     map[string]*terminal.Screen // Output of PTY runs, used instead of TestOutputs
 
 	// Live State
 	RunningNodes map[string]*filesystem.Node
 	LastRunNode  *filesystem.Node
 	RootPath     string
+	StartedAt    map[string]time.Time     // Start of each running test
+	Durations    map[string]time.Duration // Duration of each test's last run
+	Suite        SuiteRun                 // Tests finished since the runner was last idle
 
 	// Mode
 	SmartMode bool // If true, automatically queue all affected test files on file change
@@ -47,13 +56,18 @@ type State struct {
 // NewState creates a new State instance.
 func NewState(rootPath string) State {
 	return State{
-		RootPath:     rootPath,
-		NodeStatus:   make(map[string]TestStatus),
-		TestOutputs:  make(map[string][]string),
+		RootPath:       rootPath,
+		NodeStatus:     make(map[string]TestStatus),
+		TestOutputs:    make(map[string][]string),
+		TestStderr:     make(map[string][]string),
+		Screens:        make(map[string]*terminal.Screen),
 		Watched:        make(map[string]struct{}),
 		Affected:       make(map[string]struct{}),
 		SortedAffected: make([]string, 0),
+		AffectedBy:     make(map[string]AffectedReason),
 		Queue:          make([]string, 0),
-		RunningNodes: make(map[string]*filesystem.Node),
+		RunningNodes:   make(map[string]*filesystem.Node),
+		StartedAt:      make(map[string]time.Time),
+		Durations:      make(map[string]time.Duration),
 	}
 }
@@ -68,6 +68,22 @@ func (e *Engine) GetSuiteStats() (passed, failed, running int) {
 	return
 }
 
+// GetStatusCounts returns the count of passed, failed, and running tests
+// among paths, e.g. every test file beneath an explorer directory.
+func (e *Engine) GetStatusCounts(paths []string) (passed, failed, running int) {
+	for _, path := range paths {
+		switch e.State.NodeStatus[path] {
+		case StatusPass:
+			passed++
+		case StatusFail:
+			failed++
+		case StatusRunning:
+			running++
+		}
+	}
+	return
+}
+
 // ClearAffectedSuite removes all passing (StatusPass) and idle/unrun tests
 // from State.Affected, keeping only failing and currently running entries.
 func (e *Engine) ClearAffectedSuite() {
@@ -106,6 +122,16 @@ func (e *Engine) RunAffectedSuite() tea.Cmd {
 	return e.enqueueNodes(nodes)
 }
 
+// EnqueueTests queues every path for execution, respecting the
+// MaxConcurrentTests limit. Paths already queued or running are skipped.
+func (e *Engine) EnqueueTests(paths []string) tea.Cmd {
+	nodes := make([]*filesystem.Node, 0, len(paths))
+	for _, path := range paths {
+		nodes = append(nodes, filesystem.NodeFromPath(path))
+	}
+	return e.enqueueNodes(nodes)
+}
+
 // enqueueNodes appends nodes to the queue (deduplicating) and triggers the
 // first one if the runner is currently idle.
 func (e *Engine) enqueueNodes(nodes []*filesystem.Node) tea.Cmd {
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jesspatton/lazytest/analysis"
+)
+
+// AffectedReason records why Smart Mode queued a test.
+type AffectedReason struct {
+	Changed string         // The file whose change queued the test
+	Removed bool           // Changed was deleted or renamed away
+	Chain   []analysis.Hop // Imports from Changed to the test; empty if the test itself changed
+	Pruned  []analysis.Hop // Mocked imports the change stopped at
+
+	shown bool // Written as the header of a run's output
+}
+
+// recordReasons stores why each of tests is affected by the change x
+// explains.
+func (e *Engine) recordReasons(tests []string, x *analysis.Explanation, removed bool) {
+	for _, test := range tests {
+		chain, ok := x.Chain(test)
+		if !ok {
+			continue
+		}
+		e.State.AffectedBy[test] = AffectedReason{
+			Changed: x.Changed,
+			Removed: removed,
+			Chain:   chain,
+			Pruned:  x.Pruned,
+		}
+	}
+}
+
+// ExplainAffected describes why Smart Mode last queued the test at path: the
+// import chain from the changed file, and the mocks that kept the change
+// from other tests. ok is false if it was never queued by a change.
+func (e *Engine) ExplainAffected(path string) (string, bool) {
+	reason, ok := e.State.AffectedBy[path]
+	if !ok {
+		return "", false
+	}
+
+	var b strings.Builder
+	if reason.Removed {
+		fmt.Fprintf(&b, "Affected: %s was removed\n", e.relPath(reason.Changed))
+	} else {
+		fmt.Fprintf(&b, "Affected: %s changed\n", e.relPath(reason.Changed))
+	}
+	if len(reason.Chain) > 0 {
+		fmt.Fprintf(&b, "  %s\n", e.relPath(reason.Changed))
+		for _, hop := range reason.Chain {
+			fmt.Fprintf(&b, "  ← %s (%s)\n", e.relPath(hop.File), hop.Kind)
+		}
+	}
+	if len(reason.Pruned) > 0 {
+		b.WriteString("Stopped at mocks:\n")
+		for _, hop := range reason.Pruned {
+			fmt.Fprintf(&b, "  %s mocks %s\n", e.relPath(hop.File), e.relPath(hop.Dependency))
+		}
+	}
+	return b.String(), true
+}
+
+// reasonHeader returns the explanation to put above the output of path's
+// next run, once per time Smart Mode queues it.
+func (e *Engine) reasonHeader(path string) string {
+	reason, ok := e.State.AffectedBy[path]
+	if !ok || reason.shown {
+		return ""
+	}
+	reason.shown = true
+	e.State.AffectedBy[path] = reason
+	text, _ := e.ExplainAffected(path)
+	return text + "\n"
+}
+
+// relPath returns path relative to the project root when it is inside it.
+func (e *Engine) relPath(path string) string {
+	if rel, err := filepath.Rel(e.State.RootPath, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
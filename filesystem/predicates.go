@@ -18,14 +18,38 @@ func IsTestFile(name string) bool {
 		strings.HasSuffix(name, ".test.mjs") ||
 		strings.HasSuffix(name, ".spec.mjs") ||
 		strings.HasSuffix(name, ".test.cjs") ||
-		strings.HasSuffix(name, ".spec.cjs")
+		strings.HasSuffix(name, ".spec.cjs") ||
+		strings.HasSuffix(name, ".test.mts") ||
+		strings.HasSuffix(name, ".spec.mts") ||
+		strings.HasSuffix(name, ".test.cts") ||
+		strings.HasSuffix(name, ".spec.cts")
 }
 
-// IsSourceFile checks if a file is a compilable source file.
+// scriptExts are the JavaScript and TypeScript file extensions.
+var scriptExts = []string{".ts", ".js", ".tsx", ".jsx", ".mjs", ".cjs", ".mts", ".cts"}
+
+// componentExts are the single-file component formats whose script blocks
+// import other modules.
+var componentExts = []string{".vue", ".svelte", ".astro"}
+
+// IsSourceFile checks if a file is a compilable source file: a script or a
+// single-file component.
 func IsSourceFile(name string) bool {
-	exts := []string{".ts", ".js", ".tsx", ".jsx", ".mjs", ".cjs"}
-	for _, ext := range exts {
-		if strings.HasSuffix(name, ext) {
+	return isScriptFile(name) || IsComponentFile(name)
+}
+
+// IsComponentFile checks if a file is a Vue, Svelte or Astro component.
+func IsComponentFile(name string) bool {
+	return hasAnySuffix(name, componentExts)
+}
+
+func isScriptFile(name string) bool {
+	return hasAnySuffix(name, scriptExts)
+}
+
+func hasAnySuffix(name string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
 			return true
 		}
 	}
@@ -84,8 +108,8 @@ func IsTestFileByPath(absPath string) bool {
 	for _, part := range parts {
 		for _, dir := range testDirs {
 			if part == dir {
-				// Must be a runnable source file (not json, yaml, md, …)
-				if !IsSourceFile(absPath) {
+				// Must be a runnable script (not json, yaml, md, components, …)
+				if !isScriptFile(absPath) {
 					return false
 				}
 				// Exclude helpers and fixtures.
@@ -112,7 +136,6 @@ func max(a, b int) int {
 // configBasenames are exact-match config file names.
 var configBasenames = []string{
 	"package.json",
-	"tsconfig.json",
 	".lazytest.json",
 	"pnpm-workspace.yaml",
 	".babelrc",
@@ -129,6 +152,7 @@ var configPrefixes = []string{
 	"webpack.config.",
 	"playwright.config.",
 	".mocharc.",
+	"tsconfig.", // tsconfig.json and the configs it extends or references
 }
 
 // IsConfigFile checks if a file is a configuration file that might affect tests.
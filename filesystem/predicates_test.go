@@ -36,6 +36,9 @@ func TestIsSourceFile(t *testing.T) {
 		{"tsx file", "foo.tsx", true},
 		{"jsx file", "foo.jsx", true},
 		{"test file", "foo.test.ts", true}, // Test files are also source files
+		{"vue component", "Button.vue", true},
+		{"svelte component", "Button.svelte", true},
+		{"astro component", "index.astro", true},
 		{"readme", "README.md", false},
 		{"json", "package.json", false},
 	}
@@ -79,6 +82,8 @@ func TestIsConfigFile(t *testing.T) {
 		{"mocharc yml", ".mocharc.yml", true},
 		{"mocharc json", ".mocharc.json", true},
 		{"mocharc js", ".mocharc.js", true},
+		{"tsconfig base", "tsconfig.base.json", true},
+		{"tsconfig app", "tsconfig.app.json", true},
 		// Full path — verify filepath.Base stripping
 		{"full path jest config", "/project/root/jest.config.ts", true},
 		// Non-config files
@@ -104,6 +109,8 @@ func TestIsSourceFileMjsCjs(t *testing.T) {
 	}{
 		{"mjs file", "foo.mjs", true},
 		{"cjs file", "foo.cjs", true},
+		{"mts file", "foo.mts", true},
+		{"cts file", "foo.cts", true},
 		{"ts file", "foo.ts", true},
 		{"md file", "README.md", false},
 	}
@@ -127,6 +134,8 @@ func TestIsTestFileMjsCjs(t *testing.T) {
 		{"mjs spec", "add.spec.mjs", true},
 		{"cjs test", "add.test.cjs", true},
 		{"cjs spec", "add.spec.cjs", true},
+		{"mts test", "add.test.mts", true},
+		{"cts spec", "add.spec.cts", true},
 		{"plain mjs", "add.mjs", false},
 	}
 
@@ -168,6 +177,7 @@ func TestIsTestFileByPath(t *testing.T) {
 		{"mockFactory compound", "/project/test/mockFactory.ts", false},
 		// Non-source files inside test dir
 		{"json in test dir", "/project/test/config.json", false},
+		{"component in test dir", "/project/test/Wrapper.vue", false},
 		// Normal source files outside test dir
 		{"normal source", "/project/src/math.ts", false},
 		{"readme", "/project/test/README.md", false},
@@ -0,0 +1,32 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// snapshotDir is the directory Jest and Vitest write snapshot files into,
+// next to the test file that owns them.
+const snapshotDir = "__snapshots__"
+
+// IsSnapshotFile checks if a file is a Jest/Vitest snapshot file.
+func IsSnapshotFile(name string) bool {
+	return strings.HasSuffix(name, ".snap")
+}
+
+// SnapshotPath returns the default snapshot file for a test file, e.g.
+// src/a.test.ts → src/__snapshots__/a.test.ts.snap.
+func SnapshotPath(testPath string) string {
+	return filepath.Join(filepath.Dir(testPath), snapshotDir, filepath.Base(testPath)+".snap")
+}
+
+// SnapshotOwner returns the test file that owns a snapshot file, e.g.
+// src/__snapshots__/a.test.ts.snap → src/a.test.ts. It returns an empty
+// string if the path is not inside a __snapshots__ directory.
+func SnapshotOwner(snapPath string) string {
+	dir := filepath.Dir(snapPath)
+	if filepath.Base(dir) != snapshotDir || !IsSnapshotFile(snapPath) {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(dir), strings.TrimSuffix(filepath.Base(snapPath), ".snap"))
+}
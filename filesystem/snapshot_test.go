@@ -0,0 +1,24 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotPaths(t *testing.T) {
+	testPath := filepath.Join("/repo", "src", "a.test.ts")
+	snapPath := filepath.Join("/repo", "src", "__snapshots__", "a.test.ts.snap")
+
+	if got := SnapshotPath(testPath); got != snapPath {
+		t.Errorf("SnapshotPath: expected %s, got %s", snapPath, got)
+	}
+	if got := SnapshotOwner(snapPath); got != testPath {
+		t.Errorf("SnapshotOwner: expected %s, got %s", testPath, got)
+	}
+	if got := SnapshotOwner(filepath.Join("/repo", "src", "a.test.ts.snap")); got != "" {
+		t.Errorf("Expected no owner outside __snapshots__, got %s", got)
+	}
+	if !IsSnapshotFile(snapPath) || IsSnapshotFile(testPath) {
+		t.Error("IsSnapshotFile misclassified a path")
+	}
+}
@@ -70,6 +70,18 @@ func (n *Node) Sort() {
 	}
 }
 
+// Files returns the paths of every file beneath n (or n itself if it is a
+// file), in tree order.
+func (n *Node) Files() []string {
+	if !n.IsDir {
+		return []string{n.Path}
+	}
+	var files []string
+	for _, child := range n.Children {
+		files = append(files, child.Files()...)
+	}
+	return files
+}
 
 func shouldExclude(path, root string, excludes []string) bool {
 	if len(excludes) == 0 {
@@ -137,3 +137,19 @@ func TestNodeFromPath(t *testing.T) {
 		t.Error("NodeFromPath IsDir: expected false")
 	}
 }
+
+func TestNodeFiles(t *testing.T) {
+	root := &Node{Path: "/r", IsDir: true, Children: []*Node{
+		{Path: "/r/sub", IsDir: true, Children: []*Node{{Path: "/r/sub/b.test.ts"}}},
+		{Path: "/r/a.test.ts"},
+	}}
+
+	got := root.Files()
+	want := []string{"/r/sub/b.test.ts", "/r/a.test.ts"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if files := (&Node{Path: "/r/a.test.ts"}).Files(); len(files) != 1 {
+		t.Errorf("Expected a file node to return itself, got %v", files)
+	}
+}
@@ -3,6 +3,7 @@ package filesystem
 import (
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -14,11 +15,14 @@ import (
 type Watcher struct {
 	fsWatcher    *fsnotify.Watcher
 	Events       chan string // Signal to refresh the tree, carries the changed file path
+	Renames      chan Rename // Files moved or renamed, reported instead of their two paths
 	Errors       chan error  // Channel for filesystem watcher errors
 	done         chan struct{}
 	root         string
 	mu           sync.Mutex
-	pendingPaths map[string]struct{}
+	pendingPaths map[string]fsnotify.Op
+	tracked      func(path string) bool // Extra files to report, guarded by mu
+	configs      func(path string) bool // Extra config files to report, guarded by mu
 }
 
 // NewWatcher creates a new Watcher for the given root directory.
@@ -31,10 +35,11 @@ func NewWatcher(root string) (*Watcher, error) {
 	w := &Watcher{
 		fsWatcher:    fsWatcher,
 		Events:       make(chan string, 100), // Increased buffer to handle batch events
+		Renames:      make(chan Rename, 100),
 		Errors:       make(chan error, 10),
 		done:         make(chan struct{}),
 		root:         root,
-		pendingPaths: make(map[string]struct{}),
+		pendingPaths: make(map[string]fsnotify.Op),
 	}
 
 	// Use gocodewalker to find all relevant directories to watch
@@ -68,6 +73,30 @@ func NewWatcher(root string) (*Watcher, error) {
 	return w, nil
 }
 
+// SetTracked makes the watcher also report changes to paths for which
+// tracked returns true, beyond source, config and snapshot files. It may be
+// called at any time to replace the previous function.
+func (w *Watcher) SetTracked(tracked func(path string) bool) {
+	w.mu.Lock()
+	w.tracked = tracked
+	w.mu.Unlock()
+}
+
+// SetConfigs makes the watcher also report changes to paths for which
+// configs returns true, such as tsconfig bases with names IsConfigFile does
+// not know, and treat them as config files rather than sources. It may be
+// called at any time to replace the previous function.
+func (w *Watcher) SetConfigs(configs func(path string) bool) {
+	w.mu.Lock()
+	w.configs = configs
+	w.mu.Unlock()
+}
+
+// isConfig reports whether path is a config file. The caller must hold mu.
+func (w *Watcher) isConfig(path string) bool {
+	return IsConfigFile(path) || w.configs != nil && w.configs(path)
+}
+
 // Close stops the watcher and releases resources.
 func (w *Watcher) Close() {
 	close(w.done)
@@ -105,14 +134,18 @@ func (w *Watcher) startLoop() {
 				}
 			}
 
-			// Allowlist: Only process events for source files, test files, and config files
-			if !IsSourceFile(event.Name) && !IsConfigFile(event.Name) {
-				continue
-			}
-
+			// Allowlist: Only process events for source files, test files, config files,
+			// snapshot files, and tracked files such as imported fixtures
 			w.mu.Lock()
-			w.pendingPaths[event.Name] = struct{}{}
+			allowed := IsSourceFile(event.Name) || w.isConfig(event.Name) || IsSnapshotFile(event.Name) ||
+				w.tracked != nil && w.tracked(event.Name)
+			if allowed {
+				w.pendingPaths[event.Name] |= event.Op
+			}
 			w.mu.Unlock()
+			if !allowed {
+				continue
+			}
 
 			// Safely reset timer
 			if !timer.Stop() {
@@ -134,16 +167,82 @@ func (w *Watcher) startLoop() {
 
 		case <-timer.C:
 			w.mu.Lock()
-			pathsToEmit := make([]string, 0, len(w.pendingPaths))
-			for path := range w.pendingPaths {
-				pathsToEmit = append(pathsToEmit, path)
+			pending := w.pendingPaths
+			w.pendingPaths = make(map[string]fsnotify.Op) // Reset for next batch
+			configs := make(map[string]bool)
+			for path := range pending {
+				configs[path] = w.isConfig(path)
 			}
-			w.pendingPaths = make(map[string]struct{}) // Reset for next batch
 			w.mu.Unlock()
 
-			for _, path := range pathsToEmit {
-				w.Events <- path
+			renamed := make(map[string]bool)
+			for _, rename := range pairRenames(pending, func(path string) bool { return configs[path] }) {
+				renamed[rename.From], renamed[rename.To] = true, true
+				w.Renames <- rename
 			}
+			for path := range pending {
+				if !renamed[path] {
+					w.Events <- path
+				}
+			}
+		}
+	}
+}
+
+// Rename is a file that moved from one path to another.
+type Rename struct {
+	From, To string
+}
+
+// pairRenames finds the renames in a batch of events: a file that is gone
+// after a remove or rename event, paired with a file created in the same
+// batch. A removed file pairs with a created file of the same name (moved to
+// another directory), or else with the only other unpaired file if it has
+// the same extension (renamed in place). Config files, as told by isConfig,
+// and snapshot files are left out, since their changes are handled as a
+// whole.
+func pairRenames(pending map[string]fsnotify.Op, isConfig func(path string) bool) []Rename {
+	var removed, created []string
+	for path, op := range pending {
+		if isConfig(path) || IsSnapshotFile(path) {
+			continue
+		}
+		_, err := os.Stat(path)
+		switch {
+		case op&(fsnotify.Remove|fsnotify.Rename) != 0 && os.IsNotExist(err):
+			removed = append(removed, path)
+		case op&fsnotify.Create != 0 && err == nil:
+			created = append(created, path)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(created)
+
+	var renames []Rename
+	paired := make(map[string]bool)
+	for _, from := range removed {
+		for _, to := range created {
+			if !paired[to] && filepath.Base(to) == filepath.Base(from) {
+				renames = append(renames, Rename{From: from, To: to})
+				paired[from], paired[to] = true, true
+				break
+			}
+		}
+	}
+	if len(removed)-len(renames) == 1 && len(created)-len(renames) == 1 {
+		from, to := unpaired(removed, paired), unpaired(created, paired)
+		if filepath.Ext(from) == filepath.Ext(to) {
+			renames = append(renames, Rename{From: from, To: to})
+		}
+	}
+	return renames
+}
+
+func unpaired(paths []string, paired map[string]bool) string {
+	for _, path := range paths {
+		if !paired[path] {
+			return path
 		}
 	}
+	return ""
 }
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/fsnotify/fsnotify"
 )
 
 func TestWatcher(t *testing.T) {
@@ -131,3 +134,147 @@ collect:
 		}
 	}
 }
+
+// TestWatcher_Tracked verifies that files outside the allowlist are reported
+// once the tracked function claims them.
+func TestWatcher_Tracked(t *testing.T) {
+	tmpDir := t.TempDir()
+	w, err := NewWatcher(tmpDir)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer w.Close()
+
+	fixture := filepath.Join(tmpDir, "data.json")
+	w.SetTracked(func(path string) bool { return path == fixture })
+	time.Sleep(100 * time.Millisecond)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "other.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fixture, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-w.Events:
+		if event != fixture {
+			t.Errorf("expected event for %s, got %s", fixture, event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for tracked file event")
+	}
+	select {
+	case event := <-w.Events:
+		t.Errorf("unexpected event for untracked file: %s", event)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+// TestWatcher_Configs verifies that files the configs function claims are
+// reported even though IsConfigFile does not know their names.
+func TestWatcher_Configs(t *testing.T) {
+	tmpDir := t.TempDir()
+	w, err := NewWatcher(tmpDir)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer w.Close()
+
+	base := filepath.Join(tmpDir, "base.json")
+	w.SetConfigs(func(path string) bool { return path == base })
+	time.Sleep(100 * time.Millisecond)
+
+	if err := os.WriteFile(base, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-w.Events:
+		if event != base {
+			t.Errorf("expected event for %s, got %s", base, event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for config file event")
+	}
+}
+
+// TestWatcher_Rename verifies that a renamed file is reported as one rename
+// rather than a removal and a creation.
+func TestWatcher_Rename(t *testing.T) {
+	tmpDir := t.TempDir()
+	from := filepath.Join(tmpDir, "old.ts")
+	to := filepath.Join(tmpDir, "new.ts")
+	if err := os.WriteFile(from, []byte("export {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher(tmpDir)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer w.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	if err := os.Rename(from, to); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case rename := <-w.Renames:
+		if rename != (Rename{From: from, To: to}) {
+			t.Errorf("expected rename %s -> %s, got %+v", from, to, rename)
+		}
+	case event := <-w.Events:
+		t.Errorf("unexpected event for %s instead of a rename", event)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for rename")
+	}
+}
+
+func TestPairRenames(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := func(name string) string { return filepath.Join(tmpDir, name) }
+	for _, name := range []string{"lib/moved.ts", "renamed.ts", "renamed.css", "edited.ts", "jest.config.ts"} {
+		if err := os.MkdirAll(filepath.Dir(path(name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path(name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		pending map[string]fsnotify.Op
+		want    []Rename
+	}{
+		{"moved to another directory", map[string]fsnotify.Op{
+			path("moved.ts"):     fsnotify.Rename,
+			path("lib/moved.ts"): fsnotify.Create,
+			path("gone.ts"):      fsnotify.Remove,
+		}, []Rename{{From: path("moved.ts"), To: path("lib/moved.ts")}}},
+		{"renamed in place", map[string]fsnotify.Op{
+			path("original.ts"): fsnotify.Rename,
+			path("renamed.ts"):  fsnotify.Create,
+		}, []Rename{{From: path("original.ts"), To: path("renamed.ts")}}},
+		{"different extension", map[string]fsnotify.Op{
+			path("original.ts"): fsnotify.Rename,
+			path("renamed.css"): fsnotify.Create,
+		}, nil},
+		{"replaced by an editor", map[string]fsnotify.Op{
+			path("edited.ts"): fsnotify.Rename | fsnotify.Create,
+		}, nil},
+		{"config files", map[string]fsnotify.Op{
+			path("jest.config.js"): fsnotify.Rename,
+			path("jest.config.ts"): fsnotify.Create,
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pairRenames(tt.pending, IsConfigFile); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Package history records finished test runs so statistics can span
+// sessions. Runs are appended to a JSON Lines file in the project's cache
+// directory.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxRuns caps the history file; older runs are dropped when it is opened.
+const maxRuns = 5000
+
+// Run is one finished execution of a test file.
+type Run struct {
+	Path       string    `json:"path"`
+	Passed     bool      `json:"passed"`
+	DurationMs int64     `json:"duration_ms"`
+	Time       time.Time `json:"time"`
+}
+
+// Duration returns the run's duration.
+func (r Run) Duration() time.Duration {
+	return time.Duration(r.DurationMs) * time.Millisecond
+}
+
+// Store holds the recorded runs, oldest first.
+type Store struct {
+	mu           sync.Mutex
+	path         string // Empty for a store that is not persisted
+	runs         []Run
+	pending      []Run // Added since the last Flush
+	sessionStart time.Time
+}
+
+// NewMemory returns a store that keeps runs for the current session only.
+func NewMemory() *Store {
+	return &Store{sessionStart: time.Now()}
+}
+
+// Open loads the history file at path, creating it on the first Flush. Lines
+// that cannot be parsed are skipped, and the file is compacted to the most
+// recent runs once it grows past the cap.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, sessionStart: time.Now()}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var run Run
+		if json.Unmarshal(scanner.Bytes(), &run) == nil && run.Path != "" {
+			s.runs = append(s.runs, run)
+		}
+	}
+	if len(s.runs) > maxRuns {
+		s.runs = s.runs[len(s.runs)-maxRuns:]
+		if err := s.rewrite(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add records a run in memory. It is written to the history file by the
+// next Flush, so callers on the UI goroutine never touch the disk.
+func (s *Store) Add(run Run) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs = append(s.runs, run)
+	if s.path != "" {
+		s.pending = append(s.pending, run)
+	}
+}
+
+// Flush appends the runs added since the last Flush to the history file.
+// Runs that fail to write stay pending and are retried by the next Flush.
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, run := range s.pending {
+		if err := enc.Encode(run); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	s.pending = nil
+	return nil
+}
+
+// Len returns the number of recorded runs. It only grows during a session,
+// so callers can use it to tell whether derived statistics are stale.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.runs)
+}
+
+// Runs returns every recorded run, oldest first.
+func (s *Store) Runs() []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Run(nil), s.runs...)
+}
+
+// SessionRuns returns the runs recorded since the store was opened.
+func (s *Store) SessionRuns() []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var runs []Run
+	for _, run := range s.runs {
+		if !run.Time.Before(s.sessionStart) {
+			runs = append(runs, run)
+		}
+	}
+	return runs
+}
+
+func (s *Store) rewrite() error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, run := range s.runs {
+		if err := enc.Encode(run); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(s.path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,82 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStore_PersistsAcrossOpens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "history.jsonl")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := Run{Path: "/p/a.test.ts", Passed: true, DurationMs: 120, Time: time.Now().Add(-time.Hour)}
+	s.Add(old)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Expected Add to leave the file alone until Flush, got %v", err)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Expected Flush to create the file, got %v", err)
+	}
+
+	// A torn write from a crash must not lose the rest of the history.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("{\"path\": \"/p/b.te\n")
+	f.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs := s.Runs(); len(runs) != 1 || runs[0].Path != old.Path || runs[0].DurationMs != 120 {
+		t.Fatalf("Expected the recorded run back, got %+v", runs)
+	}
+	if len(s.SessionRuns()) != 0 {
+		t.Error("Expected runs from earlier sessions to be excluded from the session")
+	}
+
+	s.Add(Run{Path: "/p/b.test.ts", Time: time.Now()})
+	if len(s.SessionRuns()) != 1 || len(s.Runs()) != 2 {
+		t.Errorf("Unexpected runs: session %v, all %v", s.SessionRuns(), s.Runs())
+	}
+}
+
+func TestStore_CompactsPastCap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	var b strings.Builder
+	for i := 0; i < maxRuns+10; i++ {
+		fmt.Fprintf(&b, "{\"path\": \"/p/%d.test.ts\"}\n", i)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs := s.Runs()
+	if len(runs) != maxRuns || runs[0].Path != "/p/10.test.ts" {
+		t.Fatalf("Expected the oldest runs to be dropped, got %d starting at %s", len(runs), runs[0].Path)
+	}
+	data, _ := os.ReadFile(path)
+	if lines := strings.Count(string(data), "\n"); lines != maxRuns {
+		t.Errorf("Expected the file to be rewritten with %d runs, got %d", maxRuns, lines)
+	}
+}
+
+func TestNewMemory_DoesNotWrite(t *testing.T) {
+	s := NewMemory()
+	s.Add(Run{Path: "/p/a.test.ts", Time: time.Now()})
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.SessionRuns()) != 1 {
+		t.Errorf("Expected the run in memory, got %v", s.Runs())
+	}
+}
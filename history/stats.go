@@ -0,0 +1,145 @@
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+// FileStats aggregates the runs of one test file.
+type FileStats struct {
+	Path     string
+	Runs     int
+	Failures int
+	Total    time.Duration
+	Last     time.Duration
+	Flips    int // Outcome changes between consecutive runs
+}
+
+// Average returns the mean run duration.
+func (f FileStats) Average() time.Duration {
+	if f.Runs == 0 {
+		return 0
+	}
+	return f.Total / time.Duration(f.Runs)
+}
+
+// Report summarizes a set of runs.
+type Report struct {
+	Runs      int
+	Failures  int
+	TimeSpent time.Duration
+	Files     []FileStats // Sorted by path
+}
+
+// Summarize aggregates runs, which must be oldest first, per file.
+func Summarize(runs []Run) Report {
+	var report Report
+	byPath := make(map[string]*FileStats)
+	lastPassed := make(map[string]bool)
+	for _, run := range runs {
+		report.Runs++
+		report.TimeSpent += run.Duration()
+
+		f, ok := byPath[run.Path]
+		if !ok {
+			f = &FileStats{Path: run.Path}
+			byPath[run.Path] = f
+		} else if lastPassed[run.Path] != run.Passed {
+			f.Flips++
+		}
+		lastPassed[run.Path] = run.Passed
+
+		f.Runs++
+		f.Total += run.Duration()
+		f.Last = run.Duration()
+		if !run.Passed {
+			f.Failures++
+			report.Failures++
+		}
+	}
+
+	report.Files = make([]FileStats, 0, len(byPath))
+	for _, f := range byPath {
+		report.Files = append(report.Files, *f)
+	}
+	sort.Slice(report.Files, func(i, j int) bool { return report.Files[i].Path < report.Files[j].Path })
+	return report
+}
+
+// Slowest returns up to n files with the highest average duration.
+func (r Report) Slowest(n int) []FileStats {
+	return r.top(n, func(f FileStats) bool { return f.Runs > 0 }, func(a, b FileStats) bool {
+		return a.Average() > b.Average()
+	})
+}
+
+// MostFailing returns up to n files with the most failed runs.
+func (r Report) MostFailing(n int) []FileStats {
+	return r.top(n, func(f FileStats) bool { return f.Failures > 0 }, func(a, b FileStats) bool {
+		return a.Failures > b.Failures
+	})
+}
+
+// Flaky returns up to n files whose outcome changed at least twice, i.e.
+// that failed and then passed again (or the reverse) more than once. They are
+// only candidates: the code may have changed between runs.
+func (r Report) Flaky(n int) []FileStats {
+	return r.top(n, func(f FileStats) bool { return f.Flips >= 2 }, func(a, b FileStats) bool {
+		return a.Flips > b.Flips
+	})
+}
+
+// top filters the files with keep and returns the first n ordered by less,
+// breaking ties by path.
+func (r Report) top(n int, keep func(FileStats) bool, less func(a, b FileStats) bool) []FileStats {
+	var files []FileStats
+	for _, f := range r.Files {
+		if keep(f) {
+			files = append(files, f)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool { return less(files[i], files[j]) })
+	if len(files) > n {
+		files = files[:n]
+	}
+	return files
+}
+
+// GroupStats is the pass rate of a group of files, e.g. a workspace.
+type GroupStats struct {
+	Name   string
+	Runs   int
+	Passes int
+}
+
+// PassRate returns the share of passing runs between 0 and 1.
+func (g GroupStats) PassRate() float64 {
+	if g.Runs == 0 {
+		return 0
+	}
+	return float64(g.Passes) / float64(g.Runs)
+}
+
+// GroupBy aggregates runs into groups named by group, sorted by name.
+func GroupBy(runs []Run, group func(path string) string) []GroupStats {
+	byName := make(map[string]*GroupStats)
+	for _, run := range runs {
+		name := group(run.Path)
+		g, ok := byName[name]
+		if !ok {
+			g = &GroupStats{Name: name}
+			byName[name] = g
+		}
+		g.Runs++
+		if run.Passed {
+			g.Passes++
+		}
+	}
+
+	groups := make([]GroupStats, 0, len(byName))
+	for _, g := range byName {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
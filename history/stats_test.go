@@ -0,0 +1,50 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func run(path string, passed bool, ms int64) Run {
+	return Run{Path: path, Passed: passed, DurationMs: ms}
+}
+
+func TestSummarize(t *testing.T) {
+	report := Summarize([]Run{
+		run("a", true, 100),
+		run("b", false, 2000),
+		run("a", false, 300),
+		run("c", true, 50),
+		run("a", true, 200),
+		run("b", false, 1000),
+	})
+
+	if report.Runs != 6 || report.Failures != 3 || report.TimeSpent != 3650*time.Millisecond {
+		t.Fatalf("Unexpected totals %+v", report)
+	}
+	if slowest := report.Slowest(2); len(slowest) != 2 || slowest[0].Path != "b" || slowest[0].Average() != 1500*time.Millisecond || slowest[1].Path != "a" {
+		t.Errorf("Unexpected slowest %+v", slowest)
+	}
+	if failing := report.MostFailing(5); len(failing) != 2 || failing[0].Path != "b" || failing[1].Failures != 1 {
+		t.Errorf("Unexpected most failing %+v", failing)
+	}
+	// a went pass -> fail -> pass; b failed consistently.
+	if flaky := report.Flaky(5); len(flaky) != 1 || flaky[0].Path != "a" || flaky[0].Flips != 2 {
+		t.Errorf("Unexpected flaky %+v", flaky)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	groups := GroupBy([]Run{
+		run("/r/web/a", true, 0),
+		run("/r/web/b", false, 0),
+		run("/r/api/c", true, 0),
+	}, func(path string) string { return path[3:6] })
+
+	if len(groups) != 2 || groups[0].Name != "api" || groups[1].Name != "web" {
+		t.Fatalf("Unexpected groups %+v", groups)
+	}
+	if groups[1].PassRate() != 0.5 || groups[0].PassRate() != 1 {
+		t.Errorf("Unexpected pass rates %+v", groups)
+	}
+}
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/config"
 	"github.com/jesspatton/lazytest/engine"
+	"github.com/jesspatton/lazytest/history"
 	"github.com/jesspatton/lazytest/ui"
 )
 
@@ -19,13 +22,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	var initialNotify string
+	var initialNotify, themeFlag string
 	var positionalArgs []string
 	for i := 1; i < len(os.Args); i++ {
 		arg := os.Args[i]
 		if arg == "--notify" && i+1 < len(os.Args) {
 			initialNotify = os.Args[i+1]
 			i++
+		} else if arg == "--theme" && i+1 < len(os.Args) {
+			themeFlag = os.Args[i+1]
+			i++
 		} else if !strings.HasPrefix(arg, "-") {
 			positionalArgs = append(positionalArgs, arg)
 		}
@@ -42,12 +48,50 @@ func main() {
 	}
 
 	eng := engine.New(targetDir)
+	historyErr := openHistory(eng, targetDir)
+	if dir, err := config.CacheDir(targetDir); err == nil {
+		eng.SetGraphCache(filepath.Join(dir, "graph.json"))
+	}
+	model := ui.NewModel(eng)
+
+	userConfig, err := config.Load()
+	if themeFlag != "" {
+		userConfig.Theme = themeFlag
+	}
+	err = errors.Join(err, model.ApplyUserConfig(userConfig))
+	if path, pathErr := config.Path(); pathErr == nil {
+		model.SetConfigPath(path)
+	}
+	if err != nil && initialNotify == "" {
+		initialNotify = fmt.Sprintf("User config: %v", err)
+	}
+
+	if historyErr != nil && initialNotify == "" {
+		initialNotify = fmt.Sprintf("Run history is not saved: %v", historyErr)
+	}
+
 	if initialNotify != "" {
 		eng.InitialNotification = initialNotify
 	}
-	p := tea.NewProgram(ui.NewModel(eng), tea.WithAltScreen())
+	p := tea.NewProgram(model, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}
+	eng.Close()
+}
+
+// openHistory replaces the engine's in-memory run history with the project's
+// history file in the cache directory.
+func openHistory(eng *engine.Engine, root string) error {
+	dir, err := config.CacheDir(root)
+	if err != nil {
+		return err
+	}
+	store, err := history.Open(filepath.Join(dir, "history.jsonl"))
+	if err != nil {
+		return err
+	}
+	eng.History = store
+	return nil
 }
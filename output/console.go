@@ -0,0 +1,49 @@
+package output
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// jest: "  console.log" header followed by an indented message and stack
+	jestConsoleRegex = regexp.MustCompile(`^\s*console\.(log|info|warn|error|debug|trace)\s*$`)
+	// vitest: "stdout | src/a.test.ts > suite > test" header followed by the
+	// logged lines up to a blank line
+	vitestConsoleRegex = regexp.MustCompile(`^(stdout|stderr) \| `)
+)
+
+// HideConsoleBlocks removes the console output blocks Jest and Vitest print
+// for console.log and friends, leaving the rest of the output untouched.
+func HideConsoleBlocks(text string) string {
+	lines, plain := splitLines(text)
+	out := make([]string, 0, len(lines))
+
+	for i := 0; i < len(lines); i++ {
+		p := plain[i]
+		switch {
+		case jestConsoleRegex.MatchString(p):
+			// Skip the header and everything indented deeper than it,
+			// including the blank lines Jest uses inside the block.
+			indent := indentOf(p)
+			for i+1 < len(plain) {
+				next := plain[i+1]
+				if strings.TrimSpace(next) != "" && indentOf(next) <= indent {
+					break
+				}
+				i++
+			}
+		case vitestConsoleRegex.MatchString(p):
+			// Skip the header and the logged lines up to the blank separator.
+			for i+1 < len(plain) {
+				i++
+				if strings.TrimSpace(plain[i]) == "" {
+					break
+				}
+			}
+		default:
+			out = append(out, lines[i])
+		}
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,294 @@
+package output
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// DiffLineKind classifies a line inside an assertion diff.
+type DiffLineKind int
+
+const (
+	// DiffContext is a line present on both sides.
+	DiffContext DiffLineKind = iota
+	// DiffExpected is a line only present in the expected value.
+	DiffExpected
+	// DiffReceived is a line only present in the received (actual) value.
+	DiffReceived
+)
+
+// DiffLine is a single line of an assertion diff with its marker removed.
+type DiffLine struct {
+	Kind DiffLineKind
+	Text string
+}
+
+// AssertionDiff is an expected/received diff parsed from a runner's failure
+// output.
+type AssertionDiff struct {
+	Title    string // The failing test the diff belongs to, if known
+	Snapshot string // Snapshot name when the diff is a snapshot mismatch
+	Lines    []DiffLine
+}
+
+// diffHeader describes a diff header format and which marker denotes the
+// expected side.
+type diffHeader struct {
+	regex          *regexp.Regexp
+	expectedMarker byte
+}
+
+var diffHeaders = []diffHeader{
+	// jest / vitest: "- Expected  - 1" followed by "+ Received  + 1"
+	{regexp.MustCompile(`^- Expected\b`), '-'},
+	// jest snapshot: "- Snapshot  - 1" followed by "+ Received  + 1"
+	{regexp.MustCompile(`^- Snapshot\b`), '-'},
+	// node assert: "+ actual - expected"
+	{regexp.MustCompile(`^\+ actual - expected\b`), '-'},
+	// chai (mocha): "+ expected - actual"
+	{regexp.MustCompile(`^\+ expected - actual\b`), '+'},
+}
+
+var (
+	// titleRegex matches lines naming a failing test across runners:
+	// jest "● a › b", vitest "FAIL  file > a > b", mocha/playwright "1) a",
+	// node "not ok 1 - a".
+	titleRegex = regexp.MustCompile(`^(● .+|FAIL\s+.+ > .+|\d+\) .+|not ok \d+ .+)$`)
+	// nodeSkipRegex matches node's "..." elision markers inside a diff body.
+	nodeSkipRegex = regexp.MustCompile(`^\.\.\.( Lines skipped)?$`)
+	// snapshotNameRegex matches the snapshot a mismatch belongs to:
+	// jest "Snapshot name: `a b 1`", vitest "Snapshot `a > b 1` mismatched".
+	snapshotNameRegex = regexp.MustCompile("(?:Snapshot name: `([^`]+)`|Snapshot `([^`]+)` mismatched)")
+)
+
+// ParseAssertionDiffs extracts every expected/received diff block from a test
+// run's output. Jest/Vitest `expect(...)` diffs, Node `assert` diffs and Chai
+// diffs are recognised. ANSI sequences are ignored.
+func ParseAssertionDiffs(text string) []AssertionDiff {
+	_, plain := splitLines(text)
+
+	var diffs []AssertionDiff
+	title, snapshot := "", ""
+	for i := 0; i < len(plain); i++ {
+		trimmed := strings.TrimSpace(plain[i])
+		if titleRegex.MatchString(trimmed) {
+			title, snapshot = trimmed, ""
+			continue
+		}
+		if match := snapshotNameRegex.FindStringSubmatch(trimmed); match != nil {
+			snapshot = match[1] + match[2]
+			continue
+		}
+
+		expectedMarker, ok := matchDiffHeader(trimmed)
+		if !ok {
+			continue
+		}
+		indent := indentOf(plain[i])
+
+		// jest/vitest print the "+ Received" half of the header on the next line.
+		if strings.HasPrefix(trimmed, "- ") && i+1 < len(plain) &&
+			strings.HasPrefix(strings.TrimSpace(plain[i+1]), "+ Received") {
+			i++
+		}
+
+		diff := AssertionDiff{Title: title, Snapshot: snapshot}
+		i = parseDiffBody(plain, i+1, indent, expectedMarker, &diff)
+		if len(diff.Lines) > 0 {
+			diffs = append(diffs, diff)
+		}
+		snapshot = ""
+	}
+	return diffs
+}
+
+// matchDiffHeader reports whether line opens a diff and which marker
+// character denotes expected lines.
+func matchDiffHeader(line string) (byte, bool) {
+	for _, h := range diffHeaders {
+		if h.regex.MatchString(line) {
+			return h.expectedMarker, true
+		}
+	}
+	return 0, false
+}
+
+// parseDiffBody reads diff lines starting at start until the block ends and
+// returns the index of the last consumed line.
+func parseDiffBody(plain []string, start, indent int, expectedMarker byte, diff *AssertionDiff) int {
+	i := start
+	// Skip the blank separator between header and body.
+	for i < len(plain) && strings.TrimSpace(plain[i]) == "" {
+		i++
+	}
+
+	for ; i < len(plain); i++ {
+		line := plain[i]
+		if strings.TrimSpace(line) == "" || indentOf(line) < indent || len(line) <= indent {
+			break
+		}
+		if nodeSkipRegex.MatchString(strings.TrimSpace(line)) {
+			continue
+		}
+
+		marker := line[indent]
+		text := line[indent+1:]
+		switch marker {
+		case '-', '+':
+			kind := DiffReceived
+			if marker == expectedMarker {
+				kind = DiffExpected
+			}
+			diff.Lines = append(diff.Lines, DiffLine{Kind: kind, Text: text})
+		case ' ':
+			diff.Lines = append(diff.Lines, DiffLine{Kind: DiffContext, Text: text})
+		default:
+			return i - 1
+		}
+	}
+	return i - 1
+}
+
+// DiffRow is one row of a side-by-side rendering. Left is the expected side,
+// Right the received side. A side is absent when HasLeft/HasRight is false.
+type DiffRow struct {
+	Left, Right       string
+	HasLeft, HasRight bool
+	Changed           bool // True when the row is not plain context
+}
+
+// Rows pairs the diff's lines for side-by-side display. Context lines appear
+// on both sides; each run of expected lines is paired row by row with the run
+// of received lines that follows it.
+func (d AssertionDiff) Rows() []DiffRow {
+	var rows []DiffRow
+	var expected, received []string
+
+	flush := func() {
+		n := len(expected)
+		if len(received) > n {
+			n = len(received)
+		}
+		for j := 0; j < n; j++ {
+			row := DiffRow{Changed: true}
+			if j < len(expected) {
+				row.Left, row.HasLeft = expected[j], true
+			}
+			if j < len(received) {
+				row.Right, row.HasRight = received[j], true
+			}
+			rows = append(rows, row)
+		}
+		expected, received = nil, nil
+	}
+
+	for _, line := range d.Lines {
+		switch line.Kind {
+		case DiffExpected:
+			if len(received) > 0 {
+				flush()
+			}
+			expected = append(expected, line.Text)
+		case DiffReceived:
+			received = append(received, line.Text)
+		default:
+			flush()
+			rows = append(rows, DiffRow{Left: line.Text, Right: line.Text, HasLeft: true, HasRight: true})
+		}
+	}
+	flush()
+	return rows
+}
+
+// Segment is a run of text within a line, marked if it differs from the
+// other side.
+type Segment struct {
+	Text    string
+	Changed bool
+}
+
+// WordDiff compares two lines word by word and returns the segments of each,
+// marking the words that are not part of their longest common subsequence.
+func WordDiff(a, b string) ([]Segment, []Segment) {
+	ta, tb := tokenize(a), tokenize(b)
+
+	// LCS table over tokens.
+	lcs := make([][]int, len(ta)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(tb)+1)
+	}
+	for i := len(ta) - 1; i >= 0; i-- {
+		for j := len(tb) - 1; j >= 0; j-- {
+			if ta[i] == tb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var sa, sb []Segment
+	i, j := 0, 0
+	for i < len(ta) && j < len(tb) {
+		switch {
+		case ta[i] == tb[j]:
+			sa = appendSegment(sa, ta[i], false)
+			sb = appendSegment(sb, tb[j], false)
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			sa = appendSegment(sa, ta[i], true)
+			i++
+		default:
+			sb = appendSegment(sb, tb[j], true)
+			j++
+		}
+	}
+	for ; i < len(ta); i++ {
+		sa = appendSegment(sa, ta[i], true)
+	}
+	for ; j < len(tb); j++ {
+		sb = appendSegment(sb, tb[j], true)
+	}
+	return sa, sb
+}
+
+// appendSegment adds text to segs, merging it into the last segment when the
+// changed state matches.
+func appendSegment(segs []Segment, text string, changed bool) []Segment {
+	if n := len(segs); n > 0 && segs[n-1].Changed == changed {
+		segs[n-1].Text += text
+		return segs
+	}
+	return append(segs, Segment{Text: text, Changed: changed})
+}
+
+// tokenize splits s into runs of word characters, runs of whitespace, and
+// single punctuation characters.
+func tokenize(s string) []string {
+	var tokens []string
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		j := i + 1
+		switch {
+		case isWordRune(runes[i]):
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+		case unicode.IsSpace(runes[i]):
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+		}
+		tokens = append(tokens, string(runes[i:j]))
+		i = j
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$'
+}
@@ -0,0 +1,108 @@
+package output
+
+import (
+	"testing"
+)
+
+func TestParseAssertionDiffs_Jest(t *testing.T) {
+	text := `  ● user › builds profile
+
+    expect(received).toEqual(expected) // deep equality
+
+    - Expected  - 1
+    + Received  + 1
+
+      Object {
+    -   "name": "Ada",
+    +   "name": "Bob",
+      }
+
+      at Object.<anonymous> (user.test.js:5:20)
+`
+	diffs := ParseAssertionDiffs(text)
+	if len(diffs) != 1 {
+		t.Fatalf("Expected 1 diff, got %d", len(diffs))
+	}
+	d := diffs[0]
+	if d.Title != "● user › builds profile" {
+		t.Errorf("Unexpected title %q", d.Title)
+	}
+
+	want := []DiffLine{
+		{DiffContext, ` Object {`},
+		{DiffExpected, `   "name": "Ada",`},
+		{DiffReceived, `   "name": "Bob",`},
+		{DiffContext, ` }`},
+	}
+	if len(d.Lines) != len(want) {
+		t.Fatalf("Expected %d lines, got %d: %#v", len(want), len(d.Lines), d.Lines)
+	}
+	for i := range want {
+		if d.Lines[i] != want[i] {
+			t.Errorf("Line %d: expected %#v, got %#v", i, want[i], d.Lines[i])
+		}
+	}
+}
+
+func TestParseAssertionDiffs_NodeAndChai(t *testing.T) {
+	node := "not ok 1 - compares\n  AssertionError [ERR_ASSERTION]: Expected values to be strictly deep-equal:\n  + actual - expected\n\n    {\n  +   a: 2\n  -   a: 1\n    }\n"
+	diffs := ParseAssertionDiffs(node)
+	if len(diffs) != 1 {
+		t.Fatalf("Expected 1 node diff, got %d", len(diffs))
+	}
+	if diffs[0].Lines[1].Kind != DiffReceived || diffs[0].Lines[2].Kind != DiffExpected {
+		t.Errorf("Node diff should map '+' to actual and '-' to expected: %#v", diffs[0].Lines)
+	}
+
+	chai := "  1) math\n       adds:\n\n      AssertionError: expected 2 to equal 3\n      + expected - actual\n\n      -2\n      +3\n"
+	diffs = ParseAssertionDiffs(chai)
+	if len(diffs) != 1 {
+		t.Fatalf("Expected 1 chai diff, got %d", len(diffs))
+	}
+	lines := diffs[0].Lines
+	if len(lines) != 2 || lines[0] != (DiffLine{DiffReceived, "2"}) || lines[1] != (DiffLine{DiffExpected, "3"}) {
+		t.Errorf("Chai diff should map '+' to expected and '-' to actual: %#v", lines)
+	}
+}
+
+func TestAssertionDiff_Rows(t *testing.T) {
+	d := AssertionDiff{Lines: []DiffLine{
+		{DiffContext, "{"},
+		{DiffExpected, "a: 1"},
+		{DiffExpected, "b: 1"},
+		{DiffReceived, "a: 2"},
+		{DiffContext, "}"},
+	}}
+	rows := d.Rows()
+	if len(rows) != 4 {
+		t.Fatalf("Expected 4 rows, got %d: %#v", len(rows), rows)
+	}
+	if rows[1].Left != "a: 1" || rows[1].Right != "a: 2" || !rows[1].Changed {
+		t.Errorf("Expected paired changed row, got %#v", rows[1])
+	}
+	if rows[2].Left != "b: 1" || rows[2].HasRight {
+		t.Errorf("Expected expected-only row, got %#v", rows[2])
+	}
+	if rows[3].Changed {
+		t.Errorf("Expected context row, got %#v", rows[3])
+	}
+}
+
+func TestWordDiff(t *testing.T) {
+	a, b := WordDiff(`"name": "Ada",`, `"name": "Bob",`)
+
+	var changedA, changedB string
+	for _, s := range a {
+		if s.Changed {
+			changedA += s.Text
+		}
+	}
+	for _, s := range b {
+		if s.Changed {
+			changedB += s.Text
+		}
+	}
+	if changedA != "Ada" || changedB != "Bob" {
+		t.Errorf("Expected only the names to differ, got %q / %q", changedA, changedB)
+	}
+}
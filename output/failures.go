@@ -0,0 +1,207 @@
+package output
+
+import (
+	"regexp"
+	"strings"
+)
+
+// extractor pulls the failure blocks out of a runner's output. lines holds the
+// original (possibly coloured) lines and plain the same lines with ANSI removed.
+type extractor func(lines, plain []string) []string
+
+// extractors maps a detected runner name to its failure block extractor.
+var extractors = map[string]extractor{
+	"jest":             jestFailures,
+	"vitest":           vitestFailures,
+	"mocha":            mochaFailures,
+	"@playwright/test": playwrightFailures,
+	"node":             nodeFailures,
+}
+
+// extractorOrder is the fallback order used when the detected runner's format
+// does not match the output (e.g. a custom command overrides the runner).
+var extractorOrder = []string{"jest", "vitest", "mocha", "@playwright/test", "node"}
+
+var (
+	// mocha: "  2 failing"
+	mochaFailingRegex = regexp.MustCompile(`^\s*\d+ failing`)
+	// playwright: "  1) [chromium] › example.spec.ts:3:5 › has title"
+	playwrightBlockRegex = regexp.MustCompile(`^\s*\d+\) \[`)
+	// playwright: "  1 failed", "  2 passed (3.1s)"
+	playwrightSummaryRegex = regexp.MustCompile(`^\s*\d+ (failed|flaky|passed|skipped|did not run)`)
+)
+
+// ExtractFailures returns only the failure blocks from a test run's output.
+// The format of runnerName is tried first; if it yields nothing, the other
+// known formats are tried in turn. An empty string means no failure block was
+// recognised.
+func ExtractFailures(text, runnerName string) string {
+	lines, plain := splitLines(text)
+
+	try := func(name string) string {
+		fn, ok := extractors[name]
+		if !ok {
+			return ""
+		}
+		return strings.Trim(strings.Join(fn(lines, plain), "\n"), "\n")
+	}
+
+	if out := try(runnerName); out != "" {
+		return out
+	}
+	for _, name := range extractorOrder {
+		if name == runnerName {
+			continue
+		}
+		if out := try(name); out != "" {
+			return out
+		}
+	}
+	return ""
+}
+
+// isEngineTrailer matches the status lines the engine appends once the process
+// exits ("PASS" / "FAIL: exit status 1"); they always end a failure block.
+func isEngineTrailer(plain string) bool {
+	return plain == "PASS" || strings.HasPrefix(plain, "FAIL: ")
+}
+
+// jestFailures collects the "● suite › test" blocks Jest prints for each
+// failing test or suite.
+func jestFailures(lines, plain []string) []string {
+	var out []string
+	in := false
+	for i, p := range plain {
+		trimmed := strings.TrimSpace(p)
+		switch {
+		case strings.HasPrefix(trimmed, "● Console"):
+			in = false
+		case strings.HasPrefix(trimmed, "● "):
+			if in {
+				out = append(out, "")
+			}
+			in = true
+		case isJestBoundary(p):
+			in = false
+		}
+		if in {
+			out = append(out, lines[i])
+		}
+	}
+	return out
+}
+
+// isJestBoundary matches the top-level lines Jest prints between failure
+// blocks: per-file status lines and the run summary.
+func isJestBoundary(plain string) bool {
+	for _, prefix := range []string{"PASS ", "FAIL ", "Test Suites:", "Tests:", "Snapshots:", "Time:", "Ran all test suites", "Summary of all failing tests"} {
+		if strings.HasPrefix(plain, prefix) {
+			return true
+		}
+	}
+	return isEngineTrailer(plain)
+}
+
+// vitestFailures collects the "⎯⎯ Failed Tests ⎯⎯" section (and any unhandled
+// error sections) that Vitest prints before its summary.
+func vitestFailures(lines, plain []string) []string {
+	var out []string
+	in := false
+	for i, p := range plain {
+		trimmed := strings.TrimSpace(p)
+		switch {
+		case strings.Contains(trimmed, "⎯") &&
+			(strings.Contains(trimmed, "Failed Tests") ||
+				strings.Contains(trimmed, "Failed Suites") ||
+				strings.Contains(trimmed, "Unhandled")):
+			in = true
+		case strings.HasPrefix(trimmed, "Test Files "), isEngineTrailer(p):
+			in = false
+		}
+		if in {
+			out = append(out, lines[i])
+		}
+	}
+	return out
+}
+
+// mochaFailures collects everything from Mocha's "N failing" line onwards.
+func mochaFailures(lines, plain []string) []string {
+	var out []string
+	in := false
+	for i, p := range plain {
+		if mochaFailingRegex.MatchString(p) {
+			in = true
+		} else if isEngineTrailer(p) {
+			in = false
+		}
+		if in {
+			out = append(out, lines[i])
+		}
+	}
+	return out
+}
+
+// playwrightFailures collects the numbered "1) [project] › file › test" blocks
+// Playwright's list reporter prints after the run.
+func playwrightFailures(lines, plain []string) []string {
+	var out []string
+	in := false
+	for i, p := range plain {
+		switch {
+		case playwrightBlockRegex.MatchString(p):
+			if in {
+				out = append(out, "")
+			}
+			in = true
+		case playwrightSummaryRegex.MatchString(p), isEngineTrailer(p):
+			in = false
+		}
+		if in {
+			out = append(out, lines[i])
+		}
+	}
+	return out
+}
+
+// nodeFailures handles `node --test`: the spec reporter's "✖ failing tests:"
+// section when present, otherwise each TAP "not ok" line with its indented
+// diagnostics.
+func nodeFailures(lines, plain []string) []string {
+	var out []string
+	in := false
+	for i, p := range plain {
+		if strings.HasPrefix(strings.TrimSpace(p), "✖ failing tests:") {
+			in = true
+		} else if isEngineTrailer(p) {
+			in = false
+		}
+		if in {
+			out = append(out, lines[i])
+		}
+	}
+	if len(out) > 0 {
+		return out
+	}
+
+	for i := 0; i < len(plain); i++ {
+		trimmed := strings.TrimSpace(plain[i])
+		if !strings.HasPrefix(trimmed, "not ok ") {
+			continue
+		}
+		if len(out) > 0 {
+			out = append(out, "")
+		}
+		indent := indentOf(plain[i])
+		out = append(out, lines[i])
+		for i+1 < len(plain) {
+			next := plain[i+1]
+			if strings.TrimSpace(next) != "" && indentOf(next) <= indent {
+				break
+			}
+			i++
+			out = append(out, lines[i])
+		}
+	}
+	return out
+}
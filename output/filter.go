@@ -0,0 +1,81 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Filter describes which view over a test's captured output is active.
+// The zero value shows the output unchanged.
+type Filter struct {
+	FailuresOnly bool // Keep only the runner's failure blocks
+	HideConsole  bool // Drop console.log / stdout | blocks emitted by the runner
+	StderrOnly   bool // Show only lines the process wrote to stderr
+	StripANSI    bool // Remove ANSI escape sequences
+}
+
+// Active returns true if at least one view is enabled.
+func (f Filter) Active() bool {
+	return f.FailuresOnly || f.HideConsole || f.StderrOnly || f.StripANSI
+}
+
+// Labels returns short human-readable names for the enabled views, in a
+// stable order suitable for a pane header.
+func (f Filter) Labels() []string {
+	var labels []string
+	if f.FailuresOnly {
+		labels = append(labels, "failures")
+	}
+	if f.HideConsole {
+		labels = append(labels, "no console")
+	}
+	if f.StderrOnly {
+		labels = append(labels, "stderr")
+	}
+	if f.StripANSI {
+		labels = append(labels, "plain")
+	}
+	return labels
+}
+
+// Apply renders the enabled views over a test's output. full is the complete
+// interleaved output, stderr holds only the lines written to stderr, and
+// runnerName is the detected runner (e.g. "jest", "vitest") used to pick the
+// failure block format.
+func (f Filter) Apply(full, stderr, runnerName string) string {
+	text := full
+	if f.StderrOnly {
+		text = stderr
+	}
+	if f.HideConsole {
+		text = HideConsoleBlocks(text)
+	}
+	if f.FailuresOnly {
+		text = ExtractFailures(text, runnerName)
+	}
+	if f.StripANSI {
+		text = StripANSI(text)
+	}
+	return text
+}
+
+// StripANSI removes all ANSI escape sequences from s.
+func StripANSI(s string) string {
+	return ansi.Strip(s)
+}
+
+// splitLines splits text into lines alongside their ANSI-stripped, for matching.
+func splitLines(text string) (lines, plain []string) {
+	lines = strings.Split(text, "\n")
+	plain = make([]string, len(lines))
+	for i, line := range lines {
+		plain[i] = ansi.Strip(strings.TrimRight(line, "\r"))
+	}
+	return lines, plain
+}
+
+// indentOf returns the number of leading spaces in s.
+func indentOf(s string) int {
+	return len(s) - len(strings.TrimLeft(s, " "))
+}
@@ -0,0 +1,154 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+const jestOutput = `Running sum.test.js...
+  console.log
+    debugging value 42
+
+      at Object.<anonymous> (sum.test.js:4:11)
+
+FAIL ./sum.test.js
+  math
+    ✓ adds (2 ms)
+    ✕ subtracts (3 ms)
+
+  ● math › subtracts
+
+    expect(received).toBe(expected) // Object.is equality
+
+    Expected: 1
+    Received: 2
+
+Test Suites: 1 failed, 1 total
+Tests:       1 failed, 1 passed, 2 total
+
+FAIL: exit status 1
+`
+
+const vitestOutput = ` RUN  v1.6.0 /repo
+
+stdout | src/a.test.ts > a > logs
+hello from test
+
+ ❯ src/a.test.ts (2)
+   × a > fails
+
+⎯⎯⎯⎯⎯⎯⎯ Failed Tests 1 ⎯⎯⎯⎯⎯⎯⎯
+
+ FAIL  src/a.test.ts > a > fails
+AssertionError: expected 1 to be 2
+
+⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯⎯[1/1]⎯
+
+ Test Files  1 failed (1)
+      Tests  1 failed | 1 passed (2)
+`
+
+func TestExtractFailures_Jest(t *testing.T) {
+	got := ExtractFailures(jestOutput, "jest")
+
+	if !strings.Contains(got, "● math › subtracts") {
+		t.Errorf("Expected failure header, got %q", got)
+	}
+	if !strings.Contains(got, "Received: 2") {
+		t.Errorf("Expected failure body, got %q", got)
+	}
+	for _, noise := range []string{"console.log", "✓ adds", "Test Suites:", "FAIL: exit status 1"} {
+		if strings.Contains(got, noise) {
+			t.Errorf("Expected %q to be filtered out, got %q", noise, got)
+		}
+	}
+}
+
+func TestExtractFailures_Vitest(t *testing.T) {
+	got := ExtractFailures(vitestOutput, "vitest")
+
+	if !strings.Contains(got, "FAIL  src/a.test.ts > a > fails") {
+		t.Errorf("Expected failing test block, got %q", got)
+	}
+	if strings.Contains(got, "hello from test") || strings.Contains(got, "Test Files") {
+		t.Errorf("Expected console output and summary to be excluded, got %q", got)
+	}
+}
+
+func TestExtractFailures_FallsBackToOtherFormats(t *testing.T) {
+	// Detected runner is vitest but the command was overridden to jest.
+	got := ExtractFailures(jestOutput, "vitest")
+	if !strings.Contains(got, "● math › subtracts") {
+		t.Errorf("Expected fallback to the Jest format, got %q", got)
+	}
+}
+
+func TestExtractFailures_Mocha(t *testing.T) {
+	text := "  adds\n    ✔ works\n\n  1 passing (5ms)\n  1 failing\n\n  1) math\n       subtracts:\n     AssertionError\n\nFAIL: exit status 1\n"
+	got := ExtractFailures(text, "mocha")
+	if !strings.HasPrefix(got, "  1 failing") || !strings.Contains(got, "AssertionError") {
+		t.Errorf("Unexpected mocha failures: %q", got)
+	}
+	if strings.Contains(got, "exit status") {
+		t.Errorf("Expected engine trailer to be excluded, got %q", got)
+	}
+}
+
+func TestExtractFailures_NodeTAP(t *testing.T) {
+	text := "ok 1 - adds\nnot ok 2 - subtracts\n  ---\n  error: 'boom'\n  ...\nok 3 - multiplies\n"
+	got := ExtractFailures(text, "node")
+	want := "not ok 2 - subtracts\n  ---\n  error: 'boom'\n  ..."
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestExtractFailures_NoFailures(t *testing.T) {
+	if got := ExtractFailures("PASS ./a.test.js\n  ✓ works\n", "jest"); got != "" {
+		t.Errorf("Expected no failures, got %q", got)
+	}
+}
+
+func TestHideConsoleBlocks(t *testing.T) {
+	got := HideConsoleBlocks(jestOutput)
+	if strings.Contains(got, "debugging value") || strings.Contains(got, "console.log") {
+		t.Errorf("Expected Jest console block to be removed, got %q", got)
+	}
+	if !strings.Contains(got, "FAIL ./sum.test.js") {
+		t.Errorf("Expected the rest of the output to be kept, got %q", got)
+	}
+
+	got = HideConsoleBlocks(vitestOutput)
+	if strings.Contains(got, "hello from test") || strings.Contains(got, "stdout |") {
+		t.Errorf("Expected Vitest console block to be removed, got %q", got)
+	}
+	if !strings.Contains(got, "× a > fails") {
+		t.Errorf("Expected the rest of the output to be kept, got %q", got)
+	}
+}
+
+func TestFilter_Apply(t *testing.T) {
+	full := "\x1b[31mout line\x1b[0m\nerr line\n"
+	stderr := "err line\n"
+
+	if got := (Filter{}).Apply(full, stderr, "jest"); got != full {
+		t.Errorf("Zero filter should be a no-op, got %q", got)
+	}
+	if got := (Filter{StderrOnly: true}).Apply(full, stderr, "jest"); got != stderr {
+		t.Errorf("Expected stderr only, got %q", got)
+	}
+	if got := (Filter{StripANSI: true}).Apply(full, stderr, "jest"); got != "out line\nerr line\n" {
+		t.Errorf("Expected ANSI to be stripped, got %q", got)
+	}
+}
+
+func TestFilter_Labels(t *testing.T) {
+	f := Filter{FailuresOnly: true, StripANSI: true}
+	if !f.Active() {
+		t.Error("Expected filter to be active")
+	}
+	got := strings.Join(f.Labels(), ",")
+	if got != "failures,plain" {
+		t.Errorf("Expected labels 'failures,plain', got %q", got)
+	}
+}
@@ -0,0 +1,80 @@
+package output
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SnapshotReport summarises the snapshot results of a single Jest or Vitest
+// run.
+type SnapshotReport struct {
+	Failed   int
+	Obsolete int
+	Written  int
+
+	// Mismatches holds the diff of each mismatched snapshot.
+	Mismatches []AssertionDiff
+	// ObsoleteNames lists the obsolete snapshots when the runner names them.
+	ObsoleteNames []string
+}
+
+// HasIssues reports whether the run failed, wrote, or left obsolete
+// snapshots.
+func (r SnapshotReport) HasIssues() bool {
+	return r.Failed > 0 || r.Obsolete > 0 || r.Written > 0 || len(r.Mismatches) > 0
+}
+
+var (
+	// summary counts: jest "Snapshots:   1 failed, 2 written, 3 total",
+	// vitest "Snapshots  1 failed | 1 obsolete"
+	snapshotCountRegex = regexp.MustCompile(`(\d+) (failed|obsolete|written)`)
+	// jest obsolete listing: "• suite name 1" under a "↳ file" line
+	obsoleteNameRegex = regexp.MustCompile(`^[•·] (.+)$`)
+)
+
+// ParseSnapshotReport extracts snapshot counts, mismatch diffs, and obsolete
+// snapshot names from a test run's output.
+func ParseSnapshotReport(text string) SnapshotReport {
+	var report SnapshotReport
+	_, plain := splitLines(text)
+
+	inObsolete := false
+	for _, p := range plain {
+		trimmed := strings.TrimSpace(p)
+
+		if strings.HasPrefix(trimmed, "Snapshots:") || strings.HasPrefix(trimmed, "Snapshots ") {
+			for _, match := range snapshotCountRegex.FindAllStringSubmatch(trimmed, -1) {
+				n, _ := strconv.Atoi(match[1])
+				switch match[2] {
+				case "failed":
+					report.Failed = n
+				case "obsolete":
+					report.Obsolete = n
+				case "written":
+					report.Written = n
+				}
+			}
+			continue
+		}
+
+		switch {
+		case strings.Contains(trimmed, "obsolete") && strings.HasPrefix(trimmed, "›"):
+			inObsolete = true
+		case inObsolete && strings.HasPrefix(trimmed, "↳"):
+			// File line inside the obsolete listing.
+		case inObsolete && obsoleteNameRegex.MatchString(trimmed):
+			report.ObsoleteNames = append(report.ObsoleteNames, obsoleteNameRegex.FindStringSubmatch(trimmed)[1])
+		case trimmed == "":
+		default:
+			inObsolete = false
+		}
+	}
+
+	for _, diff := range ParseAssertionDiffs(text) {
+		if diff.Snapshot != "" {
+			report.Mismatches = append(report.Mismatches, diff)
+		}
+	}
+	return report
+}
@@ -0,0 +1,45 @@
+package output
+
+import "testing"
+
+func TestParseSnapshotReport_Jest(t *testing.T) {
+	text := "  ● card › renders\n\n    expect(received).toMatchSnapshot()\n\n    Snapshot name: `card renders 1`\n\n    - Snapshot  - 1\n    + Received  + 1\n\n    - <div>old</div>\n    + <div>new</div>\n\n" +
+		" › 1 snapshot failed.\nSnapshot Summary\n › 1 snapshot failed from 1 test suite.\n › 1 snapshot obsolete from 1 test suite.\n   ↳ src/card.test.js\n       • card stale 1\n\n" +
+		"Snapshots:   1 failed, 1 obsolete, 2 passed, 4 total\n"
+
+	r := ParseSnapshotReport(text)
+	if r.Failed != 1 || r.Obsolete != 1 || r.Written != 0 {
+		t.Errorf("Unexpected counts: %+v", r)
+	}
+	if len(r.Mismatches) != 1 || r.Mismatches[0].Snapshot != "card renders 1" {
+		t.Fatalf("Expected one named mismatch, got %#v", r.Mismatches)
+	}
+	if len(r.Mismatches[0].Lines) != 2 {
+		t.Errorf("Expected 2 diff lines, got %#v", r.Mismatches[0].Lines)
+	}
+	if len(r.ObsoleteNames) != 1 || r.ObsoleteNames[0] != "card stale 1" {
+		t.Errorf("Expected obsolete name, got %v", r.ObsoleteNames)
+	}
+	if !r.HasIssues() {
+		t.Error("Expected report to have issues")
+	}
+}
+
+func TestParseSnapshotReport_Vitest(t *testing.T) {
+	text := " FAIL  src/card.test.ts > card > renders\nError: Snapshot `card > renders 1` mismatched\n\n- Expected\n+ Received\n\n- old\n+ new\n\n   Snapshots  1 failed | 1 written\n"
+
+	r := ParseSnapshotReport(text)
+	if r.Failed != 1 || r.Written != 1 {
+		t.Errorf("Unexpected counts: %+v", r)
+	}
+	if len(r.Mismatches) != 1 || r.Mismatches[0].Snapshot != "card > renders 1" {
+		t.Errorf("Expected one named mismatch, got %#v", r.Mismatches)
+	}
+}
+
+func TestParseSnapshotReport_Clean(t *testing.T) {
+	r := ParseSnapshotReport("Snapshots:   3 passed, 3 total\n")
+	if r.HasIssues() {
+		t.Errorf("Expected no issues, got %+v", r)
+	}
+}
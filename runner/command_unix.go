@@ -3,6 +3,7 @@
 package runner
 
 import (
+	"context"
 	"os/exec"
 	"syscall"
 )
@@ -16,3 +17,8 @@ func prepareCommand(cmd *exec.Cmd) {
 		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
 	}
 }
+
+// shellCommand runs script through the POSIX shell.
+func shellCommand(ctx context.Context, script string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", script)
+}
@@ -3,6 +3,7 @@
 package runner
 
 import (
+	"context"
 	"os/exec"
 )
 
@@ -10,3 +11,8 @@ func prepareCommand(cmd *exec.Cmd) {
 	// Windows doesn't support Setpgid or syscall.Kill for process groups in the same way.
 	// The default behavior of exec.CommandContext will kill the process when the context is cancelled.
 }
+
+// shellCommand runs script through cmd.exe.
+func shellCommand(ctx context.Context, script string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd", "/C", script)
+}
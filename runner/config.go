@@ -59,7 +59,9 @@ type Config struct {
 	MaxConcurrentTests int        `json:"max_concurrent_tests,omitempty"`
 	Overrides          []Override `json:"overrides,omitempty"`
 	Excludes           []string   `json:"excludes,omitempty"`
-	DetectedRunner     string     `json:"-"` // Not serialized; set at load time
+	Hooks              Hooks      `json:"hooks"`
+	PTY                bool       `json:"pty,omitempty"` // Run tests in a pseudo-terminal; see RunPTY
+	DetectedRunner     string     `json:"-"`             // Not serialized; set at load time
 }
 
 // Override defines a custom command for a specific file pattern.
@@ -0,0 +1,116 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hooks configures commands run on test lifecycle events. Each command runs
+// through the shell in the project root and receives the HookEvent as JSON on
+// stdin and as LAZYTEST_* environment variables.
+type Hooks struct {
+	OnTestFail      string `json:"on_test_fail,omitempty"`
+	OnTestPass      string `json:"on_test_pass,omitempty"`
+	OnSuiteComplete string `json:"on_suite_complete,omitempty"`
+	OnSuiteFail     string `json:"on_suite_fail,omitempty"`
+
+	// Notify sends a desktop notification via notify-send when a suite
+	// completes.
+	Notify bool `json:"notify,omitempty"`
+	// Bell rings the terminal bell when a suite completes.
+	Bell bool `json:"bell,omitempty"`
+}
+
+// Hook event names.
+const (
+	EventTestFail      = "test_fail"
+	EventTestPass      = "test_pass"
+	EventSuiteComplete = "suite_complete"
+	EventSuiteFail     = "suite_fail"
+)
+
+// hookTimeout bounds a hook so a stuck command cannot pile up behind every
+// test run.
+const hookTimeout = 30 * time.Second
+
+// HookEvent is the context passed to a hook. For test events Path and
+// Status describe the finished test; the counts always cover the suite so
+// far, where a suite is every test run between two idle periods.
+type HookEvent struct {
+	Event      string `json:"event"`
+	Path       string `json:"path,omitempty"`
+	Status     string `json:"status"` // "pass" or "fail"
+	DurationMs int64  `json:"duration_ms"`
+	Passed     int    `json:"passed"`
+	Failed     int    `json:"failed"`
+	Total      int    `json:"total"`
+}
+
+// env returns the event as LAZYTEST_* environment variables.
+func (ev HookEvent) env() []string {
+	return []string{
+		"LAZYTEST_EVENT=" + ev.Event,
+		"LAZYTEST_PATH=" + ev.Path,
+		"LAZYTEST_STATUS=" + ev.Status,
+		"LAZYTEST_DURATION_MS=" + strconv.FormatInt(ev.DurationMs, 10),
+		"LAZYTEST_PASSED=" + strconv.Itoa(ev.Passed),
+		"LAZYTEST_FAILED=" + strconv.Itoa(ev.Failed),
+		"LAZYTEST_TOTAL=" + strconv.Itoa(ev.Total),
+	}
+}
+
+// Summary describes a suite event in one line, e.g. "38 passed, 2 failed in 12.4s".
+func (ev HookEvent) Summary() string {
+	duration := (time.Duration(ev.DurationMs) * time.Millisecond).Round(100 * time.Millisecond)
+	if ev.Failed == 0 {
+		return fmt.Sprintf("%d passed in %s", ev.Passed, duration)
+	}
+	return fmt.Sprintf("%d passed, %d failed in %s", ev.Passed, ev.Failed, duration)
+}
+
+// RunHook runs command through the shell in dir with ev on stdin and in the
+// environment. A failing command's stderr is included in the error.
+func RunHook(command, dir string, ev HookEvent) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), ev.env()...)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s hook: %w: %s", ev.Event, err, msg)
+		}
+		return fmt.Errorf("%s hook: %w", ev.Event, err)
+	}
+	return nil
+}
+
+// DesktopNotify shows a desktop notification through notify-send.
+func DesktopNotify(title, body string, urgent bool) error {
+	path, err := exec.LookPath("notify-send")
+	if err != nil {
+		return fmt.Errorf("desktop notification: %w", err)
+	}
+	urgency := "normal"
+	if urgent {
+		urgency = "critical"
+	}
+	return exec.Command(path, "--app-name=LazyTest", "--urgency="+urgency, title, body).Run()
+}
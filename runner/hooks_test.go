@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunHook_PassesEventOnStdinAndEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook script uses sh")
+	}
+	dir := t.TempDir()
+	ev := HookEvent{Event: EventTestFail, Path: "/p/a.test.ts", Status: "fail", DurationMs: 1500, Failed: 1, Total: 1}
+
+	err := RunHook(`cat > event.json && printf '%s %s' "$LAZYTEST_EVENT" "$LAZYTEST_DURATION_MS" > env.txt`, dir, ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "event.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got HookEvent
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Expected JSON on stdin, got %q: %v", data, err)
+	}
+	if got != ev {
+		t.Errorf("Expected %+v, got %+v", ev, got)
+	}
+
+	env, _ := os.ReadFile(filepath.Join(dir, "env.txt"))
+	if string(env) != "test_fail 1500" {
+		t.Errorf("Unexpected env %q", env)
+	}
+}
+
+func TestRunHook_ReportsStderr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook script uses sh")
+	}
+	err := RunHook("echo boom >&2; exit 3", t.TempDir(), HookEvent{Event: EventSuiteFail})
+	if err == nil || !strings.Contains(err.Error(), "suite_fail hook") || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected the failure and its stderr, got %v", err)
+	}
+}
+
+func TestHookEvent_Summary(t *testing.T) {
+	if got := (HookEvent{Passed: 38, Failed: 2, DurationMs: 12420}).Summary(); got != "38 passed, 2 failed in 12.4s" {
+		t.Errorf("Unexpected summary %q", got)
+	}
+	if got := (HookEvent{Passed: 3, DurationMs: 900}).Summary(); got != "3 passed in 900ms" {
+		t.Errorf("Unexpected summary %q", got)
+	}
+}
+
+func TestLoadConfig_Hooks(t *testing.T) {
+	dir := t.TempDir()
+	content := `{"command": "echo", "hooks": {"on_suite_fail": "./notify.sh", "notify": true, "bell": true}}`
+	if err := os.WriteFile(filepath.Join(dir, ".lazytest.json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hooks := LoadConfig(dir).Hooks
+	if hooks.OnSuiteFail != "./notify.sh" || !hooks.Notify || !hooks.Bell {
+		t.Errorf("Unexpected hooks %+v", hooks)
+	}
+}
@@ -10,6 +10,7 @@ type TestJob struct {
 	Command string
 	Args    []string
 	Root    string
+	PTY     bool // Run in a pseudo-terminal
 }
 
 // PrepareJob encapsulates the logic to prepare a test execution.
@@ -41,6 +42,7 @@ func PrepareJob(nodePath string, workspaces []Workspace) (*TestJob, error) {
 		Command: cmd,
 		Args:    args,
 		Root:    execRoot,
+		PTY:     config.PTY,
 	}, nil
 }
 
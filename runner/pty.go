@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// errPTYUnsupported is returned by openPTY on platforms without PTY support.
+var errPTYUnsupported = errors.New("PTY mode is not supported on this platform")
+
+// ptyReadSize is the largest chunk sent in one raw OutputUpdate.
+const ptyReadSize = 32 * 1024
+
+// RunPTY executes command for filePath attached to a pseudo-terminal of cols
+// by rows, so the runner sees a TTY and writes its interactive output. Output
+// is sent as raw OutputUpdates for a terminal emulator to interpret; stdout
+// and stderr are not told apart. Updates are tagged with runID as in Run.
+// When no PTY can be opened it says so and falls back to Run.
+func (r *Runner) RunPTY(command string, args []string, cwd, filePath string, runID, cols, rows int) {
+	master, slave, err := openPTY(cols, rows)
+	if err != nil {
+		r.Updates <- OutputUpdate{FilePath: filePath, RunID: runID, Content: fmt.Sprintf("Falling back to pipes: %v", err)}
+		r.Run(command, args, cwd, filePath, runID)
+		return
+	}
+
+	cmd := r.command(command, args, cwd, filePath)
+	cmd.Env = append(cmd.Env, "TERM=xterm-256color", fmt.Sprintf("COLUMNS=%d", cols), fmt.Sprintf("LINES=%d", rows))
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	setControllingTerminal(cmd)
+
+	err = cmd.Start()
+	// The child holds its own copy; closing ours lets reads end when it exits.
+	slave.Close()
+	if err != nil {
+		master.Close()
+		r.fail(filePath, runID, "Error starting command", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer master.Close()
+		streamPTY(master, filePath, runID, r.Updates)
+	}()
+	go r.wait(cmd, filePath, runID, &wg)
+}
+
+// streamPTY sends everything read from the PTY master as raw chunks until the
+// terminal closes.
+func streamPTY(master *os.File, filePath string, runID int, out chan<- Update) {
+	buf := make([]byte, ptyReadSize)
+	for {
+		n, err := master.Read(buf)
+		if n > 0 {
+			out <- OutputUpdate{FilePath: filePath, RunID: runID, Content: string(buf[:n]), Raw: true}
+		}
+		if err != nil {
+			// Linux reports EIO once every process has closed the terminal.
+			if err != io.EOF && !isPTYClosed(err) {
+				out <- OutputUpdate{FilePath: filePath, RunID: runID, Content: fmt.Sprintf("\r\nerror reading output: %v\r\n", err), Raw: true}
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package runner
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a new pseudo-terminal of cols by rows.
+func openPTY(cols, rows int) (master, slave *os.File, err error) {
+	return openPTYPair(cols, rows, func(master *os.File) (string, error) {
+		fd := int(master.Fd())
+		if err := unix.IoctlSetInt(fd, unix.TIOCPTYGRANT, 0); err != nil {
+			return "", err
+		}
+		if err := unix.IoctlSetInt(fd, unix.TIOCPTYUNLK, 0); err != nil {
+			return "", err
+		}
+		var name [128]byte
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), unix.TIOCPTYGNAME, uintptr(unsafe.Pointer(&name[0]))); errno != 0 {
+			return "", errno
+		}
+		if i := bytes.IndexByte(name[:], 0); i >= 0 {
+			return string(name[:i]), nil
+		}
+		return string(name[:]), nil
+	})
+}
@@ -0,0 +1,23 @@
+package runner
+
+import (
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a new pseudo-terminal of cols by rows.
+func openPTY(cols, rows int) (master, slave *os.File, err error) {
+	return openPTYPair(cols, rows, func(master *os.File) (string, error) {
+		fd := int(master.Fd())
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return "", err
+		}
+		n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+		if err != nil {
+			return "", err
+		}
+		return "/dev/pts/" + strconv.Itoa(n), nil
+	})
+}
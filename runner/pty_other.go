@@ -0,0 +1,16 @@
+//go:build !linux && !darwin
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+)
+
+func openPTY(cols, rows int) (master, slave *os.File, err error) {
+	return nil, nil, errPTYUnsupported
+}
+
+func setControllingTerminal(cmd *exec.Cmd) {}
+
+func isPTYClosed(err error) bool { return false }
@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// collect reads r's updates until the status arrives.
+func collect(t *testing.T, r *Runner) ([]OutputUpdate, StatusUpdate) {
+	t.Helper()
+	var output []OutputUpdate
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case update := <-r.Updates:
+			switch u := update.(type) {
+			case OutputUpdate:
+				output = append(output, u)
+			case StatusUpdate:
+				return output, u
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for command completion")
+		}
+	}
+}
+
+func TestRun_LongLine(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	r := NewRunner()
+	r.Run("sh", []string{"-c", `i=0; while [ $i -lt 2000 ]; do printf 'xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx'; i=$((i+1)); done; echo`}, ".", "test", 1)
+
+	output, status := collect(t, r)
+	if status.Err != nil {
+		t.Fatalf("Expected nil error, got %v", status.Err)
+	}
+	if len(output) != 1 || len(output[0].Content) != 100000 {
+		t.Errorf("Expected one 100000-byte line, got %d updates", len(output))
+	}
+}
+
+func TestRunPTY(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("PTY mode is only supported on Linux and macOS")
+	}
+	r := NewRunner()
+	r.RunPTY("sh", []string{"-c", `[ -t 1 ] && echo "tty $(stty size)"; printf 'a\rb\n'; exit 3`}, ".", "test", 1, 100, 30)
+
+	output, status := collect(t, r)
+	if status.Err == nil {
+		t.Error("Expected the exit status to be reported")
+	}
+	var raw strings.Builder
+	for _, u := range output {
+		if !u.Raw {
+			t.Fatalf("Expected raw PTY output, got line %q", u.Content)
+		}
+		raw.WriteString(u.Content)
+	}
+	// The terminal translates \n to \r\n and passes \r through untouched.
+	if want := "tty 30 100\r\na\rb\r\n"; raw.String() != want {
+		t.Errorf("Expected %q, got %q", want, raw.String())
+	}
+}
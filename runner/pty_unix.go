@@ -0,0 +1,54 @@
+//go:build linux || darwin
+
+package runner
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// setControllingTerminal starts cmd in a new session with its stdin, the PTY
+// slave, as the controlling terminal. The session leader's pid is also the
+// process group id, so the Cancel set by prepareCommand still kills the
+// whole group.
+func setControllingTerminal(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+}
+
+// setPTYSize sets the terminal size reported to programs on the PTY.
+func setPTYSize(f *os.File, cols, rows int) error {
+	return unix.IoctlSetWinsize(int(f.Fd()), unix.TIOCSWINSZ, &unix.Winsize{Col: uint16(cols), Row: uint16(rows)})
+}
+
+// isPTYClosed reports whether err is the EIO a master returns once the slave
+// side has been closed by every process.
+func isPTYClosed(err error) bool {
+	return errors.Is(err, syscall.EIO)
+}
+
+// openPTYPair opens the master at /dev/ptmx, prepares the slave with
+// prepare, which returns its device path, and sizes it.
+func openPTYPair(cols, rows int, prepare func(master *os.File) (string, error)) (master, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	name, err := prepare(master)
+	if err == nil {
+		slave, err = os.OpenFile(name, os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	}
+	if err == nil {
+		if err = setPTYSize(master, cols, rows); err != nil {
+			slave.Close()
+		}
+	}
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, slave, nil
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
 )
 
@@ -20,15 +21,20 @@ type Runner struct {
 // Update is a marker interface for runner updates.
 type Update interface{}
 
-// OutputUpdate carries a line of output.
+// OutputUpdate carries a line of output, or a raw chunk of terminal output
+// for a PTY run.
 type OutputUpdate struct {
 	FilePath string
+	RunID    int // Run that wrote the output, as passed to Run
 	Content  string
+	Stderr   bool // True if the line was written to stderr
+	Raw      bool // True if Content is unprocessed PTY output, not a line
 }
 
 // StatusUpdate carries the final result.
 type StatusUpdate struct {
 	FilePath string
+	RunID    int // Run that finished, as passed to Run
 	Err      error
 }
 
@@ -40,46 +46,28 @@ func NewRunner() *Runner {
 	}
 }
 
-func (r *Runner) Run(command string, args []string, cwd string, filePath string) {
-	r.mu.Lock()
-	// Kill existing process for this file if it's already running
-	if cancel, exists := r.runningCmds[filePath]; exists {
-		cancel()
-	}
-	
-	// Create new context
-	ctx, cancel := context.WithCancel(context.Background())
-	r.runningCmds[filePath] = cancel
-
-	cmd := exec.CommandContext(ctx, command, args...)
-	cmd.Dir = cwd
-
-	prepareCommand(cmd)
-
-	// Force color output
-	cmd.Env = os.Environ()
-	cmd.Env = append(cmd.Env, "FORCE_COLOR=1", "CLICOLOR_FORCE=1")
-
-	r.mu.Unlock()
+// Run executes command for filePath with stdout and stderr piped, sending
+// each line as an OutputUpdate and the exit status as a StatusUpdate, both
+// tagged with runID so updates still in flight from a killed run can be told
+// apart. A previous run of the same file is killed first.
+func (r *Runner) Run(command string, args []string, cwd string, filePath string, runID int) {
+	cmd := r.command(command, args, cwd, filePath)
 
 	// Setup pipes
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		r.Updates <- OutputUpdate{FilePath: filePath, Content: fmt.Sprintf("Error creating stdout pipe: %v", err)}
-		r.Updates <- StatusUpdate{FilePath: filePath, Err: err}
+		r.fail(filePath, runID, "Error creating stdout pipe", err)
 		return
 	}
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		r.Updates <- OutputUpdate{FilePath: filePath, Content: fmt.Sprintf("Error creating stderr pipe: %v", err)}
-		r.Updates <- StatusUpdate{FilePath: filePath, Err: err}
+		r.fail(filePath, runID, "Error creating stderr pipe", err)
 		return
 	}
 
 	// Start command
 	if err := cmd.Start(); err != nil {
-		r.Updates <- OutputUpdate{FilePath: filePath, Content: fmt.Sprintf("Error starting command: %v", err)}
-		r.Updates <- StatusUpdate{FilePath: filePath, Err: err}
+		r.fail(filePath, runID, "Error starting command", err)
 		return
 	}
 
@@ -88,35 +76,76 @@ func (r *Runner) Run(command string, args []string, cwd string, filePath string)
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		streamReader(stdout, filePath, r.Updates)
+		streamReader(stdout, filePath, runID, false, r.Updates)
 	}()
 	go func() {
 		defer wg.Done()
-		streamReader(stderr, filePath, r.Updates)
+		streamReader(stderr, filePath, runID, true, r.Updates)
 	}()
 
-	// Wait for command to finish
-	go func() {
-		// Wait for output streaming to finish first
-		wg.Wait()
-		// Then wait for process to exit and close pipes
-		err := cmd.Wait()
+	go r.wait(cmd, filePath, runID, &wg)
+}
 
-		r.mu.Lock()
-		delete(r.runningCmds, filePath)
-		r.mu.Unlock()
+// command prepares the process for filePath and registers its cancel func,
+// killing a previous run of the same file.
+func (r *Runner) command(command string, args []string, cwd, filePath string) *exec.Cmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Kill existing process for this file if it's already running
+	if cancel, exists := r.runningCmds[filePath]; exists {
+		cancel()
+	}
 
-		r.Updates <- StatusUpdate{FilePath: filePath, Err: err}
-	}()
+	// Create new context
+	ctx, cancel := context.WithCancel(context.Background())
+	r.runningCmds[filePath] = cancel
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Dir = cwd
+
+	prepareCommand(cmd)
+
+	// Force color output
+	cmd.Env = os.Environ()
+	cmd.Env = append(cmd.Env, "FORCE_COLOR=1", "CLICOLOR_FORCE=1")
+	return cmd
 }
 
-func streamReader(r io.Reader, filePath string, out chan<- Update) {
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		out <- OutputUpdate{FilePath: filePath, Content: scanner.Text()}
-	}
-	if err := scanner.Err(); err != nil {
-		out <- OutputUpdate{FilePath: filePath, Content: fmt.Sprintf("error reading output: %v", err)}
+// fail reports a command that could not be started.
+func (r *Runner) fail(filePath string, runID int, action string, err error) {
+	r.Updates <- OutputUpdate{FilePath: filePath, RunID: runID, Content: fmt.Sprintf("%s: %v", action, err)}
+	r.Updates <- StatusUpdate{FilePath: filePath, RunID: runID, Err: err}
+}
+
+// wait reports cmd's exit status once its output has been read.
+func (r *Runner) wait(cmd *exec.Cmd, filePath string, runID int, output *sync.WaitGroup) {
+	// Wait for output streaming to finish first
+	output.Wait()
+	// Then wait for process to exit and close pipes
+	err := cmd.Wait()
+
+	r.mu.Lock()
+	delete(r.runningCmds, filePath)
+	r.mu.Unlock()
+
+	r.Updates <- StatusUpdate{FilePath: filePath, RunID: runID, Err: err}
+}
+
+// streamReader sends each line read from r. Lines have no length limit.
+func streamReader(r io.Reader, filePath string, runID int, isStderr bool, out chan<- Update) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			out <- OutputUpdate{FilePath: filePath, RunID: runID, Content: line, Stderr: isStderr}
+		}
+		if err != nil {
+			if err != io.EOF {
+				out <- OutputUpdate{FilePath: filePath, RunID: runID, Content: fmt.Sprintf("error reading output: %v", err), Stderr: true}
+			}
+			return
+		}
 	}
 }
 
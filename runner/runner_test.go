@@ -9,7 +9,7 @@ import (
 func TestRunner(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		r := NewRunner()
-		r.Run("echo", []string{"hello"}, ".", "test")
+		r.Run("echo", []string{"hello"}, ".", "test", 1)
 
 		var output []string
 		var status *StatusUpdate
@@ -49,7 +49,7 @@ func TestRunner(t *testing.T) {
 	t.Run("Failure", func(t *testing.T) {
 		r := NewRunner()
 		// Run a command that fails (exit 1)
-		r.Run("sh", []string{"-c", "exit 1"}, ".", "test")
+		r.Run("sh", []string{"-c", "exit 1"}, ".", "test", 1)
 
 		var status *StatusUpdate
 		timeout := time.After(2 * time.Second)
@@ -75,7 +75,7 @@ func TestRunner(t *testing.T) {
 	t.Run("Kill", func(t *testing.T) {
 		r := NewRunner()
 		// Run a long running command
-		r.Run("sleep", []string{"2"}, ".", "test")
+		r.Run("sleep", []string{"2"}, ".", "test", 1)
 
 		// Give it a moment to start
 		time.Sleep(100 * time.Millisecond)
@@ -106,13 +106,13 @@ func TestRunner(t *testing.T) {
 	t.Run("Concurrent Run", func(t *testing.T) {
 		r := NewRunner()
 		// Start first command
-		r.Run("sleep", []string{"2"}, ".", "test1")
+		r.Run("sleep", []string{"2"}, ".", "test1", 1)
 
 		// Give it a moment to start
 		time.Sleep(100 * time.Millisecond)
 
 		// Start second command immediately
-		r.Run("echo", []string{"second"}, ".", "test2")
+		r.Run("echo", []string{"second"}, ".", "test2", 1)
 
 		// We expect the first command to be cancelled (killed) and the second to finish successfully
 		// However, since they share the Updates channel, we might see updates from both.
@@ -158,7 +158,7 @@ func TestRunner(t *testing.T) {
 		// However, for this test, checking if the output *contains* the base name of the temp dir is usually sufficient
 		// or we can just use the runner's Cwd argument and see if it respects it.
 
-		r.Run(cmd, args, tmpDir, "test")
+		r.Run(cmd, args, tmpDir, "test", 1)
 
 		var output []string
 		var status *StatusUpdate
@@ -220,7 +220,7 @@ func TestRunner(t *testing.T) {
 	t.Run("Stderr Capture", func(t *testing.T) {
 		r := NewRunner()
 		// Write to stderr
-		r.Run("sh", []string{"-c", "echo 'some error' >&2"}, ".", "test")
+		r.Run("sh", []string{"-c", "echo 'some error' >&2"}, ".", "test", 1)
 
 		var output []string
 		var status *StatusUpdate
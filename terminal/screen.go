@@ -0,0 +1,421 @@
+// Package terminal emulates enough of an xterm-compatible terminal to render
+// output written to a PTY: carriage returns, cursor movement, erasing and SGR
+// colors. Lines that scroll off the screen are kept as scrollback so the whole
+// run can be read afterwards.
+package terminal
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxLines caps the scrollback; the oldest lines are dropped beyond it.
+	maxLines = 10000
+	// maxPending caps an unterminated escape sequence carried between writes.
+	maxPending = 4096
+)
+
+// cell is one character on the screen. A zero rune is a cell that was never
+// written and renders as a space.
+type cell struct {
+	r     rune
+	style string // SGR sequence in effect when the cell was written
+}
+
+// Screen is a virtual terminal. The visible screen is the last rows lines of
+// the buffer; everything above it is scrollback.
+type Screen struct {
+	cols, rows int
+	lines      [][]cell
+	row, col   int // Cursor, row indexes lines
+	pen        pen
+	style      string // The pen as an SGR sequence, empty for the default style
+	savedRow   int
+	savedCol   int
+	pending    []byte // Incomplete escape sequence or rune from the last Write
+}
+
+// NewScreen returns an empty screen of cols by rows cells.
+func NewScreen(cols, rows int) *Screen {
+	return &Screen{cols: max(cols, 1), rows: max(rows, 1), lines: [][]cell{nil}}
+}
+
+// Write interprets p as terminal output. It never fails; sequences it does
+// not understand are dropped.
+func (s *Screen) Write(p []byte) (int, error) {
+	data := p
+	if len(s.pending) > 0 {
+		data = append(s.pending, p...)
+		s.pending = nil
+	}
+
+	for i := 0; i < len(data); {
+		b := data[i]
+		switch {
+		case b == 0x1b:
+			n, ok := s.escape(data[i:])
+			if !ok {
+				if len(data)-i <= maxPending {
+					s.pending = append([]byte(nil), data[i:]...)
+				}
+				return len(p), nil
+			}
+			i += n
+			continue
+		case b == '\r':
+			s.col = 0
+		case b == '\n', b == '\v', b == '\f':
+			s.lineFeed()
+		case b == '\b':
+			s.col = max(s.col-1, 0)
+		case b == '\t':
+			s.col = min((s.col/8+1)*8, s.cols-1)
+		case b < 0x20 || b == 0x7f:
+			// BEL and other controls have no visible effect.
+		default:
+			if !utf8.FullRune(data[i:]) {
+				s.pending = append([]byte(nil), data[i:]...)
+				return len(p), nil
+			}
+			r, size := utf8.DecodeRune(data[i:])
+			s.put(r)
+			i += size
+			continue
+		}
+		i++
+	}
+	return len(p), nil
+}
+
+// String renders the scrollback and screen as text with SGR sequences,
+// dropping trailing blank lines.
+func (s *Screen) String() string {
+	end := len(s.lines)
+	for end > 0 && len(s.lines[end-1]) == 0 {
+		end--
+	}
+
+	var b strings.Builder
+	for _, line := range s.lines[:end] {
+		style := ""
+		for _, c := range line {
+			if c.style != style {
+				b.WriteString("\x1b[0m")
+				b.WriteString(c.style)
+				style = c.style
+			}
+			if c.r == 0 {
+				b.WriteByte(' ')
+			} else {
+				b.WriteRune(c.r)
+			}
+		}
+		if style != "" {
+			b.WriteString("\x1b[0m")
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// top returns the index of the first line on the visible screen.
+func (s *Screen) top() int {
+	return max(len(s.lines)-s.rows, 0)
+}
+
+// put writes r at the cursor, wrapping to the next line first when the
+// previous character filled the last column.
+func (s *Screen) put(r rune) {
+	if s.col >= s.cols {
+		s.col = 0
+		s.lineFeed()
+	}
+	line := s.lines[s.row]
+	for len(line) <= s.col {
+		line = append(line, cell{})
+	}
+	line[s.col] = cell{r: r, style: s.style}
+	s.lines[s.row] = line
+	s.col++
+}
+
+// lineFeed moves the cursor down one line, scrolling at the bottom.
+func (s *Screen) lineFeed() {
+	s.row++
+	s.ensureRow()
+	if over := len(s.lines) - maxLines; over > 0 {
+		s.lines = s.lines[over:]
+		s.row -= over
+	}
+}
+
+// ensureRow grows the buffer so the cursor's line exists.
+func (s *Screen) ensureRow() {
+	for len(s.lines) <= s.row {
+		s.lines = append(s.lines, nil)
+	}
+}
+
+// moveTo puts the cursor at the 0-based screen position, clamped to the screen.
+func (s *Screen) moveTo(row, col int) {
+	top := s.top()
+	s.row = top + min(max(row, 0), s.rows-1)
+	s.col = min(max(col, 0), s.cols-1)
+	s.ensureRow()
+}
+
+// escape handles the escape sequence at the start of data and returns its
+// length. ok is false when data ends before the sequence does.
+func (s *Screen) escape(data []byte) (n int, ok bool) {
+	if len(data) < 2 {
+		return 0, false
+	}
+	switch data[1] {
+	case '[':
+		return s.csi(data)
+	case ']', 'P', 'X', '^', '_':
+		return stringSequence(data)
+	case '(', ')', '*', '+', '#', '%':
+		if len(data) < 3 {
+			return 0, false
+		}
+		return 3, true
+	case '7':
+		s.savedRow, s.savedCol = s.row-s.top(), s.col
+	case '8':
+		s.moveTo(s.savedRow, s.savedCol)
+	case 'c':
+		*s = *NewScreen(s.cols, s.rows)
+	case 'D':
+		s.lineFeed()
+	case 'E':
+		s.col = 0
+		s.lineFeed()
+	case 'M':
+		s.row = max(s.row-1, s.top())
+	}
+	return 2, true
+}
+
+// stringSequence skips an OSC, DCS or similar sequence, which ends with BEL
+// or ST (ESC \).
+func stringSequence(data []byte) (int, bool) {
+	for i := 2; i < len(data); i++ {
+		switch {
+		case data[i] == 0x07:
+			return i + 1, true
+		case data[i] == 0x1b && i+1 < len(data):
+			return i + 2, true
+		}
+	}
+	return 0, false
+}
+
+// csi handles a Control Sequence Introducer sequence: ESC [, optional
+// private marker, parameters, intermediate bytes and a final byte.
+func (s *Screen) csi(data []byte) (int, bool) {
+	i := 2
+	private := false
+	if i < len(data) && data[i] >= '<' && data[i] <= '?' {
+		private = true
+		i++
+	}
+	start := i
+	for i < len(data) && (data[i] >= '0' && data[i] <= '9' || data[i] == ';' || data[i] == ':') {
+		i++
+	}
+	params := string(data[start:i])
+	for i < len(data) && data[i] >= 0x20 && data[i] <= 0x2f {
+		i++
+	}
+	if i >= len(data) {
+		return 0, false
+	}
+	final := data[i]
+	if final < 0x40 || final > 0x7e {
+		// Malformed: drop what was read and resume at this byte.
+		return i, true
+	}
+	if !private {
+		s.control(final, params)
+	}
+	return i + 1, true
+}
+
+// control applies the CSI sequence with the given final byte and parameters.
+func (s *Screen) control(final byte, params string) {
+	args := parseParams(params)
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+
+	row := s.row - s.top()
+	switch final {
+	case 'A':
+		s.moveTo(row-arg(0, 1), s.col)
+	case 'B', 'e':
+		s.moveTo(row+arg(0, 1), s.col)
+	case 'C', 'a':
+		s.col = min(s.col+arg(0, 1), s.cols-1)
+	case 'D':
+		s.col = max(min(s.col, s.cols-1)-arg(0, 1), 0)
+	case 'E':
+		s.moveTo(row+arg(0, 1), 0)
+	case 'F':
+		s.moveTo(row-arg(0, 1), 0)
+	case 'G', '`':
+		s.col = min(arg(0, 1), s.cols) - 1
+	case 'H', 'f':
+		s.moveTo(arg(0, 1)-1, arg(1, 1)-1)
+	case 'd':
+		s.moveTo(arg(0, 1)-1, s.col)
+	case 'J':
+		s.eraseDisplay(arg(0, 0))
+	case 'K':
+		s.eraseLine(arg(0, 0))
+	case 'm':
+		s.setStyle(params)
+	case 's':
+		s.savedRow, s.savedCol = row, s.col
+	case 'u':
+		s.moveTo(s.savedRow, s.savedCol)
+	}
+}
+
+// parseParams splits CSI parameters; empty parameters are 0.
+func parseParams(params string) []int {
+	if params == "" {
+		return nil
+	}
+	fields := strings.Split(strings.ReplaceAll(params, ":", ";"), ";")
+	args := make([]int, len(fields))
+	for i, f := range fields {
+		args[i], _ = strconv.Atoi(f)
+	}
+	return args
+}
+
+// eraseLine clears part of the cursor's line: 0 to the end, 1 to the start
+// (inclusive), 2 the whole line.
+func (s *Screen) eraseLine(mode int) {
+	line := s.lines[s.row]
+	switch mode {
+	case 0:
+		if s.col < len(line) {
+			s.lines[s.row] = line[:s.col]
+		}
+	case 1:
+		for i := 0; i <= s.col && i < len(line); i++ {
+			line[i] = cell{}
+		}
+	case 2:
+		s.lines[s.row] = nil
+	}
+}
+
+// eraseDisplay clears part of the screen: 0 from the cursor to the end, 1
+// from the start to the cursor, 2 the whole screen and 3 the scrollback.
+func (s *Screen) eraseDisplay(mode int) {
+	top := s.top()
+	switch mode {
+	case 0:
+		s.eraseLine(0)
+		for i := s.row + 1; i < len(s.lines); i++ {
+			s.lines[i] = nil
+		}
+	case 1:
+		for i := top; i < s.row; i++ {
+			s.lines[i] = nil
+		}
+		s.eraseLine(1)
+	case 2:
+		for i := top; i < len(s.lines); i++ {
+			s.lines[i] = nil
+		}
+	case 3:
+		s.lines = s.lines[top:]
+		s.row -= top
+	}
+}
+
+// pen is the SGR state applied to new cells.
+type pen struct {
+	attrs  [10]bool // Indexed by SGR code: 1 bold, 2 faint, 3 italic, 4 underline...
+	fg, bg string   // SGR parameters of the colors, empty for the default
+}
+
+// setStyle applies an SGR sequence's parameters to the pen.
+func (s *Screen) setStyle(params string) {
+	fields := strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' })
+	if len(fields) == 0 {
+		fields = []string{"0"}
+	}
+	p := &s.pen
+	for i := 0; i < len(fields); i++ {
+		code, _ := strconv.Atoi(fields[i])
+		switch {
+		case code == 0:
+			*p = pen{}
+		case code >= 1 && code <= 9:
+			p.attrs[code] = true
+		case code == 22:
+			p.attrs[1], p.attrs[2] = false, false
+		case code >= 23 && code <= 29 && code != 26:
+			p.attrs[code-20] = false
+		case code >= 30 && code <= 37, code >= 90 && code <= 97:
+			p.fg = fields[i]
+		case code == 39:
+			p.fg = ""
+		case code >= 40 && code <= 47, code >= 100 && code <= 107:
+			p.bg = fields[i]
+		case code == 49:
+			p.bg = ""
+		case code == 38 || code == 48 || code == 58:
+			// Extended color: 5;n for the 256-color palette or 2;r;g;b.
+			n := 0
+			if i+1 < len(fields) {
+				switch fields[i+1] {
+				case "5":
+					n = 2
+				case "2":
+					n = 4
+				}
+			}
+			n = min(n, len(fields)-1-i)
+			color := strings.Join(fields[i:i+n+1], ";")
+			if code == 38 {
+				p.fg = color
+			} else if code == 48 {
+				p.bg = color
+			}
+			i += n
+		}
+	}
+	s.style = p.sequence()
+}
+
+// sequence renders the pen as a single SGR sequence, empty for the default
+// style.
+func (p pen) sequence() string {
+	var parts []string
+	for code, on := range p.attrs {
+		if on {
+			parts = append(parts, strconv.Itoa(code))
+		}
+	}
+	if p.fg != "" {
+		parts = append(parts, p.fg)
+	}
+	if p.bg != "" {
+		parts = append(parts, p.bg)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "\x1b[" + strings.Join(parts, ";") + "m"
+}
@@ -0,0 +1,75 @@
+package terminal
+
+import "testing"
+
+func render(cols, rows int, chunks ...string) string {
+	s := NewScreen(cols, rows)
+	for _, chunk := range chunks {
+		s.Write([]byte(chunk))
+	}
+	return s.String()
+}
+
+func TestScreen(t *testing.T) {
+	tests := []struct {
+		name   string
+		cols   int
+		chunks []string
+		want   string
+	}{
+		{"plain lines", 20, []string{"one\r\ntwo\r\n"}, "one\ntwo\n"},
+		{"carriage return overwrites", 20, []string{"Running 1/3\rRunning 2/3\rDone       \r\n"}, "Done       \n"},
+		{"erase line after return", 20, []string{"progress 50%\r\x1b[Kok\r\n"}, "ok\n"},
+		{"cursor up redraws", 20, []string{"a: RUN\r\nb: RUN\r\n", "\x1b[2A\x1b[2Ka: PASS\r\n\x1b[2Kb: FAIL\r\n"}, "a: PASS\nb: FAIL\n"},
+		{"line feed keeps column", 20, []string{"ab\ncd"}, "ab\n  cd\n"},
+		{"wraps at width", 4, []string{"abcdef"}, "abcd\nef\n"},
+		{"absolute position", 20, []string{"x\x1b[3;2Hy"}, "x\n\n y\n"},
+		{"column and back", 20, []string{"hello\x1b[1Gj\x1b[3C\x1b[2Dl"}, "jello\n"},
+		{"clear screen keeps scrollback", 20, []string{"1\r\n2\r\n3\r\n4\r\n5", "\x1b[2J\x1b[Hnew"}, "1\n2\nnew\n"},
+		{"clear scrollback", 20, []string{"1\r\n2\r\n3\r\n4\r\n5", "\x1b[2J\x1b[3J\x1b[Hnew"}, "new\n"},
+		{"save and restore", 20, []string{"\x1b7abc\x1b8X"}, "Xbc\n"},
+		{"split escape sequence", 20, []string{"abc\x1b[", "2Dx"}, "axc\n"},
+		{"split rune", 20, []string{"\xe2\x9c", "\x93 ok"}, "✓ ok\n"},
+		{"ignores OSC and private modes", 20, []string{"\x1b]0;title\x07\x1b[?25lok\x1b[?25h"}, "ok\n"},
+		{"trailing blank lines dropped", 20, []string{"ok\r\n\r\n\r\n"}, "ok\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(tt.cols, 3, tt.chunks...); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreen_Colors(t *testing.T) {
+	got := render(80, 24, "\x1b[32m✓\x1b[39m pass \x1b[1;31mfail\x1b[0m")
+	want := "\x1b[0m\x1b[32m✓\x1b[0m pass \x1b[0m\x1b[1;31mfail\x1b[0m\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestScreen_ColorResetsDoNotAccumulate(t *testing.T) {
+	s := NewScreen(80, 24)
+	for range 100 {
+		s.Write([]byte("\x1b[31mx\x1b[39m\x1b[1my\x1b[22m"))
+	}
+	if s.style != "" {
+		t.Errorf("style after resets = %q, want default", s.style)
+	}
+	s.Write([]byte("\x1b[38;5;208;48;2;1;2;3m"))
+	if want := "\x1b[38;5;208;48;2;1;2;3m"; s.style != want {
+		t.Errorf("extended colors = %q, want %q", s.style, want)
+	}
+}
+
+func TestScreen_ScrollbackCap(t *testing.T) {
+	s := NewScreen(80, 24)
+	for range maxLines + 10 {
+		s.Write([]byte("line\r\n"))
+	}
+	if len(s.lines) > maxLines {
+		t.Errorf("kept %d lines, want at most %d", len(s.lines), maxLines)
+	}
+}
@@ -2,8 +2,8 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 
-	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -15,28 +15,18 @@ func (m *Model) applySmartModeBindings() {
 	// ToggleWatch is meaningless in Smart Mode
 	m.keys.ToggleWatch.SetEnabled(!smartMode)
 
-	// RunFailures is only available in Smart Mode
+	// RunFailures and WhyAffected are only available in Smart Mode
 	m.keys.RunFailures.SetEnabled(smartMode)
+	m.keys.WhyAffected.SetEnabled(smartMode)
 
-	// Repurpose ClearWatched and AddRelated labels in Smart Mode
+	// Repurpose ClearWatched and AddRelated labels in Smart Mode, keeping
+	// whatever keys the user bound them to.
 	if smartMode {
-		m.keys.ClearWatched = key.NewBinding(
-			key.WithKeys("W"),
-			key.WithHelp("W", "clear suite"),
-		)
-		m.keys.AddRelated = key.NewBinding(
-			key.WithKeys("a"),
-			key.WithHelp("a", "run suite"),
-		)
+		m.keys.ClearWatched.SetHelp(m.keys.ClearWatched.Help().Key, "clear suite")
+		m.keys.AddRelated.SetHelp(m.keys.AddRelated.Help().Key, "run suite")
 	} else {
-		m.keys.ClearWatched = key.NewBinding(
-			key.WithKeys("W"),
-			key.WithHelp("W", "clear watched"),
-		)
-		m.keys.AddRelated = key.NewBinding(
-			key.WithKeys("a"),
-			key.WithHelp("a", "add related"),
-		)
+		m.keys.ClearWatched.SetHelp(m.keys.ClearWatched.Help().Key, "clear watched")
+		m.keys.AddRelated.SetHelp(m.keys.AddRelated.Help().Key, "add related")
 	}
 }
 
@@ -44,7 +34,7 @@ func (m *Model) applySmartModeBindings() {
 // Example:  ⚡ SMART MODE | 3 Passed • 1 Failed • 0 Running
 func (m Model) renderSuiteBadge(passed, failed, running int) string {
 	label := lipgloss.NewStyle().
-		Foreground(lipgloss.AdaptiveColor{Light: "#7C3AED", Dark: "#A78BFA"}).
+		Foreground(smartColor).
 		Bold(true).
 		Padding(0, 1).
 		Render("⚡ SMART MODE")
@@ -54,18 +44,29 @@ func (m Model) renderSuiteBadge(passed, failed, running int) string {
 		Render(" | ")
 
 	passedStr := lipgloss.NewStyle().
-		Foreground(lipgloss.AdaptiveColor{Light: "#15803D", Dark: "#4ADE80"}).
+		Foreground(passColor).
 		Render(fmt.Sprintf("%d Passed", passed))
 
 	failedStr := lipgloss.NewStyle().
-		Foreground(lipgloss.AdaptiveColor{Light: "#B91C1C", Dark: "#F87171"}).
+		Foreground(failColor).
 		Render(fmt.Sprintf("%d Failed", failed))
 
 	runningStr := lipgloss.NewStyle().
-		Foreground(lipgloss.AdaptiveColor{Light: "#B45309", Dark: "#FCD34D"}).
+		Foreground(runningColor).
 		Render(fmt.Sprintf("%d Running", running))
 
 	dot := lipgloss.NewStyle().Foreground(subtle).Render(" • ")
 
 	return label + sep + passedStr + dot + failedStr + dot + runningStr
 }
+
+// renderFilterLabel renders the active output filters for the output pane
+// header, e.g. " [failures • plain]". It is empty when no filter is active.
+func (m Model) renderFilterLabel() string {
+	if !m.outputFilter.Active() {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(subtle).
+		Render(" [" + strings.Join(m.outputFilter.Labels(), " • ") + "]")
+}
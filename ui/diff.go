@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jesspatton/lazytest/output"
+)
+
+// openDiffView parses the assertion diffs out of the selected test's output
+// and shows them in the full-screen side-by-side view.
+func (m Model) openDiffView() (Model, tea.Cmd) {
+	path, ok := m.selectedPath()
+	if !ok {
+		return m, notify("Select a test file to view its diffs.", true)
+	}
+	out, _ := m.engine.GetTestOutput(path)
+	diffs := output.ParseAssertionDiffs(out)
+	if len(diffs) == 0 {
+		return m, notify("No expected/received diffs found in the output.", true)
+	}
+
+	m.diffMode = true
+	m.diffs = diffs
+	m.diffIndex = 0
+	m.diffViewport = viewport.New(0, 0)
+	m.diffViewport.KeyMap = m.keys.overlayViewportKeys(true)
+	m.refreshDiffView()
+	return m, nil
+}
+
+// handleDiffKey processes a key message while the diff view is open.
+func (m Model) handleDiffKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.ExitSearch), key.Matches(msg, m.keys.DiffView):
+		m.diffMode = false
+		m.diffs = nil
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.NextMatch):
+		m.diffIndex = (m.diffIndex + 1) % len(m.diffs)
+		m.refreshDiffView()
+	case key.Matches(msg, m.keys.PrevMatch):
+		m.diffIndex = (m.diffIndex - 1 + len(m.diffs)) % len(m.diffs)
+		m.refreshDiffView()
+	default:
+		var cmd tea.Cmd
+		m.diffViewport, cmd = m.diffViewport.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// refreshDiffView sizes the diff viewport to the terminal and renders the
+// current diff into it.
+func (m *Model) refreshDiffView() {
+	if !m.diffMode || len(m.diffs) == 0 {
+		return
+	}
+	// Border(2) + Padding(2) horizontally; border(2), title(2), header(1) and
+	// footer(1) vertically.
+	m.diffViewport.Width = max(m.width-4, 0)
+	m.diffViewport.Height = max(m.height-7, 0)
+	m.diffViewport.SetContent(renderDiffRows(m.diffs[m.diffIndex], m.diffViewport.Width))
+	m.diffViewport.GotoTop()
+}
+
+// renderDiffView renders the full-screen side-by-side diff view.
+func (m Model) renderDiffView() string {
+	diff := m.diffs[m.diffIndex]
+
+	title := diff.Title
+	if title == "" {
+		title = "Assertion diff"
+	}
+	header := titleStyle.Render(fmt.Sprintf("DIFF %d/%d", m.diffIndex+1, len(m.diffs))) + " " + title
+
+	colWidth := m.diffViewport.Width / 2
+	columns := lipgloss.JoinHorizontal(lipgloss.Top,
+		diffExpectedHeaderStyle.Width(colWidth).Render("Expected"),
+		diffReceivedHeaderStyle.Width(colWidth).Render("Received"),
+	)
+
+	hints := statusStyle.Render(strings.Join([]string{
+		keyHint(m.keys.Down, "scroll down"),
+		keyHint(m.keys.Up, "scroll up"),
+		keyHint(m.diffViewport.KeyMap.PageDown, "page"),
+		keyHint(m.keys.NextMatch, "next diff"),
+		keyHint(m.keys.PrevMatch, "prev diff"),
+		keyHint(m.keys.ExitSearch, "close"),
+	}, " • "))
+
+	body := lipgloss.JoinVertical(lipgloss.Left, header, "", columns, m.diffViewport.View(), hints)
+	return activePaneStyle.
+		Width(m.width - 2).
+		Height(m.height - 2).
+		Render(body)
+}
+
+// renderDiffRows lays out a diff as two columns, highlighting the words that
+// differ within each changed row.
+func renderDiffRows(diff output.AssertionDiff, width int) string {
+	colWidth := width / 2
+	if colWidth < 1 {
+		colWidth = 1
+	}
+	cell := lipgloss.NewStyle().Width(colWidth)
+
+	var b strings.Builder
+	for _, row := range diff.Rows() {
+		var left, right string
+		switch {
+		case !row.Changed:
+			left, right = row.Left, row.Right
+		case row.HasLeft && row.HasRight:
+			ls, rs := output.WordDiff(row.Left, row.Right)
+			left = renderSegments(ls, diffExpectedStyle, diffExpectedWordStyle)
+			right = renderSegments(rs, diffReceivedStyle, diffReceivedWordStyle)
+		default:
+			if row.HasLeft {
+				left = diffExpectedStyle.Render(row.Left)
+			}
+			if row.HasRight {
+				right = diffReceivedStyle.Render(row.Right)
+			}
+		}
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, cell.Render(left), cell.Render(right)))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// renderSegments renders word-diff segments, emphasising the changed ones.
+func renderSegments(segs []output.Segment, base, changed lipgloss.Style) string {
+	var b strings.Builder
+	for _, seg := range segs {
+		if seg.Changed {
+			b.WriteString(changed.Render(seg.Text))
+		} else {
+			b.WriteString(base.Render(seg.Text))
+		}
+	}
+	return b.String()
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jesspatton/lazytest/engine"
 	"github.com/jesspatton/lazytest/filesystem"
@@ -29,7 +30,7 @@ func (m Model) renderExplorer(paneWidth, paneHeight int) string {
 	// Render Tabs
 	activeTabStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(highlight).
+		BorderForeground(activeBorder).
 		Padding(0, 1).
 		Foreground(highlight)
 
@@ -39,21 +40,16 @@ func (m Model) renderExplorer(paneWidth, paneHeight int) string {
 		Padding(0, 1).
 		Foreground(subtle)
 
-	var explorerTab, watchedTab string
-	// In Smart Mode the second tab is renamed "Affected Suite"
-	watchedTabLabel := "Watched"
-	if m.engine.IsSmartMode() {
-		watchedTabLabel = "Affected Suite"
-	}
-	if m.activeTab == TabExplorer {
-		explorerTab = activeTabStyle.Render("Explorer")
-		watchedTab = inactiveTabStyle.Render(watchedTabLabel)
-	} else {
-		explorerTab = inactiveTabStyle.Render("Explorer")
-		watchedTab = activeTabStyle.Render(watchedTabLabel)
+	var tabViews []string
+	for i, label := range m.tabLabels() {
+		if LeftTab(i) == m.activeTab {
+			tabViews = append(tabViews, activeTabStyle.Render(label))
+		} else {
+			tabViews = append(tabViews, inactiveTabStyle.Render(label))
+		}
 	}
 
-	tabs := lipgloss.JoinHorizontal(lipgloss.Bottom, explorerTab, watchedTab)
+	tabs := lipgloss.JoinHorizontal(lipgloss.Bottom, tabViews...)
 	explorerView.WriteString(tabs)
 	explorerView.WriteString("\n\n")
 
@@ -75,6 +71,8 @@ func (m Model) renderExplorer(paneWidth, paneHeight int) string {
 	if m.activeTab == TabExplorer {
 		if m.engine.GetTree() == nil {
 			explorerView.WriteString("Scanning...")
+		} else if len(m.flatNodes) == 0 && m.statusFilter != 0 {
+			explorerView.WriteString(lipgloss.NewStyle().Foreground(subtle).Render("No files match the status filter.\nPress 0 to clear it."))
 		} else {
 			start, end := m.calculateVisibleRange(treeHeight)
 
@@ -86,6 +84,8 @@ func (m Model) renderExplorer(paneWidth, paneHeight int) string {
 				m.renderNode(&explorerView, node, i)
 			}
 		}
+	} else if m.activeTab == TabStats {
+		m.renderStats(&explorerView, paneWidth-2, treeHeight)
 	} else {
 		// Render Watched / Affected Suite list
 		tabList, emptyHint := m.getTabList()
@@ -146,13 +146,17 @@ func (m Model) renderExplorer(paneWidth, paneHeight int) string {
 	if m.searchMode && m.activeTab == TabExplorer {
 		searchStyle := lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(highlight).
+			BorderForeground(activeBorder).
 			Width(paneWidth - 4) // Account for border width
 
 		searchContent := m.searchInput.View()
 		if !m.searchFocus {
-			hints := "n: next • N: prev • Esc: exit"
-			hintsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+			hints := strings.Join([]string{
+				keyHint(m.keys.NextMatch, "next"),
+				keyHint(m.keys.PrevMatch, "prev"),
+				keyHint(m.keys.ExitSearch, "exit"),
+			}, " • ")
+			hintsStyle := lipgloss.NewStyle().Foreground(hintColor)
 
 			// Calculate available space
 			availableWidth := paneWidth - 6 // -4 for outer margin, -2 for border
@@ -178,6 +182,16 @@ func (m Model) renderExplorer(paneWidth, paneHeight int) string {
 		Render(currentView)
 }
 
+// tabLabels returns the left pane's tab titles in LeftTab order. In Smart
+// Mode the second tab is renamed "Affected Suite".
+func (m Model) tabLabels() []string {
+	watchedTabLabel := "Watched"
+	if m.engine.IsSmartMode() {
+		watchedTabLabel = "Affected Suite"
+	}
+	return []string{m.explorerTabLabel(), watchedTabLabel, m.statsTabLabel()}
+}
+
 func (m Model) calculateVisibleRange(paneHeight int) (int, int) {
 	start := 0
 	end := len(m.flatNodes)
@@ -206,7 +220,7 @@ func (m Model) renderNode(b *strings.Builder, node DisplayNode, index int) {
 	// Use the pre-calculated depth from DisplayNode
 	indent := strings.Repeat("  ", node.Depth)
 
-	icon := m.getNodeIcon(node.Node)
+	icon := m.getNodeIcon(node)
 
 	// Check if watched
 	watchIcon := "  "
@@ -234,26 +248,44 @@ func (m Model) renderNode(b *strings.Builder, node DisplayNode, index int) {
 				}
 				idx += lastIdx
 				sb.WriteString(name[lastIdx:idx])
-				sb.WriteString(lipgloss.NewStyle().Background(lipgloss.Color("212")).Foreground(lipgloss.Color("0")).Render(name[idx : idx+len(lowerQuery)]))
+				sb.WriteString(searchMatchStyle.Render(name[idx : idx+len(lowerQuery)]))
 				lastIdx = idx + len(lowerQuery)
 			}
 			name = sb.String()
 		}
 	}
 
-	line := fmt.Sprintf("%s %s%s%s %s", cursor, indent, watchIcon, icon, name)
+	mark := " "
+	if m.isMarked(index) {
+		mark = "+"
+	}
 
-	if m.cursor == index {
+	line := fmt.Sprintf("%s%s%s%s%s %s", cursor, mark, indent, watchIcon, icon, name)
+	if node.IsDir {
+		line += m.renderDirCounts(node.Node)
+	}
+
+	switch {
+	case m.isMarked(index):
+		style := markedStyle
+		if m.cursor == index {
+			style = style.Foreground(highlight)
+		}
+		b.WriteString(style.Render(line))
+	case m.cursor == index:
 		b.WriteString(lipgloss.NewStyle().Foreground(highlight).Render(line))
-	} else {
+	default:
 		b.WriteString(line)
 	}
 	b.WriteByte('\n')
 }
 
-func (m Model) getNodeIcon(node *filesystem.Node) string {
+func (m Model) getNodeIcon(node DisplayNode) string {
 	if node.IsDir {
-		return "📁"
+		if node.Collapsed {
+			return "📁"
+		}
+		return "📂"
 	}
 	status, ok := m.engine.GetNodeStatus(node.Path)
 	if !ok {
@@ -261,3 +293,100 @@ func (m Model) getNodeIcon(node *filesystem.Node) string {
 	}
 	return StatusIcon(status)
 }
+
+// dirCounts holds the pass/fail/running counts of the test files beneath a
+// directory.
+type dirCounts struct {
+	passed, failed, running int
+}
+
+// refreshDirCounts recomputes the per-directory counts when a test status or
+// the tree changed since they were last computed.
+func (m *Model) refreshDirCounts() {
+	if m.dirCounts == nil || m.dirCountsVersion != m.engine.StatusVersion() || m.dirCountsTreeVersion != m.engine.TreeVersion() {
+		m.rebuildDirCounts()
+	}
+}
+
+// rebuildDirCounts aggregates the status of every test file into its
+// directories in a single walk of the tree.
+func (m *Model) rebuildDirCounts() {
+	m.dirCounts = make(map[string]dirCounts)
+	m.dirCountsVersion = m.engine.StatusVersion()
+	m.dirCountsTreeVersion = m.engine.TreeVersion()
+
+	var walk func(node *filesystem.Node) dirCounts
+	walk = func(node *filesystem.Node) dirCounts {
+		var c dirCounts
+		if !node.IsDir {
+			c.passed, c.failed, c.running = m.engine.GetStatusCounts([]string{node.Path})
+			return c
+		}
+		for _, child := range node.Children {
+			cc := walk(child)
+			c.passed += cc.passed
+			c.failed += cc.failed
+			c.running += cc.running
+		}
+		m.dirCounts[node.Path] = c
+		return c
+	}
+	if tree := m.engine.GetTree(); tree != nil {
+		walk(tree)
+	}
+}
+
+// renderDirCounts renders the aggregated pass/fail/running counts of the test
+// files beneath a directory, e.g. " ✅3 ❌1". It is empty when none have run.
+func (m Model) renderDirCounts(node *filesystem.Node) string {
+	c := m.dirCounts[node.Path]
+	var parts []string
+	if c.passed > 0 {
+		parts = append(parts, fmt.Sprintf("%s%d", StatusIcon(engine.StatusPass), c.passed))
+	}
+	if c.failed > 0 {
+		parts = append(parts, fmt.Sprintf("%s%d", StatusIcon(engine.StatusFail), c.failed))
+	}
+	if c.running > 0 {
+		parts = append(parts, fmt.Sprintf("%s%d", StatusIcon(engine.StatusRunning), c.running))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " " + lipgloss.NewStyle().Foreground(subtle).Render(strings.Join(parts, " "))
+}
+
+// runNode runs the selected explorer node: a file is triggered directly, and
+// a directory queues every test file beneath it.
+func (m *Model) runNode(node DisplayNode) tea.Cmd {
+	if !node.IsDir {
+		return m.engine.TriggerTest(node.Node)
+	}
+	paths := node.Files()
+	if len(paths) == 0 {
+		return nil
+	}
+	return tea.Batch(
+		m.engine.EnqueueTests(paths),
+		notify(fmt.Sprintf("Queued %d tests in %s.", len(paths), node.DisplayName), false),
+	)
+}
+
+// toggleWatchNode toggles watching the selected explorer node. For a
+// directory, the whole subtree is unwatched if every file in it is already
+// watched, and watched otherwise.
+func (m *Model) toggleWatchNode(node DisplayNode) {
+	if !node.IsDir {
+		m.engine.ToggleWatch(node.Path)
+		return
+	}
+	paths := node.Files()
+	allWatched := true
+	for _, path := range paths {
+		if !m.engine.IsWatched(path) {
+			allWatched = false
+			break
+		}
+	}
+	m.engine.SetWatched(paths, !allWatched)
+}
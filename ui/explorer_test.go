@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/engine"
+	"github.com/jesspatton/lazytest/filesystem"
+)
+
+// dirModel returns a model whose explorer shows the tree
+// root -> src(dir) -> [a.test.ts, nested(dir) -> b.test.ts].
+func dirModel(t *testing.T) Model {
+	t.Helper()
+	root := &filesystem.Node{Name: ".", Path: "/p", IsDir: true}
+	src := &filesystem.Node{Name: "src", Path: "/p/src", IsDir: true, Parent: root}
+	nested := &filesystem.Node{Name: "nested", Path: "/p/src/nested", IsDir: true, Parent: src}
+	a := &filesystem.Node{Name: "a.test.ts", Path: "/p/src/a.test.ts", Parent: src}
+	b := &filesystem.Node{Name: "b.test.ts", Path: "/p/src/nested/b.test.ts", Parent: nested}
+	nested.Children = []*filesystem.Node{b}
+	src.Children = []*filesystem.Node{nested, a}
+	root.Children = []*filesystem.Node{src}
+
+	eng := engine.New(t.TempDir())
+	eng.ProjectConfig.MaxConcurrentTests = 0
+	m := NewModel(eng)
+	m.flatNodes = flattenNodes(root)
+	return m
+}
+
+func TestExplorer_DirectoriesAreSelectable(t *testing.T) {
+	m := dirModel(t)
+
+	// src -> nested -> b.test.ts -> a.test.ts, with no directory skipped.
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	if !m.flatNodes[m.cursor].IsDir || m.flatNodes[m.cursor].DisplayName != "nested" {
+		t.Errorf("Expected cursor on 'nested' directory, got %q", m.flatNodes[m.cursor].DisplayName)
+	}
+}
+
+func TestExplorer_EnterOnDirectoryQueuesSubtree(t *testing.T) {
+	m := dirModel(t)
+
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	want := []string{"/p/src/nested/b.test.ts", "/p/src/a.test.ts"}
+	if !reflect.DeepEqual(m.engine.State.Queue, want) {
+		t.Errorf("Expected subtree queued %v, got %v", want, m.engine.State.Queue)
+	}
+}
+
+func TestExplorer_WatchDirectoryTogglesSubtree(t *testing.T) {
+	m := dirModel(t)
+
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	if got := len(m.engine.GetWatchedFiles()); got != 2 {
+		t.Fatalf("Expected 2 watched files, got %d", got)
+	}
+
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	if got := len(m.engine.GetWatchedFiles()); got != 0 {
+		t.Errorf("Expected subtree to be unwatched, got %d watched", got)
+	}
+}
+
+func TestExplorer_DirectoryCounts(t *testing.T) {
+	m := foldModel(t)
+	m.engine.State.NodeStatus["/p/src/a.test.ts"] = engine.StatusPass
+	m.engine.State.NodeStatus["/p/src/nested/b.test.ts"] = engine.StatusFail
+	m.rebuildDirCounts()
+
+	got := m.renderDirCounts(m.flatNodes[0].Node)
+	if got == "" {
+		t.Fatal("Expected aggregated counts for src")
+	}
+	if m.renderDirCounts(&filesystem.Node{IsDir: true}) != "" {
+		t.Error("Expected no counts for an empty directory")
+	}
+}
+
+func TestExplorer_DirectoryCountsFollowInPlaceTreeChanges(t *testing.T) {
+	m := dirModel(t)
+	root := &filesystem.Node{Name: ".", Path: "/p", IsDir: true, ChildrenMap: make(map[string]*filesystem.Node)}
+	root.AddNode("/p/src/a.test.ts")
+	root.AddNode("/p/src/nested/b.test.ts")
+	m.engine.State.Tree = root
+	m.engine.State.NodeStatus["/p/src/nested/b.test.ts"] = engine.StatusFail
+	m.rebuildDirCounts()
+	if m.dirCounts["/p/src/nested"].failed != 1 {
+		t.Fatalf("Expected the failure counted under nested, got %+v", m.dirCounts["/p/src/nested"])
+	}
+
+	// Renaming the test to a non-test file removes its node from the tree
+	// without replacing the tree.
+	updated, _ := m.Update(engine.RenameMsg{From: "/p/src/nested/b.test.ts", To: "/p/src/nested/b.ts"})
+	m = updated.(Model)
+	if got := m.dirCounts["/p/src/nested"]; got.failed != 0 {
+		t.Errorf("Expected the removed test to drop out of the counts, got %+v", got)
+	}
+}
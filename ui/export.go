@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/engine"
+	"github.com/jesspatton/lazytest/output"
+)
+
+// selectedOutput returns the path of the file selected in the active tab and
+// its output as currently displayed (output filters applied).
+func (m *Model) selectedOutput() (string, string, bool) {
+	path, ok := m.selectedPath()
+	if !ok {
+		return "", "", false
+	}
+	out, ok := m.testOutput(path)
+	if !ok || out == "" {
+		return path, "", false
+	}
+	return path, out, true
+}
+
+// notify wraps a message in a command that raises a toast notification.
+func notify(message string, isError bool) tea.Cmd {
+	return func() tea.Msg {
+		return engine.NotificationMsg{Message: message, IsError: isError}
+	}
+}
+
+// copyOutput copies the selected test's output to the clipboard. The plain
+// variant copies the output as displayed with ANSI stripped; the raw variant
+// copies the unfiltered output exactly as the runner produced it. The OSC 52
+// escape sequence is always emitted so copying works over SSH and inside tmux;
+// it is written through tea.Exec so it reaches the program's output while
+// rendering is paused instead of racing the renderer. The native clipboard is
+// then tried for terminals that ignore OSC 52 (see copyNative).
+func (m *Model) copyOutput(raw bool) tea.Cmd {
+	path, out, ok := m.selectedOutput()
+	if raw && ok {
+		out, ok = m.engine.GetTestOutput(path)
+		ok = ok && out != ""
+	}
+	if !ok {
+		return notify("No output to copy.", true)
+	}
+
+	kind := "raw"
+	if !raw {
+		out = output.StripANSI(out)
+		kind = "plain"
+	}
+
+	seq := osc52.New(out)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+	name := filepath.Base(path)
+	return tea.Exec(&osc52Command{seq: seq}, func(err error) tea.Msg {
+		return osc52CopiedMsg{text: out, kind: kind, name: name, err: err}
+	})
+}
+
+// osc52CopiedMsg reports that the OSC 52 sequence for a copy was written, so
+// the native clipboard can be tried next.
+type osc52CopiedMsg struct {
+	text string
+	kind string
+	name string
+	err  error
+}
+
+// osc52Command writes an OSC 52 sequence to the program's output. It runs as
+// a tea.ExecCommand so the write cannot interleave with a frame.
+type osc52Command struct {
+	seq    osc52.Sequence
+	stdout io.Writer
+}
+
+func (c *osc52Command) Run() error {
+	_, err := c.seq.WriteTo(c.stdout)
+	return err
+}
+
+func (c *osc52Command) SetStdin(io.Reader)    {}
+func (c *osc52Command) SetStdout(w io.Writer) { c.stdout = w }
+func (c *osc52Command) SetStderr(io.Writer)   {}
+
+// copyNative writes the copied text to the native clipboard and reports the
+// result. It runs as a command because the native clipboard shells out to
+// xclip, xsel or pbcopy, which can be slow or missing.
+func copyNative(msg osc52CopiedMsg) tea.Cmd {
+	return func() tea.Msg {
+		clipErr := clipboard.WriteAll(msg.text)
+		if msg.err != nil && clipErr != nil {
+			return engine.NotificationMsg{Message: fmt.Sprintf("Failed to copy output: %v", clipErr), IsError: true}
+		}
+		return engine.NotificationMsg{Message: fmt.Sprintf("Copied %s output of %s to clipboard.", msg.kind, msg.name)}
+	}
+}
+
+// saveOutput writes the selected test's output, ANSI stripped, to a log file
+// in the system temp directory and reports the file path.
+func (m *Model) saveOutput() tea.Cmd {
+	path, out, ok := m.selectedOutput()
+	if !ok {
+		return notify("No output to save.", true)
+	}
+
+	name := fmt.Sprintf("lazytest-%s-%s.log", filepath.Base(path), time.Now().Format("20060102-150405"))
+	dest := filepath.Join(os.TempDir(), name)
+	if err := os.WriteFile(dest, []byte(output.StripANSI(out)), 0644); err != nil {
+		return notify(fmt.Sprintf("Failed to save output: %v", err), true)
+	}
+	return notify(fmt.Sprintf("Saved output to %s", dest), false)
+}
+
+// openInPager suspends the TUI and opens the selected test's output in $PAGER
+// (falling back to `less -R`, or `more` on Windows).
+func (m *Model) openInPager() tea.Cmd {
+	path, out, ok := m.selectedOutput()
+	if !ok {
+		return notify("No output to page.", true)
+	}
+
+	f, err := os.CreateTemp("", "lazytest-"+filepath.Base(path)+"-*.log")
+	if err != nil {
+		return notify(fmt.Sprintf("Failed to open pager: %v", err), true)
+	}
+	_, err = f.WriteString(out)
+	f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		return notify(fmt.Sprintf("Failed to open pager: %v", err), true)
+	}
+
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{"less", "-R"}
+		if runtime.GOOS == "windows" {
+			pager = []string{"more"}
+		}
+	}
+	cmd := exec.Command(pager[0], append(pager[1:], f.Name())...)
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		os.Remove(f.Name())
+		if err != nil {
+			return engine.NotificationMsg{Message: fmt.Sprintf("Pager exited with error: %v", err), IsError: true}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/jesspatton/lazytest/engine"
+	"github.com/jesspatton/lazytest/filesystem"
+)
+
+func TestSaveOutput_WritesStrippedLog(t *testing.T) {
+	eng := engine.New(t.TempDir())
+	m := NewModel(eng)
+
+	path := "/tmp/project/sum.test.js"
+	m.flatNodes = []DisplayNode{{Node: filesystem.NodeFromPath(path), DisplayName: "sum.test.js"}}
+	eng.State.TestOutputs[path] = []string{"\x1b[31mFAIL\x1b[0m sum\n"}
+
+	msg := m.saveOutput()()
+	note, ok := msg.(engine.NotificationMsg)
+	if !ok || note.IsError {
+		t.Fatalf("Expected success notification, got %#v", msg)
+	}
+
+	dest := strings.TrimPrefix(note.Message, "Saved output to ")
+	defer os.Remove(dest)
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("Expected log file at %s: %v", dest, err)
+	}
+	if string(data) != "FAIL sum\n" {
+		t.Errorf("Expected ANSI-stripped output, got %q", string(data))
+	}
+}
+
+func TestSaveOutput_NoSelection(t *testing.T) {
+	eng := engine.New(t.TempDir())
+	m := NewModel(eng)
+
+	msg := m.saveOutput()()
+	if note, ok := msg.(engine.NotificationMsg); !ok || !note.IsError {
+		t.Errorf("Expected error notification with nothing selected, got %#v", msg)
+	}
+}
+
+func TestOSC52Command_WritesToProgramOutput(t *testing.T) {
+	var out bytes.Buffer
+	c := &osc52Command{seq: osc52.New("FAIL sum")}
+	c.SetStdout(&out)
+	if err := c.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if want := osc52.New("FAIL sum").String(); out.String() != want {
+		t.Errorf("Expected %q on the program output, got %q", want, out.String())
+	}
+}
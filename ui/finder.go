@@ -0,0 +1,299 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jesspatton/lazytest/filesystem"
+)
+
+// finderResult is one ranked entry of the fuzzy finder.
+type finderResult struct {
+	Path      string
+	Text      string // Path relative to the project root, as matched
+	Score     int
+	Positions []int // Rune offsets into Text that matched the query
+	Source    bool  // True for a non-test source file
+}
+
+// openFinder opens the fuzzy file finder overlay with an empty query.
+func (m Model) openFinder() (Model, tea.Cmd) {
+	ti := textinput.New()
+	ti.Prompt = "> "
+	ti.Placeholder = "Find test file..."
+	ti.CharLimit = 156
+	ti.Focus()
+
+	m.finderMode = true
+	m.finderInput = ti
+	m.finderCursor = 0
+	m.refreshFinder()
+	return m, textinput.Blink
+}
+
+// closeFinder closes the finder overlay.
+func (m *Model) closeFinder() {
+	m.finderMode = false
+	m.finderResults = nil
+	m.finderInput.Blur()
+}
+
+// handleFinderKey processes a key message while the finder is open. Printable
+// keys edit the query; navigation uses arrows and ctrl-keys.
+func (m Model) handleFinderKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.ExitSearch):
+		m.closeFinder()
+		return m, nil
+	case key.Matches(msg, m.keys.FinderUp):
+		if m.finderCursor > 0 {
+			m.finderCursor--
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.FinderDown):
+		if m.finderCursor < len(m.finderResults)-1 {
+			m.finderCursor++
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.FinderSources):
+		m.finderSources = !m.finderSources
+		m.refreshFinder()
+		return m, nil
+	case key.Matches(msg, m.keys.Enter):
+		if m.finderCursor >= len(m.finderResults) {
+			return m, nil
+		}
+		result := m.finderResults[m.finderCursor]
+		m.closeFinder()
+		return m.jumpToResult(result)
+	case key.Matches(msg, m.keys.FinderRun):
+		if m.finderCursor >= len(m.finderResults) {
+			return m, nil
+		}
+		result := m.finderResults[m.finderCursor]
+		m.closeFinder()
+		return m.runResult(result)
+	}
+
+	previous := m.finderInput.Value()
+	var cmd tea.Cmd
+	m.finderInput, cmd = m.finderInput.Update(msg)
+	if m.finderInput.Value() != previous {
+		m.finderCursor = 0
+		m.refreshFinder()
+	}
+	return m, cmd
+}
+
+// refreshFinder re-ranks the candidates against the current query.
+func (m *Model) refreshFinder() {
+	query := strings.ReplaceAll(m.finderInput.Value(), " ", "")
+
+	var candidates []finderResult
+	if tree := m.engine.GetTree(); tree != nil {
+		for _, path := range tree.Files() {
+			candidates = append(candidates, finderResult{Path: path, Text: m.relPath(path)})
+		}
+	}
+	if m.finderSources {
+		for _, path := range m.engine.GetSourceFiles() {
+			candidates = append(candidates, finderResult{Path: path, Text: m.relPath(path), Source: true})
+		}
+	}
+
+	results := candidates[:0]
+	for _, c := range candidates {
+		score, positions, ok := fuzzyMatch(query, c.Text)
+		if !ok {
+			continue
+		}
+		c.Score, c.Positions = score, positions
+		results = append(results, c)
+	}
+	if query != "" {
+		sort.SliceStable(results, func(i, j int) bool {
+			if results[i].Score != results[j].Score {
+				return results[i].Score > results[j].Score
+			}
+			return len(results[i].Text) < len(results[j].Text)
+		})
+	}
+
+	m.finderResults = results
+	if m.finderCursor >= len(results) {
+		m.finderCursor = max(len(results)-1, 0)
+	}
+}
+
+// jumpToResult selects a test file in the explorer. For a source file it
+// selects the first test that depends on it.
+func (m Model) jumpToResult(result finderResult) (Model, tea.Cmd) {
+	path := result.Path
+	if result.Source {
+		related := m.engine.FindRelatedTests(path)
+		if len(related) == 0 {
+			return m, notify(fmt.Sprintf("No tests depend on %s.", filepath.Base(path)), true)
+		}
+		path = related[0]
+	}
+
+	m.jumpToPath(path)
+	return m, nil
+}
+
+// runResult jumps to a result and runs it. A source file queues every test
+// that depends on it.
+func (m Model) runResult(result finderResult) (Model, tea.Cmd) {
+	if result.Source {
+		related := m.engine.FindRelatedTests(result.Path)
+		if len(related) == 0 {
+			return m, notify(fmt.Sprintf("No tests depend on %s.", filepath.Base(result.Path)), true)
+		}
+		m, _ = m.jumpToResult(result)
+		return m, tea.Batch(
+			m.engine.EnqueueTests(related),
+			notify(fmt.Sprintf("Queued %d tests related to %s.", len(related), filepath.Base(result.Path)), false),
+		)
+	}
+
+	m, _ = m.jumpToResult(result)
+	return m, m.engine.TriggerTest(filesystem.NodeFromPath(result.Path))
+}
+
+// renderFinderView renders the full-screen finder: the query and ranked
+// results on the left, a preview of the highlighted file on the right.
+func (m Model) renderFinderView() string {
+	innerWidth := max(m.width-4, 0)
+	listWidth := innerWidth / 2
+	previewWidth := innerWidth - listWidth - 1
+	// Border(2), title(2), input(1), blank(1) and hints(1) vertically.
+	bodyHeight := max(m.height-7, 1)
+
+	var title strings.Builder
+	title.WriteString(titleStyle.Render("FIND"))
+	scope := "tests"
+	if m.finderSources {
+		scope = "tests + sources"
+	}
+	title.WriteString(statusStyle.Render(fmt.Sprintf("%d matches • %s", len(m.finderResults), scope)))
+
+	list := lipgloss.NewStyle().Width(listWidth).Height(bodyHeight).MaxHeight(bodyHeight).
+		Render(m.renderFinderResults(listWidth, bodyHeight))
+	separator := lipgloss.NewStyle().Foreground(subtle).
+		Render(strings.TrimSuffix(strings.Repeat("│\n", bodyHeight), "\n"))
+	preview := lipgloss.NewStyle().Width(previewWidth).Height(bodyHeight).MaxHeight(bodyHeight).
+		Render(m.renderFinderPreview(previewWidth, bodyHeight))
+
+	hints := statusStyle.Render(strings.Join([]string{
+		keyHint(m.keys.FinderUp, "up"),
+		keyHint(m.keys.FinderDown, "down"),
+		keyHint(m.keys.Enter, "jump"),
+		keyHint(m.keys.FinderRun, "run"),
+		keyHint(m.keys.FinderSources, "include sources"),
+		keyHint(m.keys.ExitSearch, "close"),
+	}, " • "))
+
+	body := lipgloss.JoinVertical(lipgloss.Left,
+		title.String(),
+		"",
+		m.finderInput.View(),
+		lipgloss.JoinHorizontal(lipgloss.Top, list, separator, preview),
+		hints,
+	)
+	return activePaneStyle.
+		Width(m.width - 2).
+		Height(m.height - 2).
+		Render(body)
+}
+
+// renderFinderResults renders the window of results around the cursor.
+func (m Model) renderFinderResults(width, height int) string {
+	if len(m.finderResults) == 0 {
+		return lipgloss.NewStyle().Foreground(subtle).Render("No matches")
+	}
+
+	start := 0
+	if m.finderCursor >= height {
+		start = m.finderCursor - height + 1
+	}
+	var b strings.Builder
+	for i := start; i < start+height && i < len(m.finderResults); i++ {
+		result := m.finderResults[i]
+		cursor := " "
+		if i == m.finderCursor {
+			cursor = ">"
+		}
+		icon := "📄"
+		if result.Source {
+			icon = "🔧"
+		} else if status, ok := m.engine.GetNodeStatus(result.Path); ok {
+			icon = StatusIcon(status)
+		}
+		line := fmt.Sprintf("%s %s %s", cursor, icon, highlightPositions(result.Text, result.Positions))
+		if i == m.finderCursor {
+			line = lipgloss.NewStyle().Foreground(highlight).Render(line)
+		}
+		b.WriteString(lipgloss.NewStyle().MaxWidth(width).Render(line))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// renderFinderPreview renders the tail of the highlighted test's last output,
+// or the tests that depend on a highlighted source file.
+func (m Model) renderFinderPreview(width, height int) string {
+	if m.finderCursor >= len(m.finderResults) {
+		return ""
+	}
+	result := m.finderResults[m.finderCursor]
+	dim := lipgloss.NewStyle().Foreground(subtle)
+
+	if result.Source {
+		related := m.engine.FindRelatedTests(result.Path)
+		if len(related) == 0 {
+			return dim.Render("No tests depend on this file.")
+		}
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("%d related tests\n\n", len(related)))
+		for _, path := range related {
+			b.WriteString("  " + m.relPath(path) + "\n")
+		}
+		return b.String()
+	}
+
+	out, ok := m.testOutput(result.Path)
+	if !ok || out == "" {
+		return dim.Render(fmt.Sprintf("No output yet. Press %s to run it.", m.keys.FinderRun.Help().Key))
+	}
+	lines := strings.Split(strings.TrimRight(m.wrapOutput(width, out), "\n"), "\n")
+	if len(lines) > height {
+		lines = lines[len(lines)-height:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlightPositions renders text with the runes at positions emphasised.
+func highlightPositions(text string, positions []int) string {
+	if len(positions) == 0 {
+		return text
+	}
+	matched := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		matched[p] = true
+	}
+	var b strings.Builder
+	for i, r := range []rune(text) {
+		if matched[i] {
+			b.WriteString(fuzzyMatchStyle.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	if _, _, ok := fuzzyMatch("xyz", "src/a.test.ts"); ok {
+		t.Error("Expected no match for absent characters")
+	}
+
+	_, positions, ok := fuzzyMatch("ats", "src/a.test.ts")
+	if !ok {
+		t.Fatal("Expected subsequence to match")
+	}
+	// "a" at the file name start and "ts" as the consecutive extension.
+	if want := []int{4, 11, 12}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("Expected positions %v, got %v", want, positions)
+	}
+
+	boundary, _, _ := fuzzyMatch("ut", "src/user/user.test.ts")
+	scattered, _, _ := fuzzyMatch("ut", "src/lib/output.test.ts")
+	if boundary <= scattered {
+		t.Errorf("Expected boundary match to outrank %d, got %d", scattered, boundary)
+	}
+
+	if _, _, ok := fuzzyMatch("User", "src/user.test.ts"); ok {
+		t.Error("Expected an upper-case query to match case-sensitively")
+	}
+}
+
+func TestFinder_RanksAndJumps(t *testing.T) {
+	m := foldModel(t)
+	m = sendKey(m, runes("z"))
+	m = sendKey(m, runes("M"))
+
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyCtrlP})
+	if !m.finderMode || len(m.finderResults) != 2 {
+		t.Fatalf("Expected finder with 2 test files, got mode=%v results=%d", m.finderMode, len(m.finderResults))
+	}
+
+	m = sendKey(m, runes("bts"))
+	if len(m.finderResults) != 1 || m.finderResults[0].Path != "/p/src/nested/b.test.ts" {
+		t.Fatalf("Expected only b.test.ts to match, got %+v", m.finderResults)
+	}
+
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.finderMode {
+		t.Error("Expected enter to close the finder")
+	}
+	if m.flatNodes[m.cursor].Path != "/p/src/nested/b.test.ts" {
+		t.Errorf("Expected cursor on revealed b.test.ts, got %v", displayNames(m))
+	}
+}
+
+func TestFinder_RunTriggersTest(t *testing.T) {
+	m := foldModel(t)
+
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = sendKey(m, runes("a.test"))
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyCtrlR})
+
+	if last := m.engine.State.LastRunNode; last == nil || last.Path != "/p/src/a.test.ts" {
+		t.Errorf("Expected a.test.ts to be triggered, got %+v", last)
+	}
+}
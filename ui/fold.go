@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/filesystem"
+)
+
+// rebuildFlatNodes re-flattens the tree with the current folds and status
+// filter, keeping the cursor on the same path when it is still visible. An
+// active visual range keeps its anchor path, and ends when either end is no
+// longer visible so it never silently covers other files.
+func (m *Model) rebuildFlatNodes() {
+	var current string
+	if m.cursor < len(m.flatNodes) {
+		current = m.flatNodes[m.cursor].Path
+	}
+
+	m.flatNodes = flattenVisibleNodes(m.engine.GetTree(), m.folded, m.statusFilterFunc())
+
+	idx := m.indexOfPath(current)
+	if idx >= 0 {
+		m.cursor = idx
+	} else if m.cursor >= len(m.flatNodes) {
+		m.cursor = max(len(m.flatNodes)-1, 0)
+	}
+
+	if m.visualMode {
+		m.visualAnchor = m.indexOfPath(m.visualAnchorPath)
+		if m.visualAnchor < 0 || idx < 0 {
+			m.visualMode = false
+		}
+	}
+}
+
+// indexOfPath returns the flatNodes index of path, or -1 if it is not visible.
+func (m Model) indexOfPath(path string) int {
+	if path == "" {
+		return -1
+	}
+	for i, node := range m.flatNodes {
+		if node.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// revealPath unfolds every directory above path so it becomes visible. When
+// moveCursor is true the cursor is placed on it.
+func (m *Model) revealPath(path string, moveCursor bool) {
+	root := m.engine.State.RootPath
+	changed := false
+	for dir := filepath.Dir(path); strings.HasPrefix(dir, root) && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if _, ok := m.folded[dir]; ok {
+			delete(m.folded, dir)
+			changed = true
+		}
+		if dir == root {
+			break
+		}
+	}
+	if changed {
+		m.rebuildFlatNodes()
+	}
+	if moveCursor {
+		// A status filter that hides the target is dropped so the jump lands.
+		if m.indexOfPath(path) < 0 && m.statusFilter != 0 {
+			m.statusFilter = 0
+			m.rebuildFlatNodes()
+		}
+		if idx := m.indexOfPath(path); idx >= 0 {
+			m.cursor = idx
+		}
+	}
+}
+
+// parentRow returns the index of the directory row that contains the row at
+// index, or -1 for top-level rows.
+func (m Model) parentRow(index int) int {
+	depth := m.flatNodes[index].Depth
+	for i := index - 1; i >= 0; i-- {
+		if m.flatNodes[i].IsDir && m.flatNodes[i].Depth < depth {
+			return i
+		}
+	}
+	return -1
+}
+
+// collapse folds the directory under the cursor. On a file or an already
+// folded directory it moves the cursor to the parent directory instead.
+func (m *Model) collapse() {
+	if m.cursor >= len(m.flatNodes) {
+		return
+	}
+	node := m.flatNodes[m.cursor]
+	if node.IsDir && !node.Collapsed {
+		m.folded[node.Path] = struct{}{}
+		m.rebuildFlatNodes()
+		return
+	}
+	if parent := m.parentRow(m.cursor); parent >= 0 {
+		m.cursor = parent
+	}
+}
+
+// expand unfolds the directory under the cursor. On an expanded directory it
+// moves the cursor to the first child.
+func (m *Model) expand() {
+	if m.cursor >= len(m.flatNodes) {
+		return
+	}
+	node := m.flatNodes[m.cursor]
+	if !node.IsDir {
+		return
+	}
+	if node.Collapsed {
+		delete(m.folded, node.Path)
+		m.rebuildFlatNodes()
+		return
+	}
+	if m.cursor+1 < len(m.flatNodes) && m.flatNodes[m.cursor+1].Depth > node.Depth {
+		m.cursor++
+	}
+}
+
+// foldAll folds every directory in the tree.
+func (m *Model) foldAll() {
+	var walk func(*filesystem.Node)
+	walk = func(n *filesystem.Node) {
+		for _, child := range n.Children {
+			if child.IsDir {
+				m.folded[child.Path] = struct{}{}
+				walk(child)
+			}
+		}
+	}
+	if tree := m.engine.GetTree(); tree != nil {
+		walk(tree)
+	}
+	m.rebuildFlatNodes()
+}
+
+// unfoldAll removes every fold.
+func (m *Model) unfoldAll() {
+	m.folded = make(map[string]struct{})
+	m.rebuildFlatNodes()
+}
+
+// handleFoldKey interprets the key following the "z" prefix: zc folds the
+// directory under (or containing) the cursor, zo unfolds it, za toggles it, zM
+// folds everything and zR unfolds everything.
+func (m Model) handleFoldKey(msg tea.KeyMsg) Model {
+	m.pendingZ = false
+	if m.cursor >= len(m.flatNodes) {
+		return m
+	}
+	node := m.flatNodes[m.cursor]
+
+	switch {
+	case key.Matches(msg, m.keys.FoldClose):
+		if !node.IsDir {
+			if parent := m.parentRow(m.cursor); parent >= 0 {
+				m.cursor = parent
+				node = m.flatNodes[parent]
+			}
+		}
+		if node.IsDir {
+			m.folded[node.Path] = struct{}{}
+			m.rebuildFlatNodes()
+		}
+	case key.Matches(msg, m.keys.FoldOpen):
+		if node.IsDir {
+			delete(m.folded, node.Path)
+			m.rebuildFlatNodes()
+		}
+	case key.Matches(msg, m.keys.FoldToggle):
+		if node.IsDir {
+			if node.Collapsed {
+				delete(m.folded, node.Path)
+			} else {
+				m.folded[node.Path] = struct{}{}
+			}
+			m.rebuildFlatNodes()
+		}
+	case key.Matches(msg, m.keys.FoldAll):
+		m.foldAll()
+	case key.Matches(msg, m.keys.UnfoldAll):
+		m.unfoldAll()
+	}
+	m.syncViewportOutput()
+	return m
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/engine"
+	"github.com/jesspatton/lazytest/runner"
+)
+
+// foldModel returns dirModel with the tree installed in the engine so folds
+// can re-flatten it, rooted at the tree's root path.
+func foldModel(t *testing.T) Model {
+	t.Helper()
+	m := dirModel(t)
+	m.engine.State.Tree = m.flatNodes[0].Parent
+	m.engine.State.RootPath = "/p"
+	return m
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func displayNames(m Model) []string {
+	var names []string
+	for _, n := range m.flatNodes {
+		names = append(names, n.DisplayName)
+	}
+	return names
+}
+
+func TestFold_CollapseAndExpand(t *testing.T) {
+	m := foldModel(t)
+
+	m = sendKey(m, runes("h"))
+	if len(m.flatNodes) != 1 || !m.flatNodes[0].Collapsed {
+		t.Fatalf("Expected only collapsed 'src', got %v", displayNames(m))
+	}
+
+	m = sendKey(m, runes("l"))
+	if len(m.flatNodes) != 4 || m.flatNodes[0].Collapsed {
+		t.Fatalf("Expected expanded tree, got %v", displayNames(m))
+	}
+
+	// On an expanded directory, l moves into it and h returns to the parent.
+	m = sendKey(m, runes("l"))
+	if m.flatNodes[m.cursor].DisplayName != "nested" {
+		t.Errorf("Expected cursor on 'nested', got %q", m.flatNodes[m.cursor].DisplayName)
+	}
+	m = sendKey(m, runes("j"))
+	m = sendKey(m, runes("h"))
+	if m.flatNodes[m.cursor].DisplayName != "nested" {
+		t.Errorf("Expected h on a file to move to its directory, got %q", m.flatNodes[m.cursor].DisplayName)
+	}
+}
+
+func TestFold_ZPrefixCommands(t *testing.T) {
+	m := foldModel(t)
+
+	m = sendKey(m, runes("z"))
+	m = sendKey(m, runes("M"))
+	if len(m.flatNodes) != 1 {
+		t.Fatalf("Expected zM to fold everything, got %v", displayNames(m))
+	}
+
+	m = sendKey(m, runes("z"))
+	m = sendKey(m, runes("o"))
+	if got := displayNames(m); len(got) != 3 || !m.flatNodes[1].Collapsed {
+		t.Fatalf("Expected zo to open only 'src', got %v", got)
+	}
+
+	m = sendKey(m, runes("z"))
+	m = sendKey(m, runes("R"))
+	if len(m.flatNodes) != 4 || m.pendingZ {
+		t.Fatalf("Expected zR to unfold everything, got %v", displayNames(m))
+	}
+}
+
+func TestFold_SurvivesTreeReload(t *testing.T) {
+	m := foldModel(t)
+	m = sendKey(m, runes("j"))
+	m = sendKey(m, runes("h"))
+
+	newModel, _ := m.Update(engine.TreeLoadedMsg(m.engine.GetTree()))
+	m = newModel.(Model)
+	if len(m.flatNodes) != 3 || !m.flatNodes[1].Collapsed {
+		t.Errorf("Expected 'nested' to stay folded, got %v", displayNames(m))
+	}
+}
+
+func TestFold_RevealsSearchMatchAndFailure(t *testing.T) {
+	m := foldModel(t)
+	m = sendKey(m, runes("z"))
+	m = sendKey(m, runes("M"))
+
+	m = sendKey(m, runes("/"))
+	m = sendKey(m, runes("b.test"))
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.flatNodes[m.cursor].Path != "/p/src/nested/b.test.ts" {
+		t.Fatalf("Expected search to reveal b.test.ts, got %v", displayNames(m))
+	}
+
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyEsc})
+	m = sendKey(m, runes("z"))
+	m = sendKey(m, runes("M"))
+	newModel, _ := m.Update(runner.StatusUpdate{FilePath: "/p/src/a.test.ts", Err: errors.New("boom")})
+	m = newModel.(Model)
+	if m.indexOfPath("/p/src/a.test.ts") < 0 {
+		t.Errorf("Expected failing test to be revealed, got %v", displayNames(m))
+	}
+}
@@ -1,6 +1,10 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 func (m Model) renderFooter() string {
 	m.help.ShowAll = false
@@ -33,10 +37,30 @@ func (m Model) renderFooter() string {
 	var buildingLabel string
 	if m.engine.State.IsBuildingGraph {
 		buildingLabel = lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#0284C7", Dark: "#38BDF8"}).
+			Foreground(buildingColor).
 			Padding(0, 1).
 			Render("⏳ Building Graph...")
 	}
 
-	return lipgloss.JoinHorizontal(lipgloss.Center, leftComponent, buildingLabel, modeLabel)
+	var selectionLabel string
+	if m.hasSelection() {
+		label := fmt.Sprintf("✚ %d marked", len(m.markedPaths()))
+		if m.visualMode {
+			label += " (visual)"
+		}
+		selectionLabel = lipgloss.NewStyle().
+			Foreground(highlight).
+			Padding(0, 1).
+			Render(label)
+	}
+
+	var zoomLabel string
+	if m.layout.zoomed {
+		zoomLabel = lipgloss.NewStyle().
+			Foreground(highlight).
+			Padding(0, 1).
+			Render("[ZOOM]")
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Center, leftComponent, buildingLabel, selectionLabel, zoomLabel, modeLabel)
 }
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"math"
+	"unicode"
+)
+
+// Fuzzy scoring weights, loosely modelled on fzf: every matched character
+// scores, matches at word boundaries and runs of consecutive matches score
+// extra, and gaps between matches cost a little.
+const (
+	fuzzyMatchScore       = 16
+	fuzzyBoundaryBonus    = 8
+	fuzzySeparatorBonus   = 10 // Match right after a path separator
+	fuzzyCamelBonus       = 7
+	fuzzyConsecutiveBonus = 6
+	fuzzyGapStart         = 3
+	fuzzyGapExtension     = 1
+)
+
+// fuzzyMatch reports whether every rune of pattern appears in text in order
+// and, if so, the best alignment's score and the rune positions it matched.
+// Matching is case-insensitive unless pattern contains an upper-case letter.
+func fuzzyMatch(pattern, text string) (int, []int, bool) {
+	p, t := []rune(pattern), []rune(text)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+	if len(p) > len(t) {
+		return 0, nil, false
+	}
+
+	caseSensitive := false
+	for _, r := range p {
+		if unicode.IsUpper(r) {
+			caseSensitive = true
+			break
+		}
+	}
+	fold := func(r rune) rune {
+		if caseSensitive {
+			return r
+		}
+		return unicode.ToLower(r)
+	}
+
+	const none = math.MinInt32
+	n, m := len(p), len(t)
+	// score[i][j] is the best score of matching p[:i+1] with p[i] at t[j];
+	// from[i][j] is where p[i-1] sits in that alignment.
+	score := make([][]int, n)
+	from := make([][]int, n)
+	for i := range score {
+		score[i] = make([]int, m)
+		from[i] = make([]int, m)
+		for j := range score[i] {
+			score[i][j] = none
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		// carry is the best gapped predecessor score for the current j,
+		// already charged for the gap up to j.
+		carry, carryFrom := none, -1
+		for j := i; j < m; j++ {
+			if i > 0 && j >= 2 {
+				if carry != none {
+					carry -= fuzzyGapExtension
+				}
+				if prev := score[i-1][j-2]; prev != none && prev-fuzzyGapStart > carry {
+					carry, carryFrom = prev-fuzzyGapStart, j-2
+				}
+			}
+			if fold(t[j]) != fold(p[i]) {
+				continue
+			}
+
+			gain := fuzzyMatchScore + fuzzyBonus(t, j)
+			if i == 0 {
+				score[i][j] = gain
+				continue
+			}
+			if prev := score[i-1][j-1]; prev != none {
+				score[i][j], from[i][j] = prev+gain+fuzzyConsecutiveBonus, j-1
+			}
+			if carry != none && carry+gain > score[i][j] {
+				score[i][j], from[i][j] = carry+gain, carryFrom
+			}
+		}
+	}
+
+	best, end := none, -1
+	for j := n - 1; j < m; j++ {
+		if score[n-1][j] > best {
+			best, end = score[n-1][j], j
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	positions := make([]int, n)
+	for i, j := n-1, end; i >= 0; i-- {
+		positions[i] = j
+		j = from[i][j]
+	}
+	return best, positions, true
+}
+
+// fuzzyBonus returns the boundary bonus for a match at t[j].
+func fuzzyBonus(t []rune, j int) int {
+	if j == 0 {
+		return fuzzyBoundaryBonus
+	}
+	prev, cur := t[j-1], t[j]
+	switch {
+	case prev == '/' || prev == '\\':
+		return fuzzySeparatorBonus
+	case prev == '_' || prev == '-' || prev == '.' || prev == ' ':
+		return fuzzyBoundaryBonus
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return fuzzyCamelBonus
+	}
+	return 0
+}
@@ -0,0 +1,243 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+)
+
+// keyAction names a KeyMap binding for the user config.
+type keyAction struct {
+	name    string
+	binding *key.Binding
+}
+
+// actions lists every configurable binding by its config name.
+func (k *KeyMap) actions() []keyAction {
+	return []keyAction{
+		{"up", &k.Up},
+		{"down", &k.Down},
+		{"enter", &k.Enter},
+		{"tab", &k.Tab},
+		{"rerun_last", &k.ReRunLast},
+		{"refresh", &k.Refresh},
+		{"help", &k.Help},
+		{"quit", &k.Quit},
+		{"toggle_mark", &k.ToggleMark},
+		{"visual_mode", &k.VisualMode},
+		{"unwatch_marked", &k.UnwatchMarked},
+		{"clear_marks", &k.ClearMarks},
+		{"collapse", &k.Collapse},
+		{"expand", &k.Expand},
+		{"fold_prefix", &k.FoldPrefix},
+		{"fold_close", &k.FoldClose},
+		{"fold_open", &k.FoldOpen},
+		{"fold_toggle", &k.FoldToggle},
+		{"fold_all", &k.FoldAll},
+		{"unfold_all", &k.UnfoldAll},
+		{"filter_failing", &k.FilterFailing},
+		{"filter_running", &k.FilterRunning},
+		{"filter_never_run", &k.FilterNeverRun},
+		{"filter_passed", &k.FilterPassed},
+		{"filter_watched", &k.FilterWatched},
+		{"clear_status_filter", &k.ClearStatusFilter},
+		{"search", &k.Search},
+		{"next_match", &k.NextMatch},
+		{"prev_match", &k.PrevMatch},
+		{"exit_search", &k.ExitSearch},
+		{"next_tab", &k.NextTab},
+		{"prev_tab", &k.PrevTab},
+		{"toggle_watch", &k.ToggleWatch},
+		{"clear_watched", &k.ClearWatched},
+		{"add_related", &k.AddRelated},
+		{"toggle_smart_mode", &k.ToggleSmartMode},
+		{"run_failures", &k.RunFailures},
+		{"why_affected", &k.WhyAffected},
+		{"stats_scope", &k.StatsScope},
+		{"filter_failures", &k.FilterFailures},
+		{"filter_console", &k.FilterConsole},
+		{"filter_stderr", &k.FilterStderr},
+		{"filter_ansi", &k.FilterANSI},
+		{"copy_output", &k.CopyOutput},
+		{"copy_raw_output", &k.CopyRawOutput},
+		{"save_output", &k.SaveOutput},
+		{"open_pager", &k.OpenPager},
+		{"diff_view", &k.DiffView},
+		{"snapshot_review", &k.SnapshotReview},
+		{"confirm", &k.Confirm},
+		{"cancel", &k.Cancel},
+		{"fuzzy_finder", &k.FuzzyFinder},
+		{"finder_up", &k.FinderUp},
+		{"finder_down", &k.FinderDown},
+		{"finder_run", &k.FinderRun},
+		{"finder_sources", &k.FinderSources},
+		{"grow_explorer", &k.GrowExplorer},
+		{"shrink_explorer", &k.ShrinkExplorer},
+		{"reset_split", &k.ResetSplit},
+		{"toggle_orientation", &k.ToggleOrientation},
+		{"zoom_output", &k.ZoomOutput},
+	}
+}
+
+// keyScopes groups the actions that are live at the same time. Two actions
+// in one scope must not share a key; actions in different scopes may (e.g.
+// "y" copies output normally but confirms inside the snapshot prompt).
+var keyScopes = map[string][]string{
+	"normal": {
+		"up", "down", "enter", "tab", "rerun_last", "refresh", "help", "quit",
+		"toggle_mark", "visual_mode", "unwatch_marked", "clear_marks",
+		"collapse", "expand", "fold_prefix",
+		"filter_failing", "filter_running", "filter_never_run", "filter_passed", "filter_watched", "clear_status_filter",
+		"search", "next_match", "prev_match", "next_tab", "prev_tab",
+		"toggle_watch", "clear_watched", "add_related", "toggle_smart_mode", "run_failures", "why_affected", "stats_scope",
+		"filter_failures", "filter_console", "filter_stderr", "filter_ansi",
+		"copy_output", "copy_raw_output", "save_output", "open_pager",
+		"diff_view", "snapshot_review", "fuzzy_finder",
+		"grow_explorer", "shrink_explorer", "reset_split", "toggle_orientation", "zoom_output",
+	},
+	"search":    {"exit_search", "search", "next_match", "prev_match", "enter"},
+	"fold":      {"fold_close", "fold_open", "fold_toggle", "fold_all", "unfold_all"},
+	"diff view": {"exit_search", "diff_view", "next_match", "prev_match", "quit", "up", "down"},
+	"snapshots": {"exit_search", "snapshot_review", "quit", "up", "down", "enter"},
+	"confirm":   {"confirm", "cancel", "exit_search"},
+	"finder":    {"exit_search", "finder_up", "finder_down", "finder_run", "finder_sources", "enter"},
+}
+
+// foldActions are the second strokes of the fold prefix; their help label
+// includes the prefix key.
+var foldActions = map[string]bool{
+	"fold_close": true, "fold_open": true, "fold_toggle": true, "fold_all": true, "unfold_all": true,
+}
+
+// ApplyOverrides rebinds the actions named in overrides to the given keys,
+// keeping each action's help description. Unknown action names and keys
+// shared by two actions in the same scope are reported as an error, in which
+// case k is left unchanged.
+func (k *KeyMap) ApplyOverrides(overrides map[string][]string) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	updated := *k
+	byName := make(map[string]*key.Binding)
+	for _, a := range updated.actions() {
+		byName[a.name] = a.binding
+	}
+
+	var unknown []string
+	for name, keys := range overrides {
+		binding, ok := byName[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		binding.SetKeys(keys...)
+		binding.SetHelp(helpKeyLabel(keys), binding.Help().Desc)
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown keybinding action(s): %s", strings.Join(unknown, ", "))
+	}
+
+	// Fold commands are shown with their prefix ("zc").
+	_, prefixChanged := overrides["fold_prefix"]
+	if prefix := updated.FoldPrefix.Keys(); len(prefix) > 0 {
+		for _, a := range updated.actions() {
+			if _, changed := overrides[a.name]; foldActions[a.name] && (changed || prefixChanged) {
+				a.binding.SetHelp(prefix[0]+helpKeyLabel(a.binding.Keys()), a.binding.Help().Desc)
+			}
+		}
+	}
+
+	if err := updated.checkConflicts(); err != nil {
+		return err
+	}
+	*k = updated
+	return nil
+}
+
+// checkConflicts reports every key bound to more than one action within a
+// scope.
+func (k *KeyMap) checkConflicts() error {
+	byName := make(map[string]*key.Binding)
+	for _, a := range k.actions() {
+		byName[a.name] = a.binding
+	}
+
+	scopes := make([]string, 0, len(keyScopes))
+	for scope := range keyScopes {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+
+	var conflicts []string
+	for _, scope := range scopes {
+		owner := make(map[string]string)
+		for _, name := range keyScopes[scope] {
+			for _, keyName := range byName[name].Keys() {
+				if other, ok := owner[keyName]; ok && other != name {
+					conflicts = append(conflicts, fmt.Sprintf("%q is bound to both %s and %s (%s)", keyName, other, name, scope))
+					continue
+				}
+				owner[keyName] = name
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("keybinding conflicts: %s", strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// helpKeyLabel renders keys for the help view, e.g. ["j", "down"] as "j/↓".
+func helpKeyLabel(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		switch k {
+		case " ":
+			labels[i] = "space"
+		case "up":
+			labels[i] = "↑"
+		case "down":
+			labels[i] = "↓"
+		case "left":
+			labels[i] = "←"
+		case "right":
+			labels[i] = "→"
+		default:
+			labels[i] = k
+		}
+	}
+	return strings.Join(labels, "/")
+}
+
+// keyHint renders a footer hint such as "enter: jump" from a binding's help
+// key, so hints follow the user's keybindings.
+func keyHint(b key.Binding, desc string) string {
+	return b.Help().Key + ": " + desc
+}
+
+// overlayViewportKeys builds the scroll keys for the diff and snapshot
+// overlays. Line scrolling follows the configured up/down bindings unless
+// the overlay uses them for its own list; page scrolling is fixed to
+// pgup/pgdn and ctrl+u/ctrl+d.
+func (k KeyMap) overlayViewportKeys(lineScroll bool) viewport.KeyMap {
+	km := viewport.KeyMap{
+		PageUp:       key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "page up")),
+		PageDown:     key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdn", "page down")),
+		HalfPageUp:   key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "½ page up")),
+		HalfPageDown: key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "½ page down")),
+		Up:           key.NewBinding(key.WithDisabled()),
+		Down:         key.NewBinding(key.WithDisabled()),
+		Left:         key.NewBinding(key.WithDisabled()),
+		Right:        key.NewBinding(key.WithDisabled()),
+	}
+	if lineScroll {
+		km.Up = k.Up
+		km.Down = k.Down
+	}
+	return km
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/config"
+)
+
+func TestKeyMap_DefaultsHaveNoConflicts(t *testing.T) {
+	k := NewKeyMap()
+	if err := k.checkConflicts(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyMap_ScopesNameKnownActions(t *testing.T) {
+	k := NewKeyMap()
+	known := make(map[string]bool)
+	for _, a := range k.actions() {
+		known[a.name] = true
+	}
+	for scope, names := range keyScopes {
+		for _, name := range names {
+			if !known[name] {
+				t.Errorf("Scope %q lists unknown action %q", scope, name)
+			}
+		}
+	}
+}
+
+func TestKeyMap_ApplyOverrides(t *testing.T) {
+	k := NewKeyMap()
+	// Colemak-style: swap movement onto n/e and move match navigation.
+	err := k.ApplyOverrides(map[string][]string{
+		"down":       {"n", "down"},
+		"up":         {"e", "up"},
+		"next_match": {"j"},
+		"prev_match": {"J"},
+		"fold_close": {"x"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := k.Down.Help(); got.Key != "n/↓" || got.Desc != "move down" {
+		t.Errorf("Expected relabelled help with kept description, got %+v", got)
+	}
+	if got := k.FoldClose.Help().Key; got != "zx" {
+		t.Errorf("Expected fold help to include the prefix, got %q", got)
+	}
+}
+
+func TestKeyMap_ApplyOverridesRejectsConflicts(t *testing.T) {
+	k := NewKeyMap()
+	err := k.ApplyOverrides(map[string][]string{"down": {"n"}})
+	if err == nil || !strings.Contains(err.Error(), `"n" is bound to both`) {
+		t.Fatalf("Expected a conflict with next_match, got %v", err)
+	}
+	if k.Down.Keys()[0] != "j" {
+		t.Errorf("Expected defaults to be kept on error, got %v", k.Down.Keys())
+	}
+
+	if err := k.ApplyOverrides(map[string][]string{"jump": {"g"}}); err == nil {
+		t.Error("Expected an error for an unknown action")
+	}
+
+	// Keys may repeat across scopes: "y" copies output and confirms prompts.
+	if err := k.ApplyOverrides(map[string][]string{"confirm": {"y", "enter"}}); err != nil {
+		t.Errorf("Expected cross-scope reuse to be allowed, got %v", err)
+	}
+}
+
+func TestModel_UserKeybindingsDriveUpdate(t *testing.T) {
+	m := dirModel(t)
+	if err := m.ApplyUserConfig(config.UserConfig{Keybindings: map[string][]string{
+		"down":       {"n"},
+		"next_match": {"ctrl+n"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	m = sendKey(m, runes("n"))
+	if m.cursor != 1 {
+		t.Errorf("Expected 'n' to move down, cursor at %d", m.cursor)
+	}
+	m = sendKey(m, runes("j"))
+	if m.cursor != 1 {
+		t.Errorf("Expected 'j' to be unbound, cursor at %d", m.cursor)
+	}
+
+	// Smart Mode relabels help but keeps the user's keys.
+	m = sendKey(m, runes("s"))
+	if got := m.keys.ClearWatched.Help(); got.Key != "W" || got.Desc != "clear suite" {
+		t.Errorf("Unexpected smart mode help %+v", got)
+	}
+	if !strings.Contains(m.help.FullHelpView(m.keys.FullHelp()), "ctrl+n") {
+		t.Error("Expected the help view to show the user's keys")
+	}
+}
+
+func TestKeyMap_OverlayViewportKeysFollowOverrides(t *testing.T) {
+	k := NewKeyMap()
+	if err := k.ApplyOverrides(map[string][]string{"down": {"e"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	diff := k.overlayViewportKeys(true)
+	if !key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")}, diff.Down) {
+		t.Error("Expected the diff view to scroll down on the user's down key")
+	}
+	if key.Matches(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")}, diff.PageDown) {
+		t.Error("Expected 'f' not to page the diff view")
+	}
+
+	snapshots := k.overlayViewportKeys(false)
+	if snapshots.Down.Enabled() {
+		t.Error("Expected down to move the snapshot list, not scroll the details")
+	}
+}
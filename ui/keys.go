@@ -13,6 +13,30 @@ type KeyMap struct {
 	Help      key.Binding
 	Quit      key.Binding
 
+	// Selection Keys
+	ToggleMark    key.Binding
+	VisualMode    key.Binding
+	UnwatchMarked key.Binding
+	ClearMarks    key.Binding
+
+	// Folding Keys
+	Collapse   key.Binding
+	Expand     key.Binding
+	FoldPrefix key.Binding
+	FoldClose  key.Binding // Second stroke after FoldPrefix
+	FoldOpen   key.Binding
+	FoldToggle key.Binding
+	FoldAll    key.Binding
+	UnfoldAll  key.Binding
+
+	// Status Filter Keys
+	FilterFailing     key.Binding
+	FilterRunning     key.Binding
+	FilterNeverRun    key.Binding
+	FilterPassed      key.Binding
+	FilterWatched     key.Binding
+	ClearStatusFilter key.Binding
+
 	// Search Keys
 	Search     key.Binding
 	NextMatch  key.Binding
@@ -27,6 +51,44 @@ type KeyMap struct {
 	AddRelated      key.Binding
 	ToggleSmartMode key.Binding
 	RunFailures     key.Binding
+	StatsScope      key.Binding
+
+	// Output Filter Keys
+	FilterFailures key.Binding
+	FilterConsole  key.Binding
+	FilterStderr   key.Binding
+	FilterANSI     key.Binding
+
+	// Export Keys
+	CopyOutput    key.Binding
+	CopyRawOutput key.Binding
+	SaveOutput    key.Binding
+	OpenPager     key.Binding
+
+	// Diff View
+	DiffView key.Binding
+
+	// Affected Suite
+	WhyAffected key.Binding
+
+	// Snapshot Review
+	SnapshotReview key.Binding
+	Confirm        key.Binding
+	Cancel         key.Binding
+
+	// Fuzzy Finder
+	FuzzyFinder   key.Binding
+	FinderUp      key.Binding
+	FinderDown    key.Binding
+	FinderRun     key.Binding
+	FinderSources key.Binding
+
+	// Layout
+	GrowExplorer      key.Binding
+	ShrinkExplorer    key.Binding
+	ResetSplit        key.Binding
+	ToggleOrientation key.Binding
+	ZoomOutput        key.Binding
 }
 
 // NewKeyMap returns a set of default keybindings.
@@ -64,6 +126,78 @@ func NewKeyMap() KeyMap {
 			key.WithKeys("q", "ctrl+c"),
 			key.WithHelp("q", "quit"),
 		),
+		ToggleMark: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "mark file"),
+		),
+		VisualMode: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "visual select"),
+		),
+		UnwatchMarked: key.NewBinding(
+			key.WithKeys("U"),
+			key.WithHelp("U", "unwatch marked"),
+		),
+		ClearMarks: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "clear marks"),
+		),
+		Collapse: key.NewBinding(
+			key.WithKeys("h", "left"),
+			key.WithHelp("h/←", "collapse"),
+		),
+		Expand: key.NewBinding(
+			key.WithKeys("l", "right"),
+			key.WithHelp("l/→", "expand"),
+		),
+		FoldPrefix: key.NewBinding(
+			key.WithKeys("z"),
+			key.WithHelp("z", "fold prefix"),
+		),
+		FoldClose: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("zc", "fold"),
+		),
+		FoldOpen: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("zo", "unfold"),
+		),
+		FoldToggle: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("za", "toggle fold"),
+		),
+		FoldAll: key.NewBinding(
+			key.WithKeys("M"),
+			key.WithHelp("zM", "fold all"),
+		),
+		UnfoldAll: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("zR", "unfold all"),
+		),
+		FilterFailing: key.NewBinding(
+			key.WithKeys("1"),
+			key.WithHelp("1", "show failing"),
+		),
+		FilterRunning: key.NewBinding(
+			key.WithKeys("2"),
+			key.WithHelp("2", "show running"),
+		),
+		FilterNeverRun: key.NewBinding(
+			key.WithKeys("3"),
+			key.WithHelp("3", "show never run"),
+		),
+		FilterPassed: key.NewBinding(
+			key.WithKeys("4"),
+			key.WithHelp("4", "show passed"),
+		),
+		FilterWatched: key.NewBinding(
+			key.WithKeys("5"),
+			key.WithHelp("5", "show watched"),
+		),
+		ClearStatusFilter: key.NewBinding(
+			key.WithKeys("0"),
+			key.WithHelp("0", "clear status filter"),
+		),
 		Search: key.NewBinding(
 			key.WithKeys("/"),
 			key.WithHelp("/", "search"),
@@ -109,19 +243,123 @@ func NewKeyMap() KeyMap {
 			key.WithHelp("f", "run failures"),
 			key.WithDisabled(),
 		),
+		WhyAffected: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "why affected"),
+			key.WithDisabled(),
+		),
+		StatsScope: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "stats: session/all"),
+		),
+		FilterFailures: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "failures only"),
+		),
+		FilterConsole: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "hide console"),
+		),
+		FilterStderr: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "stderr only"),
+		),
+		FilterANSI: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "plain text"),
+		),
+		CopyOutput: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy output"),
+		),
+		CopyRawOutput: key.NewBinding(
+			key.WithKeys("Y"),
+			key.WithHelp("Y", "copy raw output"),
+		),
+		SaveOutput: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "save output"),
+		),
+		OpenPager: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "open in pager"),
+		),
+		DiffView: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "diff view"),
+		),
+		SnapshotReview: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "review snapshots"),
+		),
+		Confirm: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "confirm"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "cancel"),
+		),
+		FuzzyFinder: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("ctrl+p", "find file"),
+		),
+		FinderUp: key.NewBinding(
+			key.WithKeys("up", "ctrl+k"),
+			key.WithHelp("↑/ctrl+k", "previous result"),
+		),
+		FinderDown: key.NewBinding(
+			key.WithKeys("down", "ctrl+j", "ctrl+n"),
+			key.WithHelp("↓/ctrl+j", "next result"),
+		),
+		FinderRun: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "run result"),
+		),
+		FinderSources: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "include sources"),
+		),
+		GrowExplorer: key.NewBinding(
+			key.WithKeys(">"),
+			key.WithHelp(">", "grow explorer"),
+		),
+		ShrinkExplorer: key.NewBinding(
+			key.WithKeys("<"),
+			key.WithHelp("<", "shrink explorer"),
+		),
+		ResetSplit: key.NewBinding(
+			key.WithKeys("="),
+			key.WithHelp("=", "even split"),
+		),
+		ToggleOrientation: key.NewBinding(
+			key.WithKeys("|"),
+			key.WithHelp("|", "cycle layout"),
+		),
+		ZoomOutput: key.NewBinding(
+			key.WithKeys("Z"),
+			key.WithHelp("Z", "zoom output"),
+		),
 	}
 }
 
 // ShortHelp returns keybindings to be shown in the mini-help view. It's part of the help.KeyMap interface.
 func (k KeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Enter, k.Search, k.NextTab, k.PrevTab, k.Help, k.Quit}
+	return []key.Binding{k.Enter, k.Search, k.FuzzyFinder, k.NextTab, k.PrevTab, k.Help, k.Quit}
 }
 
 // FullHelp returns keybindings for the expanded help view. It's part of the help.KeyMap interface.
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Enter, k.Tab},
-		{k.PrevTab, k.NextTab, k.ToggleWatch, k.ClearWatched, k.AddRelated},
-		{k.ReRunLast, k.Refresh, k.RunFailures, k.ToggleSmartMode, k.Help, k.Quit},
+		{k.PrevTab, k.NextTab, k.ToggleWatch, k.ClearWatched, k.AddRelated, k.StatsScope},
+		{k.ToggleMark, k.VisualMode, k.UnwatchMarked, k.ClearMarks},
+		{k.Collapse, k.Expand, k.FoldClose, k.FoldOpen, k.FoldAll, k.UnfoldAll},
+		{k.FilterFailing, k.FilterRunning, k.FilterNeverRun, k.FilterPassed, k.FilterWatched, k.ClearStatusFilter},
+		{k.ReRunLast, k.Refresh, k.RunFailures, k.WhyAffected, k.ToggleSmartMode, k.Help, k.Quit},
+		{k.FilterFailures, k.FilterConsole, k.FilterStderr, k.FilterANSI},
+		{k.CopyOutput, k.CopyRawOutput, k.SaveOutput, k.OpenPager, k.DiffView, k.SnapshotReview},
+		{k.Search, k.NextMatch, k.PrevMatch, k.FuzzyFinder, k.FinderRun, k.FinderSources},
+		{k.ShrinkExplorer, k.GrowExplorer, k.ResetSplit, k.ToggleOrientation, k.ZoomOutput},
 	}
 }
@@ -0,0 +1,225 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/config"
+	"github.com/jesspatton/lazytest/engine"
+)
+
+// orientation is how the explorer and output panes are arranged.
+type orientation int
+
+const (
+	// orientationAuto stacks the panes on narrow terminals.
+	orientationAuto orientation = iota
+	// orientationHorizontal puts the panes side by side.
+	orientationHorizontal
+	// orientationVertical stacks the explorer above the output.
+	orientationVertical
+)
+
+var orientationNames = []string{"auto", "horizontal", "vertical"}
+
+func (o orientation) String() string {
+	return orientationNames[o]
+}
+
+func parseOrientation(name string) (orientation, error) {
+	if name == "" {
+		return orientationAuto, nil
+	}
+	for i, n := range orientationNames {
+		if n == name {
+			return orientation(i), nil
+		}
+	}
+	return orientationAuto, fmt.Errorf("unknown layout orientation %q (want auto, horizontal or vertical)", name)
+}
+
+const (
+	defaultSplitRatio    = 0.5
+	minSplitRatio        = 0.15
+	maxSplitRatio        = 0.85
+	splitStep            = 0.05
+	defaultVerticalBelow = 100
+	// footerRows is the space left under the panes for the footer.
+	footerRows = 2
+)
+
+// layout is the pane arrangement. ratio is the explorer's share of the split,
+// along the width when side by side and along the height when stacked.
+type layout struct {
+	ratio         float64
+	orientation   orientation
+	verticalBelow int
+	zoomed        bool // Output fills the screen and the explorer is hidden
+	dragging      bool // The divider is being dragged with the mouse
+}
+
+func newLayout() layout {
+	return layout{ratio: defaultSplitRatio, verticalBelow: defaultVerticalBelow}
+}
+
+// layoutFromConfig validates the layout section of the user config.
+func layoutFromConfig(cfg config.Layout) (layout, error) {
+	l := newLayout()
+	o, err := parseOrientation(cfg.Orientation)
+	if err != nil {
+		return l, err
+	}
+	l.orientation = o
+	if cfg.SplitRatio != 0 {
+		if cfg.SplitRatio < 0 || cfg.SplitRatio > 1 {
+			return newLayout(), fmt.Errorf("layout split_ratio %v is not between 0 and 1", cfg.SplitRatio)
+		}
+		l.ratio = clampRatio(cfg.SplitRatio)
+	}
+	if cfg.VerticalBelow > 0 {
+		l.verticalBelow = cfg.VerticalBelow
+	}
+	return l, nil
+}
+
+// config returns the layout as it is saved in the user config.
+func (l layout) config() config.Layout {
+	cfg := config.Layout{SplitRatio: l.ratio, Orientation: l.orientation.String()}
+	if l.verticalBelow != defaultVerticalBelow {
+		cfg.VerticalBelow = l.verticalBelow
+	}
+	return cfg
+}
+
+// clampRatio keeps both panes usable and rounds to whole percents so the
+// saved ratio stays readable.
+func clampRatio(ratio float64) float64 {
+	return min(max(math.Round(ratio*100)/100, minSplitRatio), maxSplitRatio)
+}
+
+// rect is a pane's bounds in screen cells, borders included.
+type rect struct {
+	x, y, w, h int
+}
+
+func (r rect) contains(x, y int) bool {
+	return x >= r.x && x < r.x+r.w && y >= r.y && y < r.y+r.h
+}
+
+// vertical reports whether the panes are currently stacked.
+func (m Model) vertical() bool {
+	switch m.layout.orientation {
+	case orientationHorizontal:
+		return false
+	case orientationVertical:
+		return true
+	default:
+		return m.width < m.layout.verticalBelow
+	}
+}
+
+// paneRects splits the screen between the explorer and the output pane. The
+// explorer always comes first (left or top); while the output is zoomed the
+// explorer's rect is empty.
+func (m Model) paneRects() (explorer, out rect) {
+	width, height := m.width, max(m.height-footerRows, 0)
+	if m.layout.zoomed {
+		return rect{}, rect{w: width, h: height}
+	}
+	if m.vertical() {
+		split := int(float64(height) * m.layout.ratio)
+		return rect{w: width, h: split}, rect{y: split, w: width, h: height - split}
+	}
+	split := int(float64(width) * m.layout.ratio)
+	return rect{w: split, h: height}, rect{x: split, w: width - split, h: height}
+}
+
+// onDivider reports whether a screen cell lies on the border between the
+// two panes.
+func (m Model) onDivider(x, y int) bool {
+	explorer, out := m.paneRects()
+	if m.layout.zoomed || !out.contains(x, y) && !explorer.contains(x, y) {
+		return false
+	}
+	if m.vertical() {
+		return y == explorer.h-1 || y == explorer.h
+	}
+	return x == explorer.w-1 || x == explorer.w
+}
+
+// resizePanes fits the output viewport to its pane.
+func (m *Model) resizePanes() {
+	_, out := m.paneRects()
+	// Width: Border(2) + Padding(2); Height: Border(2) + Header(2) + 1 spare
+	width, height := max(out.w-4, 0), max(out.h-5, 0)
+	if !m.ready {
+		m.viewport = viewport.New(width, height)
+		m.ready = true
+	} else {
+		m.viewport.Width = width
+		m.viewport.Height = height
+	}
+	// New PTY runs get a terminal the size of the pane they render in.
+	m.engine.SetTerminalSize(width, height)
+	m.syncViewportOutput()
+}
+
+// setSplit moves the divider and saves the new layout. A zoomed output is
+// restored so the change is visible.
+func (m *Model) setSplit(ratio float64) tea.Cmd {
+	m.layout.ratio = clampRatio(ratio)
+	m.layout.zoomed = false
+	m.resizePanes()
+	return m.saveLayout()
+}
+
+// dragDivider moves the divider to follow the mouse.
+func (m *Model) dragDivider(x, y int) {
+	explorer, out := m.paneRects()
+	if m.vertical() {
+		m.layout.ratio = clampRatio(float64(y) / float64(max(explorer.h+out.h, 1)))
+	} else {
+		m.layout.ratio = clampRatio(float64(x) / float64(max(explorer.w+out.w, 1)))
+	}
+	m.resizePanes()
+}
+
+// cycleOrientation switches between auto, side-by-side and stacked panes.
+func (m *Model) cycleOrientation() tea.Cmd {
+	m.layout.orientation = (m.layout.orientation + 1) % orientation(len(orientationNames))
+	m.resizePanes()
+
+	label := m.layout.orientation.String()
+	if m.layout.orientation == orientationAuto {
+		label = "auto (horizontal)"
+		if m.vertical() {
+			label = "auto (vertical)"
+		}
+	}
+	return tea.Batch(notify("Layout: "+label, false), m.saveLayout())
+}
+
+// toggleZoom maximizes the output pane or restores the split.
+func (m *Model) toggleZoom() {
+	m.layout.zoomed = !m.layout.zoomed
+	if m.layout.zoomed {
+		m.activePane = PaneOutput
+	}
+	m.resizePanes()
+}
+
+// saveLayout writes the layout to the user config file, if there is one.
+func (m Model) saveLayout() tea.Cmd {
+	if m.configPath == "" {
+		return nil
+	}
+	path, cfg := m.configPath, m.layout.config()
+	return func() tea.Msg {
+		if err := config.SaveLayout(path, cfg); err != nil {
+			return engine.NotificationMsg{Message: fmt.Sprintf("Failed to save layout: %v", err), IsError: true}
+		}
+		return nil
+	}
+}
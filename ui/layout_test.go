@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/config"
+)
+
+func sizedModel(t *testing.T, width, height int) Model {
+	t.Helper()
+	updated, _ := dirModel(t).Update(tea.WindowSizeMsg{Width: width, Height: height})
+	return updated.(Model)
+}
+
+func TestLayout_AdjustSplitWithKeys(t *testing.T) {
+	m := sizedModel(t, 120, 40)
+	explorer, out := m.paneRects()
+	if explorer.w != 60 || out.x != 60 || out.w != 60 {
+		t.Fatalf("Expected an even split, got %+v / %+v", explorer, out)
+	}
+
+	m = sendKey(m, runes(">"))
+	m = sendKey(m, runes(">"))
+	if explorer, _ = m.paneRects(); explorer.w != 72 {
+		t.Errorf("Expected the explorer to grow to 72 columns, got %d", explorer.w)
+	}
+	if m.viewport.Width != 120-72-4 {
+		t.Errorf("Expected the viewport to follow the split, got width %d", m.viewport.Width)
+	}
+
+	for range 20 {
+		m = sendKey(m, runes("<"))
+	}
+	if m.layout.ratio != minSplitRatio {
+		t.Errorf("Expected the split to stop at %v, got %v", minSplitRatio, m.layout.ratio)
+	}
+
+	m = sendKey(m, runes("="))
+	if m.layout.ratio != defaultSplitRatio {
+		t.Errorf("Expected = to restore the even split, got %v", m.layout.ratio)
+	}
+}
+
+func TestLayout_AutoStacksNarrowTerminals(t *testing.T) {
+	m := sizedModel(t, 80, 40)
+	if !m.vertical() {
+		t.Fatal("Expected a narrow terminal to stack the panes")
+	}
+	explorer, out := m.paneRects()
+	if explorer.w != 80 || out.w != 80 || out.y != explorer.h || explorer.h+out.h != 38 {
+		t.Errorf("Expected stacked full-width panes, got %+v / %+v", explorer, out)
+	}
+
+	m = sendKey(m, runes("|")) // auto -> horizontal
+	if m.vertical() {
+		t.Error("Expected horizontal orientation to override auto")
+	}
+}
+
+func TestLayout_ZoomOutput(t *testing.T) {
+	m := sizedModel(t, 120, 40)
+	m = sendKey(m, runes("Z"))
+	explorer, out := m.paneRects()
+	if explorer.w != 0 || out.w != 120 || m.activePane != PaneOutput {
+		t.Fatalf("Expected the output to fill the screen, got %+v / %+v", explorer, out)
+	}
+
+	// Switching back to the explorer restores the split.
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyTab})
+	if m.layout.zoomed || m.activePane != PaneExplorer {
+		t.Errorf("Expected tab to leave zoom for the explorer")
+	}
+}
+
+func TestLayout_DragDividerSavesToConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	m := sizedModel(t, 120, 40)
+	m.SetConfigPath(path)
+
+	drag := func(action tea.MouseAction, x int) tea.Cmd {
+		updated, cmd := m.Update(tea.MouseMsg{X: x, Y: 10, Action: action, Button: tea.MouseButtonLeft})
+		m = updated.(Model)
+		return cmd
+	}
+	drag(tea.MouseActionPress, 60)
+	drag(tea.MouseActionMotion, 42)
+	cmd := drag(tea.MouseActionRelease, 42)
+
+	if m.layout.ratio != 0.35 {
+		t.Fatalf("Expected the divider to follow the mouse, got ratio %v", m.layout.ratio)
+	}
+	if cmd == nil {
+		t.Fatal("Expected releasing the divider to save the layout")
+	}
+	cmd()
+
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Layout.SplitRatio != 0.35 || cfg.Layout.Orientation != "auto" {
+		t.Errorf("Unexpected saved layout %+v", cfg.Layout)
+	}
+}
+
+func TestLayout_FromConfig(t *testing.T) {
+	m := dirModel(t)
+	err := m.ApplyUserConfig(config.UserConfig{Layout: config.Layout{SplitRatio: 0.3, Orientation: "vertical"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.layout.ratio != 0.3 || m.layout.orientation != orientationVertical {
+		t.Errorf("Expected the configured layout, got %+v", m.layout)
+	}
+
+	if err := m.ApplyUserConfig(config.UserConfig{Layout: config.Layout{Orientation: "diagonal"}}); err == nil {
+		t.Error("Expected an error for an unknown orientation")
+	}
+}
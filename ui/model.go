@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"errors"
 	"strings"
 	"time"
 
@@ -9,8 +10,10 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/jesspatton/lazytest/config"
 	"github.com/jesspatton/lazytest/engine"
 	"github.com/jesspatton/lazytest/filesystem"
+	"github.com/jesspatton/lazytest/output"
 )
 
 // Pane represents a distinct section of the UI.
@@ -31,6 +34,10 @@ const (
 	TabExplorer LeftTab = iota
 	// TabWatched is the watched files tab.
 	TabWatched
+	// TabStats is the test statistics tab.
+	TabStats
+
+	tabCount = 3
 )
 
 // DisplayNode represents a node in the explorer list, potentially compacted.
@@ -38,6 +45,7 @@ type DisplayNode struct {
 	*filesystem.Node
 	DisplayName string
 	Depth       int
+	Collapsed   bool // True for a folded directory whose children are hidden
 }
 
 // Model represents the application state for the Bubbletea program.
@@ -51,15 +59,43 @@ type Model struct {
 	cursor     int
 	viewport   viewport.Model
 
+	// Layout State
+	layout     layout
+	configPath string // User config file that layout changes are saved to
+
 	// Tab State
 	activeTab     LeftTab
 	watchedCursor int
 
+	// Stats Tab State
+	statsCursor      int  // Index among the selectable stats rows
+	statsSessionOnly bool // Sections cover this session instead of all history
+	statsCache       []statsRow
+	statsRunCount    int // History length statsCache was built from
+
+	// Fold State
+	folded   map[string]struct{} // Directory paths whose children are hidden
+	pendingZ bool                // True after "z", awaiting the fold command
+
+	// Status Filter State
+	statusFilter statusFilter
+
+	// Directory Count State: test status counts per directory path
+	dirCounts            map[string]dirCounts
+	dirCountsVersion     int // Engine status version dirCounts was built from
+	dirCountsTreeVersion int // Engine tree version dirCounts was built from
+
+	// Selection State
+	marked           map[string]struct{}
+	visualMode       bool
+	visualAnchor     int    // flatNodes index of visualAnchorPath
+	visualAnchorPath string // Row the visual range started on
+
 	// Search State
 	searchMode        bool
 	searchFocus       bool
 	searchInput       textinput.Model
-	searchMatches     []int
+	searchMatches     []string // Paths of matching nodes, including folded ones
 	currentMatchIndex int
 
 	// Components
@@ -82,30 +118,87 @@ type Model struct {
 
 	// Output debouncing
 	outputUpdateQueued bool
+
+	// Output view filters
+	outputFilter output.Filter
+
+	// Diff View State
+	diffMode     bool
+	diffs        []output.AssertionDiff
+	diffIndex    int
+	diffViewport viewport.Model
+
+	// Why Affected State: the explanation shown over the panes, if any
+	whyText string
+
+	// Snapshot Review State
+	snapshotMode     bool
+	snapshotConfirm  bool
+	snapshotEntries  []engine.SnapshotEntry
+	snapshotCursor   int
+	snapshotViewport viewport.Model
+
+	// Fuzzy Finder State
+	finderMode    bool
+	finderSources bool // Include non-test source files
+	finderInput   textinput.Model
+	finderResults []finderResult
+	finderCursor  int
 }
 
 // NewModel creates and initializes a new Model.
 func NewModel(eng *engine.Engine) Model {
 	h := help.New()
-	h.Styles.ShortKey = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#909090", Dark: "#A0A0A0"})
-	h.Styles.ShortDesc = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#B0B0B0", Dark: "#808080"})
-	h.Styles.ShortSeparator = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#D0D0D0", Dark: "#606060"})
-	h.Styles.FullKey = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#909090", Dark: "#A0A0A0"})
-	h.Styles.FullDesc = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#B0B0B0", Dark: "#808080"})
-	h.Styles.FullSeparator = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#D0D0D0", Dark: "#606060"})
+	h.Styles = helpStyles()
 	ti := textinput.New()
 	ti.Placeholder = "Search..."
 	ti.Prompt = "/"
 	ti.CharLimit = 156
 	ti.Width = 20
 
-	return Model{
+	m := Model{
 		activePane:  PaneExplorer,
 		engine:      eng,
 		keys:        NewKeyMap(),
 		help:        h,
 		searchInput: ti,
+		marked:      make(map[string]struct{}),
+		folded:      make(map[string]struct{}),
+		layout:      newLayout(),
 	}
+	m.rebuildStats()
+	return m
+}
+
+// SetConfigPath sets the user config file that layout changes are saved to.
+// Without one, layout changes last for the session only.
+func (m *Model) SetConfigPath(path string) {
+	m.configPath = path
+}
+
+// ApplyUserConfig applies the user-level settings to the model. An invalid
+// section is reported as an error and leaves the defaults for that section in
+// place.
+func (m *Model) ApplyUserConfig(cfg config.UserConfig) error {
+	var errs []error
+	if err := m.keys.ApplyOverrides(cfg.Keybindings); err != nil {
+		errs = append(errs, err)
+	}
+	m.applySmartModeBindings()
+
+	if theme, err := LoadTheme(cfg.Theme, config.ThemeDir()); err != nil {
+		errs = append(errs, err)
+	} else {
+		applyTheme(theme)
+		m.help.Styles = helpStyles()
+	}
+
+	if l, err := layoutFromConfig(cfg.Layout); err != nil {
+		errs = append(errs, err)
+	} else {
+		m.layout = l
+	}
+	return errors.Join(errs...)
 }
 
 // Init initializes the Bubbletea program.
@@ -126,11 +219,29 @@ func (m Model) View() string {
 		return "Loading..."
 	}
 
-	paneWidth := (m.width / 2) - 2
-	paneHeight := m.height - 4
+	if m.whyText != "" {
+		return m.renderWhyAffected()
+	}
+
+	if m.diffMode {
+		return m.renderDiffView()
+	}
+
+	if m.snapshotMode {
+		return m.renderSnapshotView()
+	}
+
+	if m.finderMode {
+		return m.renderFinderView()
+	}
+
+	explorerRect, outputRect := m.paneRects()
 
 	// Explorer View
-	explorerRender := m.renderExplorer(paneWidth, paneHeight)
+	var explorerRender string
+	if !m.layout.zoomed {
+		explorerRender = m.renderExplorer(max(explorerRect.w-2, 0), max(explorerRect.h-2, 0))
+	}
 
 	// Output View
 	var outputView strings.Builder
@@ -138,9 +249,11 @@ func (m Model) View() string {
 		passed, failed, running := m.engine.GetSuiteStats()
 		badge := m.renderSuiteBadge(passed, failed, running)
 		outputView.WriteString(badge)
+		outputView.WriteString(m.renderFilterLabel())
 		outputView.WriteByte('\n')
 	} else {
 		outputView.WriteString(titleStyle.Render("OUTPUT"))
+		outputView.WriteString(m.renderFilterLabel())
 		outputView.WriteString("\n\n")
 	}
 
@@ -155,11 +268,19 @@ func (m Model) View() string {
 		outputStyle = activePaneStyle
 	}
 	outputRender := outputStyle.
-		Width(paneWidth).
-		Height(paneHeight).
+		Width(max(outputRect.w-2, 0)).
+		Height(max(outputRect.h-2, 0)).
 		Render(outputView.String())
 
-	panes := lipgloss.JoinHorizontal(lipgloss.Top, explorerRender, outputRender)
+	var panes string
+	switch {
+	case m.layout.zoomed:
+		panes = outputRender
+	case m.vertical():
+		panes = lipgloss.JoinVertical(lipgloss.Left, explorerRender, outputRender)
+	default:
+		panes = lipgloss.JoinHorizontal(lipgloss.Top, explorerRender, outputRender)
+	}
 	footer := m.renderFooter()
 
 	return lipgloss.JoinVertical(lipgloss.Left, panes, footer)
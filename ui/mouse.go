@@ -11,10 +11,26 @@ import (
 
 // handleMouse processes mouse events based on the mouse support plan.
 func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	splitPoint := m.width / 2
+	explorerRect, outputRect := m.paneRects()
+
+	// --- Divider ---
+	if m.layout.dragging {
+		switch msg.Action {
+		case tea.MouseActionMotion:
+			m.dragDivider(msg.X, msg.Y)
+			return m, nil
+		case tea.MouseActionRelease:
+			m.layout.dragging = false
+			return m, m.saveLayout()
+		}
+	}
+	if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft && m.onDivider(msg.X, msg.Y) {
+		m.layout.dragging = true
+		return m, nil
+	}
 
 	// --- Output Pane ---
-	if msg.X >= splitPoint {
+	if outputRect.contains(msg.X, msg.Y) {
 		if msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown {
 			var cmd tea.Cmd
 			m.viewport, cmd = m.viewport.Update(msg)
@@ -28,13 +44,18 @@ func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	}
 
 	// --- Explorer Pane ---
+	if !explorerRect.contains(msg.X, msg.Y) {
+		return m, nil
+	}
 	if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
 		m.activePane = PaneExplorer
 	}
 
 	// Handle Explorer Pane Scrolling
 	if msg.Button == tea.MouseButtonWheelUp {
-		if m.activeTab == TabExplorer {
+		if m.activeTab == TabStats {
+			m.statsCursor = max(m.statsCursor-1, 0)
+		} else if m.activeTab == TabExplorer {
 			if m.cursor > 0 {
 				m.cursor--
 			}
@@ -48,7 +69,9 @@ func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	}
 
 	if msg.Button == tea.MouseButtonWheelDown {
-		if m.activeTab == TabExplorer {
+		if m.activeTab == TabStats {
+			m.statsCursor = min(m.statsCursor+1, max(len(statsSelectable(m.statsRows()))-1, 0))
+		} else if m.activeTab == TabExplorer {
 			if m.cursor < len(m.flatNodes)-1 {
 				m.cursor++
 			}
@@ -77,32 +100,32 @@ func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 			Border(lipgloss.RoundedBorder()).
 			Padding(0, 1)
 		
-		explorerTab := activeTabStyle.Render("Explorer")
-		watchedTabLabel := "Watched"
-		if m.engine.IsSmartMode() {
-			watchedTabLabel = "Affected Suite"
+		var tabViews []string
+		for _, label := range m.tabLabels() {
+			tabViews = append(tabViews, activeTabStyle.Render(label))
 		}
-		watchedTab := activeTabStyle.Render(watchedTabLabel)
-		tabs := lipgloss.JoinHorizontal(lipgloss.Bottom, explorerTab, watchedTab)
-		
+		tabs := lipgloss.JoinHorizontal(lipgloss.Bottom, tabViews...)
+
 		headerOffset := lipgloss.Height(tabs) + 1
 		headerPhysicalHeight := lipgloss.Height(tabs + "\n\n")
 		tabAreaHeight := lipgloss.Height(tabs)
-		explorerTabWidth := lipgloss.Width(explorerTab)
 
 		// 1. Clicked Tabs Area
 		if contentY < tabAreaHeight {
-			if contentX < explorerTabWidth {
-				m.activeTab = TabExplorer
-			} else {
-				m.activeTab = TabWatched
+			tabEnd := 0
+			for i, view := range tabViews {
+				tabEnd += lipgloss.Width(view)
+				if contentX < tabEnd || i == len(tabViews)-1 {
+					m.activeTab = LeftTab(i)
+					break
+				}
 			}
 			m.syncViewportOutput()
 			return m, nil
 		}
 
 		// Calculate available heights for the tree
-		paneHeight := m.height - 5
+		paneHeight := explorerRect.h - 3
 		treeHeight := paneHeight - headerPhysicalHeight
 
 		searchHeight := 0
@@ -141,7 +164,20 @@ func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 			m.lastClickX = msg.X
 			m.lastClickY = msg.Y
 
-			if m.activeTab == TabExplorer {
+			if m.activeTab == TabStats {
+				rows := m.statsRows()
+				index := m.statsWindowStart(rows, treeHeight) + visualIndex
+				for i, row := range statsSelectable(rows) {
+					if row == index {
+						m.statsCursor = i
+						m.syncViewportOutput()
+						if isDoubleClick {
+							m.jumpToPath(rows[row].path)
+						}
+						break
+					}
+				}
+			} else if m.activeTab == TabExplorer {
 				start, _ := m.calculateVisibleRange(treeHeight)
 				index := start + visualIndex
 
@@ -150,10 +186,7 @@ func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 					m.syncViewportOutput()
 
 					if isDoubleClick {
-						node := m.flatNodes[m.cursor]
-						if !node.IsDir {
-							return m, m.engine.TriggerTest(node.Node)
-						}
+						return m, m.runNode(m.flatNodes[m.cursor])
 					}
 				}
 			} else {
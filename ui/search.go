@@ -28,7 +28,7 @@ func (m Model) handleSearchKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
 			// Jump to first match if exists
 			if len(m.searchMatches) > 0 {
 				m.currentMatchIndex = 0
-				m.cursor = m.searchMatches[0]
+				m.revealPath(m.searchMatches[0], true)
 			}
 			return m, nil, true
 		default:
@@ -36,12 +36,12 @@ func (m Model) handleSearchKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
 			var cmd tea.Cmd
 			m.searchInput, cmd = m.searchInput.Update(msg)
 
-			// Update matches
-			m.searchMatches = []int{}
+			// Update matches, looking inside folded directories too
+			m.searchMatches = []string{}
 			if m.searchInput.Value() != "" {
-				for i, node := range m.flatNodes {
+				for _, node := range flattenNodes(m.engine.GetTree()) {
 					if strings.Contains(strings.ToLower(node.DisplayName), strings.ToLower(m.searchInput.Value())) {
-						m.searchMatches = append(m.searchMatches, i)
+						m.searchMatches = append(m.searchMatches, node.Path)
 					}
 				}
 			}
@@ -63,13 +63,13 @@ func (m Model) handleSearchKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
 		case key.Matches(msg, m.keys.NextMatch):
 			if len(m.searchMatches) > 0 {
 				m.currentMatchIndex = (m.currentMatchIndex + 1) % len(m.searchMatches)
-				m.cursor = m.searchMatches[m.currentMatchIndex]
+				m.revealPath(m.searchMatches[m.currentMatchIndex], true)
 				m.syncViewportOutput()
 			}
 		case key.Matches(msg, m.keys.PrevMatch):
 			if len(m.searchMatches) > 0 {
 				m.currentMatchIndex = (m.currentMatchIndex - 1 + len(m.searchMatches)) % len(m.searchMatches)
-				m.cursor = m.searchMatches[m.currentMatchIndex]
+				m.revealPath(m.searchMatches[m.currentMatchIndex], true)
 				m.syncViewportOutput()
 			}
 		case key.Matches(msg, m.keys.Enter):
@@ -78,10 +78,7 @@ func (m Model) handleSearchKey(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
 			m.searchInput.Reset()
 			m.searchMatches = nil
 			if m.cursor < len(m.flatNodes) {
-				node := m.flatNodes[m.cursor]
-				if !node.IsDir {
-					return m, m.engine.TriggerTest(node.Node), true
-				}
+				return m, m.runNode(m.flatNodes[m.cursor]), true
 			}
 		}
 		// Return handled=false so the rest of the flow can process commands
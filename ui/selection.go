@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hasSelection reports whether any explorer file is marked, either directly or
+// through an active visual range.
+func (m Model) hasSelection() bool {
+	return len(m.marked) > 0 || m.visualMode
+}
+
+// toggleMark marks or unmarks the file under the cursor and advances the
+// cursor to the next file.
+func (m *Model) toggleMark() {
+	if m.cursor >= len(m.flatNodes) || m.flatNodes[m.cursor].IsDir {
+		return
+	}
+	path := m.flatNodes[m.cursor].Path
+	if _, ok := m.marked[path]; ok {
+		delete(m.marked, path)
+	} else {
+		m.marked[path] = struct{}{}
+	}
+
+	for next := m.cursor + 1; next < len(m.flatNodes); next++ {
+		if !m.flatNodes[next].IsDir {
+			m.cursor = next
+			break
+		}
+	}
+}
+
+// toggleVisualMode starts a visual range anchored at the cursor, or, when one
+// is already active, commits the range into the marked set.
+func (m *Model) toggleVisualMode() {
+	if !m.visualMode {
+		if m.cursor >= len(m.flatNodes) {
+			return
+		}
+		m.visualMode = true
+		m.visualAnchor = m.cursor
+		m.visualAnchorPath = m.flatNodes[m.cursor].Path
+		return
+	}
+	lo, hi := m.visualRange()
+	for i := lo; i <= hi; i++ {
+		if !m.flatNodes[i].IsDir {
+			m.marked[m.flatNodes[i].Path] = struct{}{}
+		}
+	}
+	m.visualMode = false
+}
+
+// visualRange returns the inclusive flatNodes index range covered by the
+// active visual selection.
+func (m Model) visualRange() (int, int) {
+	lo, hi := m.visualAnchor, m.cursor
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	if hi >= len(m.flatNodes) {
+		hi = len(m.flatNodes) - 1
+	}
+	return lo, hi
+}
+
+// isMarked reports whether the explorer row at index is part of the
+// selection.
+func (m Model) isMarked(index int) bool {
+	node := m.flatNodes[index]
+	if node.IsDir {
+		return false
+	}
+	if m.visualMode {
+		if lo, hi := m.visualRange(); index >= lo && index <= hi {
+			return true
+		}
+	}
+	_, ok := m.marked[node.Path]
+	return ok
+}
+
+// markedPaths returns the sorted paths of every selected file, including the
+// active visual range.
+func (m Model) markedPaths() []string {
+	set := make(map[string]struct{}, len(m.marked))
+	for path := range m.marked {
+		set[path] = struct{}{}
+	}
+	if m.visualMode {
+		lo, hi := m.visualRange()
+		for i := lo; i <= hi; i++ {
+			if !m.flatNodes[i].IsDir {
+				set[m.flatNodes[i].Path] = struct{}{}
+			}
+		}
+	}
+	paths := make([]string, 0, len(set))
+	for path := range set {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// clearMarks drops the selection and leaves visual mode.
+func (m *Model) clearMarks() {
+	m.marked = make(map[string]struct{})
+	m.visualMode = false
+}
+
+// runMarked queues every selected file through the engine and clears the
+// selection.
+func (m *Model) runMarked() tea.Cmd {
+	paths := m.markedPaths()
+	m.clearMarks()
+	return tea.Batch(
+		m.engine.EnqueueTests(paths),
+		notify(fmt.Sprintf("Queued %d tests.", len(paths)), false),
+	)
+}
+
+// watchMarked watches or unwatches every selected file and clears the
+// selection.
+func (m *Model) watchMarked(watched bool) tea.Cmd {
+	paths := m.markedPaths()
+	m.clearMarks()
+	m.engine.SetWatched(paths, watched)
+	verb := "Watching"
+	if !watched {
+		verb = "Unwatched"
+	}
+	return notify(fmt.Sprintf("%s %d files.", verb, len(paths)), false)
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/engine"
+	"github.com/jesspatton/lazytest/filesystem"
+)
+
+// selectionModel returns a model whose explorer lists a directory followed by
+// three test files.
+func selectionModel(t *testing.T) Model {
+	t.Helper()
+	eng := engine.New(t.TempDir())
+	eng.ProjectConfig.MaxConcurrentTests = 0
+	m := NewModel(eng)
+	m.flatNodes = []DisplayNode{
+		{Node: &filesystem.Node{Name: "src", Path: "/p/src", IsDir: true}, DisplayName: "src"},
+		{Node: filesystem.NodeFromPath("/p/src/a.test.ts"), DisplayName: "a.test.ts", Depth: 1},
+		{Node: filesystem.NodeFromPath("/p/src/b.test.ts"), DisplayName: "b.test.ts", Depth: 1},
+		{Node: filesystem.NodeFromPath("/p/src/c.test.ts"), DisplayName: "c.test.ts", Depth: 1},
+	}
+	m.cursor = 1
+	return m
+}
+
+func sendKey(m Model, msg tea.KeyMsg) Model {
+	updated, _ := m.Update(msg)
+	return updated.(Model)
+}
+
+func TestMultiSelect_VisualRangeAndWatch(t *testing.T) {
+	m := selectionModel(t)
+
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+
+	want := []string{"/p/src/a.test.ts", "/p/src/b.test.ts", "/p/src/c.test.ts"}
+	if got := m.markedPaths(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected visual range %v, got %v", want, got)
+	}
+
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	if got := m.engine.GetWatchedFiles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected all marked files watched, got %v", got)
+	}
+	if m.hasSelection() {
+		t.Error("Expected selection to be cleared after bulk watch")
+	}
+}
+
+func TestMultiSelect_SpaceMarksAndRunQueues(t *testing.T) {
+	m := selectionModel(t)
+
+	// Mark a.test.ts (cursor advances to b), skip b, mark c.
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+
+	want := []string{"/p/src/a.test.ts", "/p/src/c.test.ts"}
+	if got := m.markedPaths(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected marked %v, got %v", want, got)
+	}
+
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !reflect.DeepEqual(m.engine.State.Queue, want) {
+		t.Errorf("Expected marked files queued, got %v", m.engine.State.Queue)
+	}
+}
+
+func TestMultiSelect_VisualRangeEndsWhenAnchorIsFolded(t *testing.T) {
+	m := foldModel(t)
+	m.cursor = 2 // b.test.ts, inside nested
+
+	m = sendKey(m, runes("v"))
+	m = sendKey(m, runes("k"))
+	m = sendKey(m, runes("h")) // Fold nested, hiding the anchor
+	if m.visualMode {
+		t.Fatalf("Expected visual mode to end once its anchor is hidden, got range over %v", m.markedPaths())
+	}
+	if got := m.markedPaths(); len(got) != 0 {
+		t.Errorf("Expected nothing selected, got %v", got)
+	}
+}
+
+func TestMultiSelect_VisualAnchorFollowsFilter(t *testing.T) {
+	m := foldModel(t)
+	m.engine.State.NodeStatus["/p/src/a.test.ts"] = engine.StatusPass
+	m.engine.State.NodeStatus["/p/src/nested/b.test.ts"] = engine.StatusFail
+	m.cursor = 3 // a.test.ts
+
+	m = sendKey(m, runes("v"))
+	m = sendKey(m, runes("4")) // Passed only: src, a.test.ts
+	if !m.visualMode || m.flatNodes[m.visualAnchor].Path != "/p/src/a.test.ts" {
+		t.Fatalf("Expected the anchor to stay on a.test.ts, got %v at %d", displayNames(m), m.visualAnchor)
+	}
+	if got := m.markedPaths(); !reflect.DeepEqual(got, []string{"/p/src/a.test.ts"}) {
+		t.Errorf("Expected only a.test.ts selected, got %v", got)
+	}
+}
@@ -0,0 +1,201 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jesspatton/lazytest/engine"
+)
+
+// openSnapshotReview collects the snapshot results of every test that has run
+// and opens the full-screen snapshot review.
+func (m Model) openSnapshotReview() (Model, tea.Cmd) {
+	entries := m.engine.GetSnapshotEntries()
+	if len(entries) == 0 {
+		return m, notify("No snapshot failures, writes, or obsolete snapshots found.", false)
+	}
+
+	m.snapshotMode = true
+	m.snapshotEntries = entries
+	m.snapshotCursor = 0
+	m.snapshotConfirm = false
+	m.snapshotViewport = viewport.New(0, 0)
+	m.snapshotViewport.KeyMap = m.keys.overlayViewportKeys(false)
+	m.refreshSnapshotView()
+	return m, nil
+}
+
+// handleSnapshotKey processes a key message while the snapshot review is open.
+func (m Model) handleSnapshotKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if m.snapshotConfirm {
+		switch {
+		case key.Matches(msg, m.keys.Confirm):
+			entry := m.snapshotEntries[m.snapshotCursor]
+			m.snapshotMode = false
+			m.snapshotConfirm = false
+			m.snapshotEntries = nil
+			return m, tea.Batch(
+				m.engine.UpdateSnapshots(entry.TestPath),
+				notify(fmt.Sprintf("Updating snapshots for %s...", filepath.Base(entry.TestPath)), false),
+			)
+		case key.Matches(msg, m.keys.Cancel), key.Matches(msg, m.keys.ExitSearch):
+			m.snapshotConfirm = false
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.ExitSearch), key.Matches(msg, m.keys.SnapshotReview):
+		m.snapshotMode = false
+		m.snapshotEntries = nil
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Up):
+		if m.snapshotCursor > 0 {
+			m.snapshotCursor--
+			m.refreshSnapshotView()
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.snapshotCursor < len(m.snapshotEntries)-1 {
+			m.snapshotCursor++
+			m.refreshSnapshotView()
+		}
+	case key.Matches(msg, m.keys.Enter):
+		entry := m.snapshotEntries[m.snapshotCursor]
+		if !m.engine.CanUpdateSnapshots(entry.TestPath) {
+			return m, notify(fmt.Sprintf("Cannot update snapshots of %s: only Jest and Vitest are supported.", filepath.Base(entry.TestPath)), true)
+		}
+		m.snapshotConfirm = true
+	default:
+		var cmd tea.Cmd
+		m.snapshotViewport, cmd = m.snapshotViewport.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// snapshotListHeight is the number of rows the file list may occupy.
+func (m Model) snapshotListHeight() int {
+	return min(len(m.snapshotEntries), max(m.height/3, 1))
+}
+
+// refreshSnapshotView sizes the details viewport and renders the selected
+// entry's mismatches and obsolete snapshots into it.
+func (m *Model) refreshSnapshotView() {
+	if !m.snapshotMode || len(m.snapshotEntries) == 0 {
+		return
+	}
+	// Border(2), title(2), list, separator(1) and hints(1) vertically.
+	m.snapshotViewport.Width = max(m.width-4, 0)
+	m.snapshotViewport.Height = max(m.height-6-m.snapshotListHeight(), 0)
+
+	entry := m.snapshotEntries[m.snapshotCursor]
+	var b strings.Builder
+	for _, mismatch := range entry.Report.Mismatches {
+		b.WriteString(titleStyle.Render("Snapshot `" + mismatch.Snapshot + "`"))
+		b.WriteByte('\n')
+		colWidth := m.snapshotViewport.Width / 2
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+			diffExpectedHeaderStyle.Width(colWidth).Render("Snapshot"),
+			diffReceivedHeaderStyle.Width(colWidth).Render("Received"),
+		))
+		b.WriteByte('\n')
+		b.WriteString(renderDiffRows(mismatch, m.snapshotViewport.Width))
+		b.WriteByte('\n')
+	}
+	if entry.Report.Obsolete > 0 {
+		b.WriteString(titleStyle.Render(fmt.Sprintf("%d obsolete", entry.Report.Obsolete)))
+		b.WriteByte('\n')
+		for _, name := range entry.Report.ObsoleteNames {
+			b.WriteString("  • " + name + "\n")
+		}
+	}
+	if b.Len() == 0 {
+		b.WriteString(fmt.Sprintf("%d snapshot(s) written. Nothing to review.", entry.Report.Written))
+	}
+	m.snapshotViewport.SetContent(b.String())
+	m.snapshotViewport.GotoTop()
+}
+
+// renderSnapshotView renders the full-screen snapshot review.
+func (m Model) renderSnapshotView() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("SNAPSHOTS"))
+	b.WriteString("\n\n")
+
+	listHeight := m.snapshotListHeight()
+	start := 0
+	if m.snapshotCursor >= listHeight {
+		start = m.snapshotCursor - listHeight + 1
+	}
+	for i := start; i < start+listHeight && i < len(m.snapshotEntries); i++ {
+		b.WriteString(m.renderSnapshotEntry(m.snapshotEntries[i], i == m.snapshotCursor))
+		b.WriteByte('\n')
+	}
+
+	b.WriteString(lipgloss.NewStyle().Foreground(subtle).Render(strings.Repeat("─", max(m.width-4, 0))))
+	b.WriteByte('\n')
+	b.WriteString(m.snapshotViewport.View())
+	b.WriteByte('\n')
+
+	if m.snapshotConfirm {
+		entry := m.snapshotEntries[m.snapshotCursor]
+		b.WriteString(notificationErrorStyle.Render(fmt.Sprintf("Update snapshots in %s? (%s/%s)", m.relPath(entry.SnapshotPath), m.keys.Confirm.Help().Key, m.keys.Cancel.Help().Key)))
+	} else {
+		hints := []string{keyHint(m.keys.Up, "up"), keyHint(m.keys.Down, "down")}
+		if m.engine.CanUpdateSnapshots(m.snapshotEntries[m.snapshotCursor].TestPath) {
+			hints = append(hints, keyHint(m.keys.Enter, "accept (rerun with -u)"))
+		}
+		hints = append(hints, keyHint(m.snapshotViewport.KeyMap.PageDown, "scroll"), keyHint(m.keys.ExitSearch, "close"))
+		b.WriteString(statusStyle.Render(strings.Join(hints, " • ")))
+	}
+
+	return activePaneStyle.
+		Width(m.width - 2).
+		Height(m.height - 2).
+		Render(b.String())
+}
+
+// renderSnapshotEntry renders one row of the snapshot file list.
+func (m Model) renderSnapshotEntry(entry engine.SnapshotEntry, selected bool) string {
+	cursor := " "
+	if selected {
+		cursor = ">"
+	}
+	icon := "📸"
+	if entry.Report.Failed > 0 || len(entry.Report.Mismatches) > 0 {
+		icon = "❌"
+	}
+
+	var counts []string
+	if entry.Report.Failed > 0 {
+		counts = append(counts, fmt.Sprintf("%d failed", entry.Report.Failed))
+	}
+	if entry.Report.Obsolete > 0 {
+		counts = append(counts, fmt.Sprintf("%d obsolete", entry.Report.Obsolete))
+	}
+	if entry.Report.Written > 0 {
+		counts = append(counts, fmt.Sprintf("%d written", entry.Report.Written))
+	}
+
+	line := fmt.Sprintf("%s %s %s  %s", cursor, icon, m.relPath(entry.SnapshotPath),
+		lipgloss.NewStyle().Foreground(subtle).Render(strings.Join(counts, " • ")))
+	if selected {
+		return lipgloss.NewStyle().Foreground(highlight).Render(line)
+	}
+	return line
+}
+
+// relPath returns path relative to the project root, or path itself if it is
+// outside the root.
+func (m Model) relPath(path string) string {
+	if rel, err := filepath.Rel(m.engine.State.RootPath, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
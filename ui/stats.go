@@ -0,0 +1,214 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jesspatton/lazytest/history"
+)
+
+// statsTopN is how many files each stats section lists.
+const statsTopN = 5
+
+// statsRow is one line of the Stats tab. Rows with a path are selectable and
+// navigate to that file.
+type statsRow struct {
+	text   string
+	path   string
+	header bool
+	dim    bool
+}
+
+// statsRows returns the Stats tab rows built by the last refreshStats.
+func (m Model) statsRows() []statsRow {
+	return m.statsCache
+}
+
+// refreshStats rebuilds the Stats tab rows when runs were recorded since they
+// were last built, so rendering and navigation never re-summarize the history.
+func (m *Model) refreshStats() {
+	if n := m.engine.History.Len(); n != m.statsRunCount {
+		m.rebuildStats()
+	}
+}
+
+// rebuildStats builds the Stats tab rows from the run history. The summary
+// always shows both the session and all-time totals; the sections below cover
+// the scope chosen with the StatsScope key.
+func (m *Model) rebuildStats() {
+	m.statsCache = m.buildStatsRows()
+	m.statsRunCount = m.engine.History.Len()
+}
+
+func (m Model) buildStatsRows() []statsRow {
+	store := m.engine.History
+	all, session := store.Runs(), store.SessionRuns()
+	if len(all) == 0 {
+		return nil
+	}
+
+	summary := func(label string, runs []history.Run) statsRow {
+		r := history.Summarize(runs)
+		return statsRow{text: fmt.Sprintf("%-9s %d runs • %d failed • %s", label, r.Runs, r.Failures, formatDuration(r.TimeSpent))}
+	}
+	rows := []statsRow{summary("Session", session), summary("All time", all), {}}
+
+	runs := all
+	if m.statsSessionOnly {
+		runs = session
+	}
+	report := history.Summarize(runs)
+
+	section := func(title string, files []history.FileStats, value func(history.FileStats) string) {
+		rows = append(rows, statsRow{text: title, header: true})
+		if len(files) == 0 {
+			rows = append(rows, statsRow{text: "none yet", dim: true})
+		}
+		for _, f := range files {
+			rows = append(rows, statsRow{text: fmt.Sprintf("%8s  %s", value(f), m.relPath(f.Path)), path: f.Path})
+		}
+		rows = append(rows, statsRow{})
+	}
+	section("Slowest (avg)", report.Slowest(statsTopN), func(f history.FileStats) string {
+		return formatDuration(f.Average())
+	})
+	section("Most failing", report.MostFailing(statsTopN), func(f history.FileStats) string {
+		return fmt.Sprintf("%d/%d", f.Failures, f.Runs)
+	})
+	section("Flaky candidates", report.Flaky(statsTopN), func(f history.FileStats) string {
+		return fmt.Sprintf("%d flips", f.Flips)
+	})
+
+	rows = append(rows, statsRow{text: "Pass rate by workspace", header: true})
+	for _, g := range history.GroupBy(runs, m.engine.WorkspaceName) {
+		rows = append(rows, statsRow{text: fmt.Sprintf("%8s  %s (%d runs)", fmt.Sprintf("%.0f%%", g.PassRate()*100), g.Name, g.Runs)})
+	}
+	return rows
+}
+
+// statsSelectable returns the indices of the rows that can be selected.
+func statsSelectable(rows []statsRow) []int {
+	var idx []int
+	for i, row := range rows {
+		if row.path != "" {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// statsCursorRow returns the index of the selected row, or -1 when nothing
+// is selectable.
+func (m Model) statsCursorRow(rows []statsRow) int {
+	selectable := statsSelectable(rows)
+	if len(selectable) == 0 {
+		return -1
+	}
+	return selectable[min(m.statsCursor, len(selectable)-1)]
+}
+
+// selectedStatsPath returns the file on the selected Stats row.
+func (m Model) selectedStatsPath() (string, bool) {
+	rows := m.statsRows()
+	if row := m.statsCursorRow(rows); row >= 0 {
+		return rows[row].path, true
+	}
+	return "", false
+}
+
+// statsWindowStart returns the first visible row so the selected row fits in
+// height rows.
+func (m Model) statsWindowStart(rows []statsRow, height int) int {
+	if row := m.statsCursorRow(rows); row >= height {
+		return row - height + 1
+	}
+	return 0
+}
+
+// handleStatsKey handles keys while the Stats tab is active.
+func (m Model) handleStatsKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	selectable := statsSelectable(m.statsRows())
+	// New runs may have shortened the lists since the cursor last moved.
+	m.statsCursor = min(m.statsCursor, max(len(selectable)-1, 0))
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.statsCursor > 0 {
+			m.statsCursor--
+			m.syncViewportOutput()
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.statsCursor < len(selectable)-1 {
+			m.statsCursor++
+			m.syncViewportOutput()
+		}
+	case key.Matches(msg, m.keys.StatsScope):
+		m.statsSessionOnly = !m.statsSessionOnly
+		m.statsCursor = 0
+		m.rebuildStats()
+		m.syncViewportOutput()
+	case key.Matches(msg, m.keys.Enter):
+		if path, ok := m.selectedStatsPath(); ok {
+			m.jumpToPath(path)
+		}
+	}
+	return m, nil
+}
+
+// jumpToPath selects path in the Explorer tab.
+func (m *Model) jumpToPath(path string) {
+	m.activePane = PaneExplorer
+	m.activeTab = TabExplorer
+	m.revealPath(path, true)
+	m.syncViewportOutput()
+}
+
+// renderStats writes the visible window of the Stats tab to b.
+func (m Model) renderStats(b *strings.Builder, width, height int) {
+	rows := m.statsRows()
+	if len(rows) == 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(subtle).Render("No runs recorded yet.\nRun some tests to collect stats."))
+		return
+	}
+
+	cursorRow := m.statsCursorRow(rows)
+	line := lipgloss.NewStyle().MaxWidth(width)
+	start := m.statsWindowStart(rows, height)
+	for i := start; i < start+height && i < len(rows); i++ {
+		row := rows[i]
+		switch {
+		case row.header:
+			b.WriteString(titleStyle.Padding(0).Render(row.text))
+		case i == cursorRow:
+			b.WriteString(line.Foreground(highlight).Render("> " + row.text))
+		case row.dim:
+			b.WriteString(line.Foreground(subtle).Render("  " + row.text))
+		default:
+			b.WriteString(line.Render("  " + row.text))
+		}
+		b.WriteByte('\n')
+	}
+}
+
+// statsTabLabel names the Stats tab and its scope.
+func (m Model) statsTabLabel() string {
+	if m.statsSessionOnly {
+		return "Stats: session"
+	}
+	return "Stats"
+}
+
+// formatDuration renders d compactly: "850ms", "1.2s" or "3m4s".
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d < time.Minute:
+		return d.Round(100 * time.Millisecond).String()
+	default:
+		return d.Round(time.Second).String()
+	}
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/history"
+)
+
+func statsModel(t *testing.T) Model {
+	t.Helper()
+	m := foldModel(t)
+	now := time.Now()
+	for _, r := range []history.Run{
+		{Path: "/p/src/a.test.ts", Passed: true, DurationMs: 200, Time: now},
+		{Path: "/p/src/nested/b.test.ts", Passed: false, DurationMs: 3000, Time: now},
+	} {
+		m.engine.History.Add(r)
+	}
+	m.refreshStats()
+	m.activeTab = TabStats
+	return m
+}
+
+func TestStats_RowsFromHistory(t *testing.T) {
+	m := statsModel(t)
+	rows := m.statsRows()
+
+	var slowest []string
+	for i, row := range rows {
+		if row.header && row.text == "Slowest (avg)" {
+			slowest = []string{rows[i+1].path, rows[i+2].path}
+		}
+	}
+	if len(slowest) != 2 || slowest[0] != "/p/src/nested/b.test.ts" || slowest[1] != "/p/src/a.test.ts" {
+		t.Errorf("Expected b then a by duration, got %v", slowest)
+	}
+	if got := rows[0].text; got != "Session   2 runs • 1 failed • 3.2s" {
+		t.Errorf("Unexpected session summary %q", got)
+	}
+}
+
+func TestStats_SelectAndJump(t *testing.T) {
+	m := statsModel(t)
+	if path, _ := m.selectedStatsPath(); path != "/p/src/nested/b.test.ts" {
+		t.Fatalf("Expected the first selectable row to be the slowest file, got %q", path)
+	}
+
+	m = sendKey(m, runes("j"))
+	if path, _ := m.selectedStatsPath(); path != "/p/src/a.test.ts" {
+		t.Fatalf("Expected j to move to the next file, got %q", path)
+	}
+
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.activeTab != TabExplorer || m.flatNodes[m.cursor].Path != "/p/src/a.test.ts" {
+		t.Errorf("Expected enter to select a.test.ts in the explorer, got tab %v cursor %v", m.activeTab, displayNames(m)[m.cursor])
+	}
+}
+
+func TestStats_TabCycle(t *testing.T) {
+	m := foldModel(t)
+	m = sendKey(m, runes("["))
+	if m.activeTab != TabStats {
+		t.Errorf("Expected [ to wrap to the Stats tab, got %v", m.activeTab)
+	}
+	m = sendKey(m, runes("]"))
+	if m.activeTab != TabExplorer {
+		t.Errorf("Expected ] to wrap back to the Explorer tab, got %v", m.activeTab)
+	}
+}
+
+func TestStats_Renders(t *testing.T) {
+	updated, _ := statsModel(t).Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	view := updated.(Model).View()
+	for _, want := range []string{"Stats", "Slowest (avg)", "Flaky candidates", "Pass rate by workspace", "> "} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the stats tab", want)
+		}
+	}
+}
+
+func TestStats_RebuildsOnlyWhenRunsChange(t *testing.T) {
+	m := statsModel(t)
+	rows := m.statsRows()
+
+	m.refreshStats()
+	if &m.statsRows()[0] != &rows[0] {
+		t.Error("Expected the rows to be reused while no run was recorded")
+	}
+
+	m.engine.History.Add(history.Run{Path: "/p/src/a.test.ts", Passed: true, DurationMs: 100, Time: time.Now()})
+	m.refreshStats()
+	if got := m.statsRows()[0].text; !strings.HasPrefix(got, "Session   3 runs") {
+		t.Errorf("Expected the new run in the session summary, got %q", got)
+	}
+}
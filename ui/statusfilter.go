@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/engine"
+)
+
+// statusFilter is a set of test states the explorer is restricted to. A file
+// is shown when it matches any state in the set; the zero value shows
+// everything.
+type statusFilter uint8
+
+const (
+	filterFailing statusFilter = 1 << iota
+	filterRunning
+	filterNeverRun
+	filterPassed
+	filterWatched
+)
+
+// statusFilterLabels lists each filter state with its header label, in
+// display order.
+var statusFilterLabels = []struct {
+	state statusFilter
+	label string
+}{
+	{filterFailing, "failing"},
+	{filterRunning, "running"},
+	{filterNeverRun, "never run"},
+	{filterPassed, "passed"},
+	{filterWatched, "watched"},
+}
+
+// Labels returns the names of the states in the set.
+func (f statusFilter) Labels() []string {
+	var labels []string
+	for _, l := range statusFilterLabels {
+		if f&l.state != 0 {
+			labels = append(labels, l.label)
+		}
+	}
+	return labels
+}
+
+// statusFilterFunc returns the explorer's file predicate for the active status
+// filter, or nil when no filter is set.
+func (m Model) statusFilterFunc() func(path string) bool {
+	if m.statusFilter == 0 {
+		return nil
+	}
+	filter := m.statusFilter
+	eng := m.engine
+	return func(path string) bool {
+		status, ran := eng.GetNodeStatus(path)
+		switch {
+		case filter&filterFailing != 0 && ran && status == engine.StatusFail:
+			return true
+		case filter&filterRunning != 0 && ran && status == engine.StatusRunning:
+			return true
+		case filter&filterNeverRun != 0 && (!ran || status == engine.StatusIdle):
+			return true
+		case filter&filterPassed != 0 && ran && status == engine.StatusPass:
+			return true
+		case filter&filterWatched != 0 && eng.IsWatched(path):
+			return true
+		}
+		return false
+	}
+}
+
+// toggleStatusFilter toggles a state in the explorer filter, or clears the
+// filter entirely when state is zero.
+func (m *Model) toggleStatusFilter(state statusFilter) {
+	if state == 0 {
+		m.statusFilter = 0
+	} else {
+		m.statusFilter ^= state
+	}
+	m.rebuildFlatNodes()
+}
+
+// refreshStatusFilter re-applies an active status filter after test states
+// or the watched set may have changed.
+func (m *Model) refreshStatusFilter() {
+	if m.statusFilter != 0 {
+		m.rebuildFlatNodes()
+	}
+}
+
+// statusFilterKey maps a key message to the filter state it toggles.
+func (m Model) statusFilterKey(msg tea.KeyMsg) (statusFilter, bool) {
+	bindings := []struct {
+		binding key.Binding
+		state   statusFilter
+	}{
+		{m.keys.FilterFailing, filterFailing},
+		{m.keys.FilterRunning, filterRunning},
+		{m.keys.FilterNeverRun, filterNeverRun},
+		{m.keys.FilterPassed, filterPassed},
+		{m.keys.FilterWatched, filterWatched},
+		{m.keys.ClearStatusFilter, 0},
+	}
+	for _, b := range bindings {
+		if key.Matches(msg, b.binding) {
+			return b.state, true
+		}
+	}
+	return 0, false
+}
+
+// explorerTabLabel returns the Explorer tab's title, naming the active status
+// filter if there is one.
+func (m Model) explorerTabLabel() string {
+	if m.statusFilter == 0 {
+		return "Explorer"
+	}
+	return "Explorer: " + strings.Join(m.statusFilter.Labels(), "+")
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/engine"
+	"github.com/jesspatton/lazytest/runner"
+)
+
+func TestStatusFilter_ShowsFailingInTreeContext(t *testing.T) {
+	m := foldModel(t)
+	m.engine.State.NodeStatus["/p/src/nested/b.test.ts"] = engine.StatusFail
+	m.engine.State.NodeStatus["/p/src/a.test.ts"] = engine.StatusPass
+
+	m = sendKey(m, runes("1"))
+	if want := []string{"src", "nested", "b.test.ts"}; !reflect.DeepEqual(displayNames(m), want) {
+		t.Errorf("Expected %v, got %v", want, displayNames(m))
+	}
+	if got := m.explorerTabLabel(); got != "Explorer: failing" {
+		t.Errorf("Unexpected tab label %q", got)
+	}
+
+	// Filters combine: failing or passed shows both files.
+	m = sendKey(m, runes("4"))
+	if len(m.flatNodes) != 4 {
+		t.Errorf("Expected failing+passed to show every file, got %v", displayNames(m))
+	}
+
+	m = sendKey(m, runes("0"))
+	if m.statusFilter != 0 || m.explorerTabLabel() != "Explorer" {
+		t.Errorf("Expected 0 to clear the filter, got %v", m.statusFilter.Labels())
+	}
+}
+
+func TestStatusFilter_HidesEmptyDirectories(t *testing.T) {
+	m := foldModel(t)
+	m.engine.State.NodeStatus["/p/src/nested/b.test.ts"] = engine.StatusPass
+
+	m = sendKey(m, runes("3"))
+	if want := []string{"src", "a.test.ts"}; !reflect.DeepEqual(displayNames(m), want) {
+		t.Errorf("Expected 'nested' to be hidden, got %v", displayNames(m))
+	}
+}
+
+func TestStatusFilter_UpdatesOnStatusChange(t *testing.T) {
+	m := foldModel(t)
+	m = sendKey(m, runes("1"))
+	if len(m.flatNodes) != 0 {
+		t.Fatalf("Expected nothing failing yet, got %v", displayNames(m))
+	}
+
+	m.engine.State.NodeStatus["/p/src/a.test.ts"] = engine.StatusFail
+	newModel, _ := m.Update(runner.StatusUpdate{FilePath: "/p/src/a.test.ts"})
+	m = newModel.(Model)
+	if want := []string{"src", "a.test.ts"}; !reflect.DeepEqual(displayNames(m), want) {
+		t.Errorf("Expected the new failure to appear, got %v", displayNames(m))
+	}
+}
+
+func TestStatusFilter_WatchedFiles(t *testing.T) {
+	m := foldModel(t)
+	m = sendKey(m, runes("5"))
+	if len(m.flatNodes) != 0 {
+		t.Fatalf("Expected nothing watched yet, got %v", displayNames(m))
+	}
+
+	m.engine.ToggleWatch("/p/src/a.test.ts")
+	m = sendKey(m, tea.KeyMsg{Type: tea.KeyDown})
+	if want := []string{"src", "a.test.ts"}; !reflect.DeepEqual(displayNames(m), want) {
+		t.Errorf("Expected the watched file to appear, got %v", displayNames(m))
+	}
+}
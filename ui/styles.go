@@ -1,46 +1,152 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/lipgloss"
+)
 
+// The colors and styles below are derived from the active Theme by
+// applyTheme; they start out with the default theme.
 var (
 	// Colors
-	subtle    = lipgloss.AdaptiveColor{Light: "#D9DCCF", Dark: "#626262"}
-	highlight = lipgloss.AdaptiveColor{Light: "#874BFD", Dark: "#7D56F4"}
-	special   = lipgloss.AdaptiveColor{Light: "#43BF6D", Dark: "#73F59F"}
-	warning   = lipgloss.AdaptiveColor{Light: "#F25D94", Dark: "#F55081"}
+	subtle        lipgloss.TerminalColor
+	highlight     lipgloss.TerminalColor
+	special       lipgloss.TerminalColor
+	passColor     lipgloss.TerminalColor
+	failColor     lipgloss.TerminalColor
+	runningColor  lipgloss.TerminalColor
+	smartColor    lipgloss.TerminalColor
+	buildingColor lipgloss.TerminalColor
+	hintColor     lipgloss.TerminalColor
+	helpKeyColor  lipgloss.TerminalColor
+	helpDescColor lipgloss.TerminalColor
+	helpSepColor  lipgloss.TerminalColor
+	activeBorder  lipgloss.TerminalColor
 
 	// Borders
+	paneStyle       lipgloss.Style
+	activePaneStyle lipgloss.Style
+
+	// Text
+	titleStyle   lipgloss.Style
+	statusStyle  lipgloss.Style
+	welcomeStyle lipgloss.Style
+
+	notificationInfoStyle  lipgloss.Style
+	notificationErrorStyle lipgloss.Style
+
+	// Explorer search
+	searchMatchStyle lipgloss.Style
+
+	// Multi-select
+	markedStyle lipgloss.Style
+
+	// Fuzzy finder
+	fuzzyMatchStyle lipgloss.Style
+
+	// Diff view (Jest convention: expected is green, received is red)
+	diffExpectedStyle       lipgloss.Style
+	diffReceivedStyle       lipgloss.Style
+	diffExpectedWordStyle   lipgloss.Style
+	diffReceivedWordStyle   lipgloss.Style
+	diffExpectedHeaderStyle lipgloss.Style
+	diffReceivedHeaderStyle lipgloss.Style
+)
+
+func init() {
+	applyTheme(builtinThemes["default"].resolve())
+}
+
+// applyTheme rebuilds every package style from t, which must be resolved.
+func applyTheme(t Theme) {
+	subtle = t.Subtle
+	highlight = t.Highlight
+	special = t.Accent
+	passColor = t.StatusPass
+	failColor = t.StatusFail
+	runningColor = t.StatusRunning
+	smartColor = t.BadgeSmart
+	buildingColor = t.BadgeBuilding
+	hintColor = t.Hint
+	helpKeyColor = t.HelpKey
+	helpDescColor = t.HelpDescription
+	helpSepColor = t.HelpSeparator
+	activeBorder = t.PaneBorderActive
+
 	paneStyle = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder()).
-			BorderForeground(subtle).
-			Padding(0, 1)
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(t.PaneBorder).
+		Padding(0, 1)
 
 	activePaneStyle = paneStyle.Copy().
-			BorderForeground(highlight)
+		BorderForeground(t.PaneBorderActive)
 
-	// Text
 	titleStyle = lipgloss.NewStyle().
-			Foreground(highlight).
-			Bold(true).
-			Padding(0, 1)
+		Foreground(t.Title).
+		Bold(true).
+		Padding(0, 1)
 
 	statusStyle = lipgloss.NewStyle().
-			Foreground(subtle).
-			Padding(0, 1)
+		Foreground(t.Subtle).
+		Padding(0, 1)
 
 	welcomeStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			Padding(1, 2)
+		Foreground(t.Welcome).
+		Padding(1, 2)
 
 	notificationInfoStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.AdaptiveColor{Light: "#3182CE", Dark: "#2B6CB0"}).
-			Bold(true).
-			Padding(0, 1)
+		Foreground(t.NotificationForeground).
+		Background(t.NotificationInfoBackground).
+		Bold(true).
+		Padding(0, 1)
 
 	notificationErrorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(warning).
-			Bold(true).
-			Padding(0, 1)
-)
+		Foreground(t.NotificationForeground).
+		Background(t.NotificationErrorBackground).
+		Bold(true).
+		Padding(0, 1)
+
+	searchMatchStyle = lipgloss.NewStyle().
+		Foreground(t.SearchMatchForeground).
+		Background(t.SearchMatchBackground)
+
+	markedStyle = lipgloss.NewStyle().
+		Background(t.MarkedBackground).
+		Bold(true)
+
+	fuzzyMatchStyle = lipgloss.NewStyle().
+		Foreground(t.FuzzyMatch).
+		Bold(true)
+
+	diffExpectedStyle = lipgloss.NewStyle().
+		Foreground(t.DiffExpected)
+
+	diffReceivedStyle = lipgloss.NewStyle().
+		Foreground(t.DiffReceived)
+
+	diffExpectedWordStyle = lipgloss.NewStyle().
+		Foreground(t.DiffWordForeground).
+		Background(t.DiffExpectedWordBackground).
+		Bold(true)
+
+	diffReceivedWordStyle = lipgloss.NewStyle().
+		Foreground(t.DiffWordForeground).
+		Background(t.DiffReceivedWordBackground).
+		Bold(true)
+
+	diffExpectedHeaderStyle = diffExpectedStyle.Copy().Bold(true).Underline(true)
+
+	diffReceivedHeaderStyle = diffReceivedStyle.Copy().Bold(true).Underline(true)
+}
+
+// helpStyles returns the help view styles for the active theme.
+func helpStyles() help.Styles {
+	s := help.New().Styles
+	s.ShortKey = lipgloss.NewStyle().Foreground(helpKeyColor)
+	s.ShortDesc = lipgloss.NewStyle().Foreground(helpDescColor)
+	s.ShortSeparator = lipgloss.NewStyle().Foreground(helpSepColor)
+	s.FullKey = lipgloss.NewStyle().Foreground(helpKeyColor)
+	s.FullDesc = lipgloss.NewStyle().Foreground(helpDescColor)
+	s.FullSeparator = lipgloss.NewStyle().Foreground(helpSepColor)
+	return s
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jesspatton/lazytest/filesystem"
@@ -24,11 +25,19 @@ func (m *Model) syncViewportOutput() {
 
 	var content string
 
-	if m.activeTab == TabWatched {
+	if m.activeTab == TabStats {
+		if path, ok := m.selectedStatsPath(); ok {
+			if out, ok := m.testOutput(path); ok && out != "" {
+				content = out
+			} else {
+				content = fmt.Sprintf("No output this session for %s.\nPress <%s> to jump to it in the explorer.", m.relPath(path), m.keys.Enter.Help().Key)
+			}
+		}
+	} else if m.activeTab == TabWatched {
 		tabList, emptyMsg := m.getTabList()
 		if m.watchedCursor < len(tabList) {
 			path := tabList[m.watchedCursor]
-			if out, ok := m.engine.GetTestOutput(path); ok && out != "" {
+			if out, ok := m.testOutput(path); ok && out != "" {
 				content = out
 			} else {
 				content = "No output yet."
@@ -42,21 +51,26 @@ func (m *Model) syncViewportOutput() {
 		if m.cursor < len(m.flatNodes) {
 			node := m.flatNodes[m.cursor]
 			if !node.IsDir {
-				if out, ok := m.engine.GetTestOutput(node.Path); ok && out != "" {
+				if out, ok := m.testOutput(node.Path); ok && out != "" {
 					content = out
 				} else if !m.engine.HasAnyOutput() && welcome != "" {
 					// No test has run yet — show the welcome banner.
 					content = welcomeStyle.Render(welcome)
 				} else if filesystem.IsTestFile(node.Name) {
-					content = "No output yet for this test file.\nPress <Enter> to run, 'w' to watch, or 's' for Smart Mode."
+					content = fmt.Sprintf("No output yet for this test file.\nPress <%s> to run, '%s' to watch, or '%s' for Smart Mode.",
+						m.keys.Enter.Help().Key, m.keys.ToggleWatch.Help().Key, m.keys.ToggleSmartMode.Help().Key)
 				} else {
-					content = fmt.Sprintf("Source file: %s\nPress 'w' to watch or 's' for Smart Mode.", node.Name)
+					content = fmt.Sprintf("Source file: %s\nPress '%s' to watch or '%s' for Smart Mode.",
+						node.Name, m.keys.ToggleWatch.Help().Key, m.keys.ToggleSmartMode.Help().Key)
 				}
 			} else {
 				if !m.engine.HasAnyOutput() && welcome != "" {
 					content = welcomeStyle.Render(welcome)
 				} else {
-					content = fmt.Sprintf("Directory: %s", node.Name)
+					files := node.Files()
+					passed, failed, running := m.engine.GetStatusCounts(files)
+					content = fmt.Sprintf("Directory: %s\n%d test files • %d passed • %d failed • %d running\nPress <Enter> to run all, 'w' to watch all.",
+						node.DisplayName, len(files), passed, failed, running)
 				}
 			}
 		}
@@ -73,11 +87,50 @@ func (m *Model) syncViewportOutput() {
 	m.viewport.SetContent(m.wrapOutput(m.viewport.Width, content))
 }
 
+// selectedPath returns the path of the test file selected in the active tab.
+// It returns false when the cursor is on a directory or the list is empty.
+func (m *Model) selectedPath() (string, bool) {
+	if m.activeTab == TabStats {
+		return m.selectedStatsPath()
+	}
+	if m.activeTab == TabWatched {
+		tabList, _ := m.getTabList()
+		if m.watchedCursor < len(tabList) {
+			return tabList[m.watchedCursor], true
+		}
+		return "", false
+	}
+	if m.cursor < len(m.flatNodes) && !m.flatNodes[m.cursor].IsDir {
+		return m.flatNodes[m.cursor].Path, true
+	}
+	return "", false
+}
+
 // getTabList returns the list of paths and an empty-state hint message for the
 // currently active tab, accounting for Smart Mode vs. Manual Watch Mode.
 func (m *Model) getTabList() ([]string, string) {
 	if m.engine.IsSmartMode() {
 		return m.engine.GetAffectedSuite(), "No tests affected yet.\nEdit a source file to trigger Smart Mode."
 	}
-	return m.engine.GetWatchedFiles(), "No watched files.\nPress 'w' on a file to watch it."
+	return m.engine.GetWatchedFiles(), fmt.Sprintf("No watched files.\nPress '%s' on a file to watch it.", m.keys.ToggleWatch.Help().Key)
+}
+
+// testOutput returns the stored output for path with the active output
+// filters applied. When the filters remove everything, a hint naming them is
+// returned instead so the pane never looks stale.
+func (m *Model) testOutput(path string) (string, bool) {
+	out, ok := m.engine.GetTestOutput(path)
+	if !ok || out == "" || !m.outputFilter.Active() {
+		return out, ok
+	}
+
+	var runnerName string
+	if m.outputFilter.FailuresOnly {
+		runnerName = m.engine.GetRunnerName(path)
+	}
+	filtered := m.outputFilter.Apply(out, m.engine.GetTestStderr(path), runnerName)
+	if strings.TrimSpace(filtered) == "" {
+		filtered = fmt.Sprintf("No output matches the active filters (%s).", strings.Join(m.outputFilter.Labels(), ", "))
+	}
+	return filtered, true
 }
@@ -0,0 +1,419 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme assigns a color to every style slot of the UI. A nil slot falls back
+// to a related slot when the theme is resolved (see resolve).
+type Theme struct {
+	Name string
+
+	// Panes and text
+	PaneBorder       lipgloss.TerminalColor
+	PaneBorderActive lipgloss.TerminalColor
+	Title            lipgloss.TerminalColor
+	Subtle           lipgloss.TerminalColor
+	Highlight        lipgloss.TerminalColor
+	Accent           lipgloss.TerminalColor
+	Hint             lipgloss.TerminalColor
+	Welcome          lipgloss.TerminalColor
+
+	// Explorer highlights
+	MarkedBackground      lipgloss.TerminalColor
+	SearchMatchForeground lipgloss.TerminalColor
+	SearchMatchBackground lipgloss.TerminalColor
+	FuzzyMatch            lipgloss.TerminalColor
+
+	// Test statuses and diffs
+	StatusPass                 lipgloss.TerminalColor
+	StatusFail                 lipgloss.TerminalColor
+	StatusRunning              lipgloss.TerminalColor
+	DiffExpected               lipgloss.TerminalColor
+	DiffReceived               lipgloss.TerminalColor
+	DiffWordForeground         lipgloss.TerminalColor
+	DiffExpectedWordBackground lipgloss.TerminalColor
+	DiffReceivedWordBackground lipgloss.TerminalColor
+
+	// Badges
+	BadgeSmart    lipgloss.TerminalColor
+	BadgeBuilding lipgloss.TerminalColor
+
+	// Notifications
+	NotificationForeground      lipgloss.TerminalColor
+	NotificationInfoBackground  lipgloss.TerminalColor
+	NotificationErrorBackground lipgloss.TerminalColor
+
+	// Help view
+	HelpKey         lipgloss.TerminalColor
+	HelpDescription lipgloss.TerminalColor
+	HelpSeparator   lipgloss.TerminalColor
+}
+
+// themeSlot names a Theme color for theme files.
+type themeSlot struct {
+	name  string
+	color *lipgloss.TerminalColor
+}
+
+// slots lists every color slot by its theme file name.
+func (t *Theme) slots() []themeSlot {
+	return []themeSlot{
+		{"pane_border", &t.PaneBorder},
+		{"pane_border_active", &t.PaneBorderActive},
+		{"title", &t.Title},
+		{"text_subtle", &t.Subtle},
+		{"highlight", &t.Highlight},
+		{"accent", &t.Accent},
+		{"hint", &t.Hint},
+		{"welcome", &t.Welcome},
+		{"marked_background", &t.MarkedBackground},
+		{"search_match_foreground", &t.SearchMatchForeground},
+		{"search_match_background", &t.SearchMatchBackground},
+		{"fuzzy_match", &t.FuzzyMatch},
+		{"status_pass", &t.StatusPass},
+		{"status_fail", &t.StatusFail},
+		{"status_running", &t.StatusRunning},
+		{"diff_expected", &t.DiffExpected},
+		{"diff_received", &t.DiffReceived},
+		{"diff_word_foreground", &t.DiffWordForeground},
+		{"diff_expected_word_background", &t.DiffExpectedWordBackground},
+		{"diff_received_word_background", &t.DiffReceivedWordBackground},
+		{"badge_smart", &t.BadgeSmart},
+		{"badge_building", &t.BadgeBuilding},
+		{"notification_foreground", &t.NotificationForeground},
+		{"notification_info_background", &t.NotificationInfoBackground},
+		{"notification_error_background", &t.NotificationErrorBackground},
+		{"help_key", &t.HelpKey},
+		{"help_description", &t.HelpDescription},
+		{"help_separator", &t.HelpSeparator},
+	}
+}
+
+// resolve fills unset slots from the slots they derive from, so a theme that
+// only changes "highlight" also recolors the active border and titles.
+func (t Theme) resolve() Theme {
+	fallback := func(slot *lipgloss.TerminalColor, from lipgloss.TerminalColor) {
+		if *slot == nil {
+			*slot = from
+		}
+	}
+	fallback(&t.PaneBorder, t.Subtle)
+	fallback(&t.PaneBorderActive, t.Highlight)
+	fallback(&t.Title, t.Highlight)
+	fallback(&t.FuzzyMatch, t.Accent)
+	fallback(&t.DiffExpected, t.StatusPass)
+	fallback(&t.DiffReceived, t.StatusFail)
+	return t
+}
+
+// builtinThemes are the themes selectable by name. "default" adapts to the
+// terminal background; the others use fixed colors.
+var builtinThemes = map[string]Theme{
+	"default": {
+		Name:                        "default",
+		Subtle:                      lipgloss.AdaptiveColor{Light: "#D9DCCF", Dark: "#626262"},
+		Highlight:                   lipgloss.AdaptiveColor{Light: "#874BFD", Dark: "#7D56F4"},
+		Accent:                      lipgloss.AdaptiveColor{Light: "#43BF6D", Dark: "#73F59F"},
+		Hint:                        lipgloss.Color("240"),
+		Welcome:                     lipgloss.Color("241"),
+		MarkedBackground:            lipgloss.AdaptiveColor{Light: "#E9E3FF", Dark: "#3B2F63"},
+		SearchMatchForeground:       lipgloss.Color("0"),
+		SearchMatchBackground:       lipgloss.Color("212"),
+		StatusPass:                  lipgloss.AdaptiveColor{Light: "#15803D", Dark: "#4ADE80"},
+		StatusFail:                  lipgloss.AdaptiveColor{Light: "#B91C1C", Dark: "#F87171"},
+		StatusRunning:               lipgloss.AdaptiveColor{Light: "#B45309", Dark: "#FCD34D"},
+		DiffWordForeground:          lipgloss.Color("#FFFFFF"),
+		DiffExpectedWordBackground:  lipgloss.AdaptiveColor{Light: "#15803D", Dark: "#166534"},
+		DiffReceivedWordBackground:  lipgloss.AdaptiveColor{Light: "#B91C1C", Dark: "#991B1B"},
+		BadgeSmart:                  lipgloss.AdaptiveColor{Light: "#7C3AED", Dark: "#A78BFA"},
+		BadgeBuilding:               lipgloss.AdaptiveColor{Light: "#0284C7", Dark: "#38BDF8"},
+		NotificationForeground:      lipgloss.Color("#FFFFFF"),
+		NotificationInfoBackground:  lipgloss.AdaptiveColor{Light: "#3182CE", Dark: "#2B6CB0"},
+		NotificationErrorBackground: lipgloss.AdaptiveColor{Light: "#F25D94", Dark: "#F55081"},
+		HelpKey:                     lipgloss.AdaptiveColor{Light: "#909090", Dark: "#A0A0A0"},
+		HelpDescription:             lipgloss.AdaptiveColor{Light: "#B0B0B0", Dark: "#808080"},
+		HelpSeparator:               lipgloss.AdaptiveColor{Light: "#D0D0D0", Dark: "#606060"},
+	},
+	"dark": {
+		Name:                        "dark",
+		Subtle:                      lipgloss.Color("#626262"),
+		Highlight:                   lipgloss.Color("#7D56F4"),
+		Accent:                      lipgloss.Color("#73F59F"),
+		Hint:                        lipgloss.Color("240"),
+		Welcome:                     lipgloss.Color("245"),
+		MarkedBackground:            lipgloss.Color("#3B2F63"),
+		SearchMatchForeground:       lipgloss.Color("0"),
+		SearchMatchBackground:       lipgloss.Color("212"),
+		StatusPass:                  lipgloss.Color("#4ADE80"),
+		StatusFail:                  lipgloss.Color("#F87171"),
+		StatusRunning:               lipgloss.Color("#FCD34D"),
+		DiffWordForeground:          lipgloss.Color("#FFFFFF"),
+		DiffExpectedWordBackground:  lipgloss.Color("#166534"),
+		DiffReceivedWordBackground:  lipgloss.Color("#991B1B"),
+		BadgeSmart:                  lipgloss.Color("#A78BFA"),
+		BadgeBuilding:               lipgloss.Color("#38BDF8"),
+		NotificationForeground:      lipgloss.Color("#FFFFFF"),
+		NotificationInfoBackground:  lipgloss.Color("#2B6CB0"),
+		NotificationErrorBackground: lipgloss.Color("#F55081"),
+		HelpKey:                     lipgloss.Color("#A0A0A0"),
+		HelpDescription:             lipgloss.Color("#808080"),
+		HelpSeparator:               lipgloss.Color("#606060"),
+	},
+	"light": {
+		Name:                        "light",
+		Subtle:                      lipgloss.Color("#A8AB9E"),
+		Highlight:                   lipgloss.Color("#874BFD"),
+		Accent:                      lipgloss.Color("#2F9E55"),
+		Hint:                        lipgloss.Color("244"),
+		Welcome:                     lipgloss.Color("241"),
+		MarkedBackground:            lipgloss.Color("#E9E3FF"),
+		SearchMatchForeground:       lipgloss.Color("#000000"),
+		SearchMatchBackground:       lipgloss.Color("#F9A8D4"),
+		StatusPass:                  lipgloss.Color("#15803D"),
+		StatusFail:                  lipgloss.Color("#B91C1C"),
+		StatusRunning:               lipgloss.Color("#B45309"),
+		DiffWordForeground:          lipgloss.Color("#FFFFFF"),
+		DiffExpectedWordBackground:  lipgloss.Color("#15803D"),
+		DiffReceivedWordBackground:  lipgloss.Color("#B91C1C"),
+		BadgeSmart:                  lipgloss.Color("#7C3AED"),
+		BadgeBuilding:               lipgloss.Color("#0284C7"),
+		NotificationForeground:      lipgloss.Color("#FFFFFF"),
+		NotificationInfoBackground:  lipgloss.Color("#3182CE"),
+		NotificationErrorBackground: lipgloss.Color("#F25D94"),
+		HelpKey:                     lipgloss.Color("#707070"),
+		HelpDescription:             lipgloss.Color("#909090"),
+		HelpSeparator:               lipgloss.Color("#C0C0C0"),
+	},
+	"high-contrast": {
+		Name:                        "high-contrast",
+		PaneBorder:                  lipgloss.Color("#FFFFFF"),
+		Subtle:                      lipgloss.Color("#D0D0D0"),
+		Highlight:                   lipgloss.Color("#FFFF00"),
+		Accent:                      lipgloss.Color("#00FF00"),
+		Hint:                        lipgloss.Color("#FFFFFF"),
+		Welcome:                     lipgloss.Color("#FFFFFF"),
+		MarkedBackground:            lipgloss.Color("#0000AA"),
+		SearchMatchForeground:       lipgloss.Color("#000000"),
+		SearchMatchBackground:       lipgloss.Color("#00FFFF"),
+		StatusPass:                  lipgloss.Color("#00FF00"),
+		StatusFail:                  lipgloss.Color("#FF3030"),
+		StatusRunning:               lipgloss.Color("#FFFF00"),
+		DiffWordForeground:          lipgloss.Color("#000000"),
+		DiffExpectedWordBackground:  lipgloss.Color("#00FF00"),
+		DiffReceivedWordBackground:  lipgloss.Color("#FF3030"),
+		BadgeSmart:                  lipgloss.Color("#FF00FF"),
+		BadgeBuilding:               lipgloss.Color("#00FFFF"),
+		NotificationForeground:      lipgloss.Color("#000000"),
+		NotificationInfoBackground:  lipgloss.Color("#00FFFF"),
+		NotificationErrorBackground: lipgloss.Color("#FF3030"),
+		HelpKey:                     lipgloss.Color("#FFFFFF"),
+		HelpDescription:             lipgloss.Color("#E0E0E0"),
+		HelpSeparator:               lipgloss.Color("#D0D0D0"),
+	},
+	"dracula": {
+		Name:                        "dracula",
+		Subtle:                      lipgloss.Color("#6272A4"),
+		Highlight:                   lipgloss.Color("#BD93F9"),
+		Accent:                      lipgloss.Color("#50FA7B"),
+		Hint:                        lipgloss.Color("#6272A4"),
+		Welcome:                     lipgloss.Color("#F8F8F2"),
+		MarkedBackground:            lipgloss.Color("#44475A"),
+		SearchMatchForeground:       lipgloss.Color("#282A36"),
+		SearchMatchBackground:       lipgloss.Color("#FF79C6"),
+		StatusPass:                  lipgloss.Color("#50FA7B"),
+		StatusFail:                  lipgloss.Color("#FF5555"),
+		StatusRunning:               lipgloss.Color("#F1FA8C"),
+		DiffWordForeground:          lipgloss.Color("#282A36"),
+		DiffExpectedWordBackground:  lipgloss.Color("#50FA7B"),
+		DiffReceivedWordBackground:  lipgloss.Color("#FF5555"),
+		BadgeSmart:                  lipgloss.Color("#FF79C6"),
+		BadgeBuilding:               lipgloss.Color("#8BE9FD"),
+		NotificationForeground:      lipgloss.Color("#282A36"),
+		NotificationInfoBackground:  lipgloss.Color("#8BE9FD"),
+		NotificationErrorBackground: lipgloss.Color("#FF5555"),
+		HelpKey:                     lipgloss.Color("#F8F8F2"),
+		HelpDescription:             lipgloss.Color("#6272A4"),
+		HelpSeparator:               lipgloss.Color("#44475A"),
+	},
+	"nord": {
+		Name:                        "nord",
+		Subtle:                      lipgloss.Color("#4C566A"),
+		Highlight:                   lipgloss.Color("#88C0D0"),
+		Accent:                      lipgloss.Color("#A3BE8C"),
+		Hint:                        lipgloss.Color("#4C566A"),
+		Welcome:                     lipgloss.Color("#D8DEE9"),
+		MarkedBackground:            lipgloss.Color("#3B4252"),
+		SearchMatchForeground:       lipgloss.Color("#2E3440"),
+		SearchMatchBackground:       lipgloss.Color("#EBCB8B"),
+		StatusPass:                  lipgloss.Color("#A3BE8C"),
+		StatusFail:                  lipgloss.Color("#BF616A"),
+		StatusRunning:               lipgloss.Color("#EBCB8B"),
+		DiffWordForeground:          lipgloss.Color("#2E3440"),
+		DiffExpectedWordBackground:  lipgloss.Color("#A3BE8C"),
+		DiffReceivedWordBackground:  lipgloss.Color("#BF616A"),
+		BadgeSmart:                  lipgloss.Color("#B48EAD"),
+		BadgeBuilding:               lipgloss.Color("#81A1C1"),
+		NotificationForeground:      lipgloss.Color("#2E3440"),
+		NotificationInfoBackground:  lipgloss.Color("#81A1C1"),
+		NotificationErrorBackground: lipgloss.Color("#BF616A"),
+		HelpKey:                     lipgloss.Color("#D8DEE9"),
+		HelpDescription:             lipgloss.Color("#4C566A"),
+		HelpSeparator:               lipgloss.Color("#3B4252"),
+	},
+	"gruvbox": {
+		Name:                        "gruvbox",
+		Subtle:                      lipgloss.Color("#928374"),
+		Highlight:                   lipgloss.Color("#FABD2F"),
+		Accent:                      lipgloss.Color("#B8BB26"),
+		Hint:                        lipgloss.Color("#928374"),
+		Welcome:                     lipgloss.Color("#EBDBB2"),
+		MarkedBackground:            lipgloss.Color("#3C3836"),
+		SearchMatchForeground:       lipgloss.Color("#282828"),
+		SearchMatchBackground:       lipgloss.Color("#FE8019"),
+		StatusPass:                  lipgloss.Color("#B8BB26"),
+		StatusFail:                  lipgloss.Color("#FB4934"),
+		StatusRunning:               lipgloss.Color("#FE8019"),
+		DiffWordForeground:          lipgloss.Color("#282828"),
+		DiffExpectedWordBackground:  lipgloss.Color("#B8BB26"),
+		DiffReceivedWordBackground:  lipgloss.Color("#FB4934"),
+		BadgeSmart:                  lipgloss.Color("#D3869B"),
+		BadgeBuilding:               lipgloss.Color("#83A598"),
+		NotificationForeground:      lipgloss.Color("#282828"),
+		NotificationInfoBackground:  lipgloss.Color("#83A598"),
+		NotificationErrorBackground: lipgloss.Color("#FB4934"),
+		HelpKey:                     lipgloss.Color("#EBDBB2"),
+		HelpDescription:             lipgloss.Color("#928374"),
+		HelpSeparator:               lipgloss.Color("#504945"),
+	},
+}
+
+// ThemeNames returns the names of the built-in themes, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// themeFile is the on-disk theme format: an optional built-in base theme and
+// per-slot color overrides.
+type themeFile struct {
+	Extends string                     `json:"extends"`
+	Colors  map[string]json.RawMessage `json:"colors"`
+}
+
+// LoadTheme resolves spec to a theme. spec is a built-in theme name, the name
+// of a file in themeDir (without ".json"), or a path to a theme file. An
+// empty spec selects the default theme.
+func LoadTheme(spec, themeDir string) (Theme, error) {
+	if spec == "" {
+		spec = "default"
+	}
+	if theme, ok := builtinThemes[spec]; ok {
+		return theme.resolve(), nil
+	}
+
+	path := spec
+	if !strings.ContainsRune(spec, filepath.Separator) && !strings.HasSuffix(spec, ".json") && themeDir != "" {
+		path = filepath.Join(themeDir, spec+".json")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, fmt.Errorf("unknown theme %q (built-in themes: %s)", spec, strings.Join(ThemeNames(), ", "))
+	}
+	return parseThemeFile(data, strings.TrimSuffix(filepath.Base(path), ".json"))
+}
+
+// parseThemeFile builds a theme from a theme file's contents.
+func parseThemeFile(data []byte, name string) (Theme, error) {
+	var file themeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Theme{}, fmt.Errorf("invalid theme %s: %w", name, err)
+	}
+
+	if file.Extends == "" {
+		file.Extends = "default"
+	}
+	theme, ok := builtinThemes[file.Extends]
+	if !ok {
+		return Theme{}, fmt.Errorf("theme %s extends unknown theme %q", name, file.Extends)
+	}
+	theme.Name = name
+
+	slots := make(map[string]*lipgloss.TerminalColor)
+	for _, s := range theme.slots() {
+		slots[s.name] = s.color
+	}
+	var problems []string
+	for slot, raw := range file.Colors {
+		target, ok := slots[slot]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown slot %q", slot))
+			continue
+		}
+		color, err := parseThemeColor(raw)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", slot, err))
+			continue
+		}
+		*target = color
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return Theme{}, fmt.Errorf("invalid theme %s: %s", name, strings.Join(problems, "; "))
+	}
+	return theme.resolve(), nil
+}
+
+// hexColorRegex matches "#rgb" and "#rrggbb" colors.
+var hexColorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// parseThemeColor reads a color as either a single value ("#7D56F4", "212")
+// or an adaptive pair ({"light": "#874BFD", "dark": "#7D56F4"}).
+func parseThemeColor(raw json.RawMessage) (lipgloss.TerminalColor, error) {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		if err := validateColor(single); err != nil {
+			return nil, err
+		}
+		return lipgloss.Color(single), nil
+	}
+
+	var pair struct {
+		Light string `json:"light"`
+		Dark  string `json:"dark"`
+	}
+	if err := json.Unmarshal(raw, &pair); err != nil {
+		return nil, fmt.Errorf("expected a color string or {\"light\", \"dark\"}")
+	}
+	if err := validateColor(pair.Light); err != nil {
+		return nil, err
+	}
+	if err := validateColor(pair.Dark); err != nil {
+		return nil, err
+	}
+	return lipgloss.AdaptiveColor{Light: pair.Light, Dark: pair.Dark}, nil
+}
+
+// validateColor accepts hex colors and ANSI 256 color numbers.
+func validateColor(s string) error {
+	if hexColorRegex.MatchString(s) {
+		return nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 255 {
+		return nil
+	}
+	return fmt.Errorf("invalid color %q", s)
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jesspatton/lazytest/config"
+)
+
+func TestBuiltinThemes_FillEverySlot(t *testing.T) {
+	for _, name := range ThemeNames() {
+		theme, err := LoadTheme(name, "")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, slot := range theme.slots() {
+			if *slot.color == nil {
+				t.Errorf("Theme %s leaves slot %s unset", name, slot.name)
+			}
+		}
+	}
+}
+
+func TestLoadTheme_FileOverridesAndDerivedSlots(t *testing.T) {
+	dir := t.TempDir()
+	content := `{
+		"extends": "nord",
+		"colors": {
+			"highlight": "#FF8800",
+			"status_fail": {"light": "#B00020", "dark": "#FF5370"}
+		}
+	}`
+	if err := os.WriteFile(filepath.Join(dir, "mine.json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	theme, err := LoadTheme("mine", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme.Highlight != lipgloss.Color("#FF8800") || theme.PaneBorderActive != lipgloss.Color("#FF8800") {
+		t.Errorf("Expected highlight and the derived active border to be overridden, got %v / %v", theme.Highlight, theme.PaneBorderActive)
+	}
+	if theme.StatusFail != (lipgloss.AdaptiveColor{Light: "#B00020", Dark: "#FF5370"}) {
+		t.Errorf("Expected adaptive fail color, got %v", theme.StatusFail)
+	}
+	if theme.StatusPass != builtinThemes["nord"].StatusPass {
+		t.Errorf("Expected untouched slots to come from nord, got %v", theme.StatusPass)
+	}
+}
+
+func TestLoadTheme_Errors(t *testing.T) {
+	if _, err := LoadTheme("no-such-theme", t.TempDir()); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+	if _, err := parseThemeFile([]byte(`{"colors": {"bogus": "#fff"}}`), "x"); err == nil {
+		t.Error("Expected an error for an unknown slot")
+	}
+	if _, err := parseThemeFile([]byte(`{"colors": {"highlight": "purple"}}`), "x"); err == nil {
+		t.Error("Expected an error for an invalid color")
+	}
+	if _, err := parseThemeFile([]byte(`{"extends": "nope"}`), "x"); err == nil {
+		t.Error("Expected an error for an unknown base theme")
+	}
+}
+
+func TestModel_ApplyUserConfigTheme(t *testing.T) {
+	t.Cleanup(func() { applyTheme(builtinThemes["default"].resolve()) })
+
+	m := dirModel(t)
+	if err := m.ApplyUserConfig(config.UserConfig{Theme: "dracula"}); err != nil {
+		t.Fatal(err)
+	}
+	if highlight != lipgloss.Color("#BD93F9") || passColor != lipgloss.Color("#50FA7B") {
+		t.Errorf("Expected dracula colors to be active, got %v / %v", highlight, passColor)
+	}
+
+	// A bad theme is reported and leaves the current styles alone.
+	if err := m.ApplyUserConfig(config.UserConfig{Theme: "no-such-theme"}); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+	if highlight != lipgloss.Color("#BD93F9") {
+		t.Errorf("Expected styles to be unchanged, got %v", highlight)
+	}
+}
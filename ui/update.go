@@ -6,7 +6,6 @@ import (
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
-	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jesspatton/lazytest/engine"
 	"github.com/jesspatton/lazytest/filesystem"
@@ -26,11 +25,37 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Let engine handle business logic
 	cmd = m.engine.Update(msg)
 	cmds = append(cmds, cmd)
+	m.refreshStats()
+	m.refreshDirCounts()
 
 	switch msg := msg.(type) {
 	case tea.MouseMsg:
 		return m.handleMouse(msg)
 	case tea.KeyMsg:
+		// The explanation closes on any key.
+		if m.whyText != "" {
+			m.whyText = ""
+			return m, tea.Batch(cmds...)
+		}
+		// The diff view is modal and captures every key while open.
+		if m.diffMode {
+			m, cmd = m.handleDiffKey(msg)
+			return m, tea.Batch(append(cmds, cmd)...)
+		}
+		if m.snapshotMode {
+			m, cmd = m.handleSnapshotKey(msg)
+			return m, tea.Batch(append(cmds, cmd)...)
+		}
+		if m.finderMode {
+			m, cmd = m.handleFinderKey(msg)
+			return m, tea.Batch(append(cmds, cmd)...)
+		}
+		// The stroke after "z" is a fold command, so it must not reach the
+		// global bindings ("zR" is not a refresh).
+		if m.pendingZ {
+			return m.handleFoldKey(msg), tea.Batch(cmds...)
+		}
+
 		// Handle global keys (except when in search mode, some keys might be overridden)
 		if !m.searchMode {
 			switch {
@@ -45,6 +70,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.activePane = PaneOutput
 				} else {
 					m.activePane = PaneExplorer
+					// The explorer is hidden while the output is zoomed.
+					if m.layout.zoomed {
+						m.toggleZoom()
+					}
 				}
 			case key.Matches(msg, m.keys.Refresh):
 				return m, m.engine.RefreshTree
@@ -52,20 +81,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, m.engine.ReRunLast()
 			case key.Matches(msg, m.keys.NextTab):
 				if m.activePane == PaneExplorer {
-					if m.activeTab == TabExplorer {
-						m.activeTab = TabWatched
-					} else {
-						m.activeTab = TabExplorer
-					}
+					m.activeTab = (m.activeTab + 1) % tabCount
 					m.syncViewportOutput()
 				}
 			case key.Matches(msg, m.keys.PrevTab):
 				if m.activePane == PaneExplorer {
-					if m.activeTab == TabExplorer {
-						m.activeTab = TabWatched
-					} else {
-						m.activeTab = TabExplorer
-					}
+					m.activeTab = (m.activeTab + tabCount - 1) % tabCount
 					m.syncViewportOutput()
 				}
 			case key.Matches(msg, m.keys.ClearWatched):
@@ -77,7 +98,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.engine.ClearWatched()
 					m.watchedCursor = 0
 					if m.activeTab == TabWatched {
-						m.viewport.SetContent(m.wrapOutput(m.viewport.Width, "No watched files.\nPress 'w' on a file to watch it."))
+						_, emptyHint := m.getTabList()
+						m.viewport.SetContent(m.wrapOutput(m.viewport.Width, emptyHint))
 					}
 				}
 			case key.Matches(msg, m.keys.RunFailures):
@@ -111,11 +133,55 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case key.Matches(msg, m.keys.ToggleSmartMode):
 				m.engine.ToggleSmartMode()
 				m.applySmartModeBindings()
+			case key.Matches(msg, m.keys.FilterFailures):
+				m.outputFilter.FailuresOnly = !m.outputFilter.FailuresOnly
+				m.syncViewportOutput()
+			case key.Matches(msg, m.keys.FilterConsole):
+				m.outputFilter.HideConsole = !m.outputFilter.HideConsole
+				m.syncViewportOutput()
+			case key.Matches(msg, m.keys.FilterStderr):
+				m.outputFilter.StderrOnly = !m.outputFilter.StderrOnly
+				m.syncViewportOutput()
+			case key.Matches(msg, m.keys.FilterANSI):
+				m.outputFilter.StripANSI = !m.outputFilter.StripANSI
+				m.syncViewportOutput()
+			case key.Matches(msg, m.keys.CopyOutput):
+				return m, m.copyOutput(false)
+			case key.Matches(msg, m.keys.CopyRawOutput):
+				return m, m.copyOutput(true)
+			case key.Matches(msg, m.keys.SaveOutput):
+				return m, m.saveOutput()
+			case key.Matches(msg, m.keys.OpenPager):
+				return m, m.openInPager()
+			case key.Matches(msg, m.keys.DiffView):
+				m, cmd = m.openDiffView()
+				return m, cmd
+			case key.Matches(msg, m.keys.SnapshotReview):
+				m, cmd = m.openSnapshotReview()
+				return m, cmd
+			case key.Matches(msg, m.keys.FuzzyFinder):
+				m, cmd = m.openFinder()
+				return m, cmd
+			case key.Matches(msg, m.keys.GrowExplorer):
+				return m, m.setSplit(m.layout.ratio + splitStep)
+			case key.Matches(msg, m.keys.ShrinkExplorer):
+				return m, m.setSplit(m.layout.ratio - splitStep)
+			case key.Matches(msg, m.keys.ResetSplit):
+				return m, m.setSplit(defaultSplitRatio)
+			case key.Matches(msg, m.keys.ToggleOrientation):
+				return m, m.cycleOrientation()
+			case key.Matches(msg, m.keys.ZoomOutput):
+				m.toggleZoom()
+				return m, nil
 			}
 		}
 
 		// Handle pane-specific keys
 		if m.activePane == PaneExplorer {
+			if m.activeTab == TabStats {
+				m, cmd = m.handleStatsKey(msg)
+				return m, cmd
+			}
 			if m.activeTab == TabWatched {
 				// In Smart Mode the watched tab shows the Affected Suite list.
 				tabList, _ := m.getTabList()
@@ -135,6 +201,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						path := tabList[m.watchedCursor]
 						return m, m.engine.TriggerTest(filesystem.NodeFromPath(path))
 					}
+				case key.Matches(msg, m.keys.WhyAffected):
+					if m.engine.IsSmartMode() && m.watchedCursor < len(tabList) {
+						m, cmd = m.openWhyAffected(tabList[m.watchedCursor])
+						return m, cmd
+					}
 				case key.Matches(msg, m.keys.ToggleWatch):
 					if !m.engine.IsSmartMode() && m.watchedCursor < len(tabList) {
 						path := tabList[m.watchedCursor]
@@ -158,50 +229,58 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			} else {
 				switch {
+				case m.hasSelection() && key.Matches(msg, m.keys.Enter):
+					return m, m.runMarked()
+				case m.hasSelection() && key.Matches(msg, m.keys.ToggleWatch):
+					return m, m.watchMarked(true)
+				case m.hasSelection() && key.Matches(msg, m.keys.UnwatchMarked):
+					return m, m.watchMarked(false)
+				case key.Matches(msg, m.keys.ClearMarks):
+					m.clearMarks()
+				case key.Matches(msg, m.keys.ToggleMark):
+					m.toggleMark()
+					m.syncViewportOutput()
+				case key.Matches(msg, m.keys.VisualMode):
+					m.toggleVisualMode()
+				case key.Matches(msg, m.keys.FoldPrefix):
+					m.pendingZ = true
+				case key.Matches(msg, m.keys.Collapse):
+					m.collapse()
+					m.syncViewportOutput()
+				case key.Matches(msg, m.keys.Expand):
+					m.expand()
+					m.syncViewportOutput()
 				case key.Matches(msg, m.keys.Search):
 					m.searchMode = true
 					m.searchFocus = true
 					m.searchInput.Focus()
 					return m, tea.Batch(append(cmds, textinput.Blink)...)
 				case key.Matches(msg, m.keys.Up):
-					// Smart Navigation Up
-					newCursor := m.cursor - 1
-					for newCursor >= 0 {
-						if !m.flatNodes[newCursor].IsDir {
-							m.cursor = newCursor
-							break
-						}
-						newCursor--
+					if m.cursor > 0 {
+						m.cursor--
 					}
 					m.syncViewportOutput()
 				case key.Matches(msg, m.keys.Down):
-					// Smart Navigation Down
-					newCursor := m.cursor + 1
-					for newCursor < len(m.flatNodes) {
-						if !m.flatNodes[newCursor].IsDir {
-							m.cursor = newCursor
-							break
-						}
-						newCursor++
+					if m.cursor < len(m.flatNodes)-1 {
+						m.cursor++
 					}
 					m.syncViewportOutput()
 				case key.Matches(msg, m.keys.Enter):
 					if m.cursor < len(m.flatNodes) {
-						node := m.flatNodes[m.cursor]
-						if !node.IsDir {
-							return m, m.engine.TriggerTest(node.Node)
-						}
+						return m, m.runNode(m.flatNodes[m.cursor])
 					}
 				case key.Matches(msg, m.keys.ToggleWatch):
 					if m.cursor < len(m.flatNodes) {
-						node := m.flatNodes[m.cursor]
-						if !node.IsDir {
-							m.engine.ToggleWatch(node.Path)
-						}
+						m.toggleWatchNode(m.flatNodes[m.cursor])
 					}
 				default:
-					// No matching key
+					if state, ok := m.statusFilterKey(msg); ok {
+						m.toggleStatusFilter(state)
+						m.syncViewportOutput()
+					}
 				}
+				// Watching or running may have changed what the filter shows.
+				m.refreshStatusFilter()
 			}
 		} else {
 			// Forward keys to viewport
@@ -214,28 +293,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		m.help.Width = msg.Width
 
-		// Calculate available space
-		// Width: (Total / 2) - Border(2) - Padding(2) = Total/2 - 4
-		paneWidth := (m.width / 2) - 4
-		// Height: Total - Footer(1) - Border(2) - Padding(0) = Total - 3
-		// Let's reserve 2 extra lines for safety/margins
-		paneHeight := m.height - 5
-
-		// Viewport Height: PaneHeight - Header("OUTPUT\n\n")
-		// Header takes 2 lines (Title + Empty line)
-		viewportHeight := paneHeight - 2
-
-		if !m.ready {
-			m.viewport = viewport.New(paneWidth, viewportHeight)
-			m.ready = true
-		} else {
-			m.viewport.Width = paneWidth
-			m.viewport.Height = viewportHeight
-		}
-		m.syncViewportOutput()
+		m.resizePanes()
+		m.refreshDiffView()
+		m.refreshSnapshotView()
 
 	case engine.TreeLoadedMsg:
-		m.flatNodes = flattenNodes(m.engine.GetTree())
+		m.rebuildFlatNodes()
 		m.syncViewportOutput()
 		return m, nil
 
@@ -255,6 +318,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.cursor < len(m.flatNodes) && m.flatNodes[m.cursor].Path != msg.FilePath {
 				shouldShow = false
 			}
+		case TabStats:
+			if path, ok := m.selectedStatsPath(); ok && path != msg.FilePath {
+				shouldShow = false
+			}
 		}
 
 		if shouldShow && !m.outputUpdateQueued {
@@ -267,6 +334,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case renderTickMsg:
 		m.outputUpdateQueued = false
+		m.refreshStatusFilter()
 		m.syncViewportOutput()
 		m.viewport.GotoBottom()
 		return m, tea.Batch(cmds...)
@@ -274,6 +342,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case runner.StatusUpdate:
 		// Zero-Touch Failure Auto-Focus (Smart Mode only):
 		// When a test fails in Smart Mode, automatically jump to it.
+		m.refreshStatusFilter()
+		if msg.Err != nil {
+			m.revealPath(msg.FilePath, false)
+		}
 		if msg.Err != nil && m.engine.IsSmartMode() {
 			suite := m.engine.GetAffectedSuite()
 			// The failed test will be first after re-sort (StatusFail priority)
@@ -281,7 +353,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.activeTab = TabWatched
 				m.watchedCursor = 0
 				path := suite[0]
-				if out, ok := m.engine.GetTestOutput(path); ok && out != "" {
+				if out, ok := m.testOutput(path); ok && out != "" {
 					m.viewport.SetContent(m.wrapOutput(m.viewport.Width, out))
 					m.viewport.GotoBottom()
 				}
@@ -293,6 +365,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.syncViewportOutput()
 		return m, tea.Batch(cmds...)
 
+	case osc52CopiedMsg:
+		cmds = append(cmds, copyNative(msg))
+		return m, tea.Batch(cmds...)
+
 	case engine.NotificationMsg:
 		m.notificationID++
 		m.activeNotification = msg.Message
@@ -5,11 +5,36 @@ import "github.com/jesspatton/lazytest/filesystem"
 // flattenNodes performs a depth-first traversal to create a flat list of nodes.
 // It merges single-child directories to reduce vertical space.
 func flattenNodes(tree *filesystem.Node) []DisplayNode {
+	return flattenVisibleNodes(tree, nil, nil)
+}
+
+// flattenVisibleNodes works like flattenNodes but skips the children of every
+// directory whose path is in folded. A compacted row ("a/b") folds under the
+// path of its last directory. When include is non-nil, only files it accepts
+// are listed, along with the directories that contain them.
+func flattenVisibleNodes(tree *filesystem.Node, folded map[string]struct{}, include func(path string) bool) []DisplayNode {
 	nodes := []DisplayNode{}
 	if tree == nil {
 		return nodes
 	}
 
+	var visible map[*filesystem.Node]bool
+	if include != nil {
+		visible = make(map[*filesystem.Node]bool)
+		var mark func(*filesystem.Node) bool
+		mark = func(n *filesystem.Node) bool {
+			keep := !n.IsDir && include(n.Path)
+			for _, child := range n.Children {
+				if mark(child) {
+					keep = true
+				}
+			}
+			visible[n] = keep
+			return keep
+		}
+		mark(tree)
+	}
+
 	// Helper to get the compacted name and the final node
 	var getCompacted func(*filesystem.Node, string) (*filesystem.Node, string)
 	getCompacted = func(n *filesystem.Node, currentName string) (*filesystem.Node, string) {
@@ -24,15 +49,25 @@ func flattenNodes(tree *filesystem.Node) []DisplayNode {
 	var traverse func(*filesystem.Node, int)
 	traverse = func(n *filesystem.Node, depth int) {
 		// Don't add root itself if it's just "."
+		if visible != nil && !visible[n] {
+			return
+		}
 		if n != tree {
 			finalNode, displayName := getCompacted(n, n.Name)
+			_, collapsed := folded[finalNode.Path]
+			collapsed = collapsed && finalNode.IsDir
 
 			nodes = append(nodes, DisplayNode{
 				Node:        finalNode,
 				DisplayName: displayName,
 				Depth:       depth,
+				Collapsed:   collapsed,
 			})
 
+			if collapsed {
+				return
+			}
+
 			// Now we need to continue traversal from the finalNode's children
 			for _, child := range finalNode.Children {
 				traverse(child, depth+1)
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// openWhyAffected shows why Smart Mode queued the test at path.
+func (m Model) openWhyAffected(path string) (Model, tea.Cmd) {
+	text, ok := m.engine.ExplainAffected(path)
+	if !ok {
+		return m, notify(fmt.Sprintf("%s was not queued by a file change.", filepath.Base(path)), true)
+	}
+	m.whyText = text
+	return m, nil
+}
+
+// renderWhyAffected renders the explanation centered over the screen.
+func (m Model) renderWhyAffected() string {
+	title := titleStyle.Render("WHY AFFECTED")
+	hint := statusStyle.Render("Press any key to close")
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		paneStyle.Render(fmt.Sprintf("%s\n\n%s\n%s", title, m.whyText, hint)),
+	)
+}
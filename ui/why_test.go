@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jesspatton/lazytest/analysis"
+	"github.com/jesspatton/lazytest/engine"
+)
+
+func TestWhyAffected_OpensAndCloses(t *testing.T) {
+	updated, _ := NewModel(engine.New("/p")).Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m := sendKey(updated.(Model), runes("s"))
+	m.activePane = PaneExplorer
+	m.activeTab = TabWatched
+
+	test := "/p/src/price.test.ts"
+	m.engine.State.Affected[test] = struct{}{}
+	m.engine.UpdateSortedAffected()
+	m.engine.State.AffectedBy[test] = engine.AffectedReason{
+		Changed: "/p/src/format.ts",
+		Chain:   []analysis.Hop{{File: test, Dependency: "/p/src/format.ts", Kind: analysis.KindImport}},
+	}
+
+	m = sendKey(m, runes("x"))
+	if !strings.Contains(m.whyText, "← src/price.test.ts (import)") {
+		t.Fatalf("Expected the import chain to be shown, got %q", m.whyText)
+	}
+	if view := m.View(); !strings.Contains(view, "WHY AFFECTED") {
+		t.Errorf("Expected the explanation view, got %q", view)
+	}
+
+	m = sendKey(m, runes("j"))
+	if m.whyText != "" {
+		t.Errorf("Expected any key to close the explanation")
+	}
+}